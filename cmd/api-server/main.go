@@ -2,14 +2,22 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"cloud.google.com/go/firestore"
 	"github.com/joho/godotenv"
 	"github.com/labstack/echo/v5"
 	"github.com/labstack/echo/v5/middleware"
+	"github.com/tsongpon/helios/internal/auth"
 	"github.com/tsongpon/helios/internal/httphandler"
+	"github.com/tsongpon/helios/internal/model"
 	"github.com/tsongpon/helios/internal/repository"
 	"github.com/tsongpon/helios/internal/service"
 )
@@ -19,32 +27,236 @@ func main() {
 
 	gcpProjectID := os.Getenv("GCP_PROJECT_ID")
 	databaseID := os.Getenv("GCP_FIRESTORE_DATABASE_ID")
-	ctx := context.Background()
+	// Cancelled on shutdown, stopping job workers and their in-flight LLM calls
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 	firestoreClient, err := firestore.NewClientWithDatabase(ctx, gcpProjectID, databaseID)
 	if err != nil {
 		log.Fatalf("failed to create firestore client: %v", err)
 	}
 	defer firestoreClient.Close()
 
-	llmAPIKey := os.Getenv("GEMINI_API_KEY")
-	llmRepository := repository.NewGeminiLLMRepository(llmAPIKey)
+	llmRepository, err := newLLMChain(os.Getenv("LLM_PROVIDER"), os.Getenv("LLM_FALLBACK_PROVIDERS"))
+	if err != nil {
+		log.Fatalf("failed to create LLM repository: %v", err)
+	}
+	statementRepository := repository.NewFirestoreStatementRepository(firestoreClient)
 	transactionRepository := repository.NewFirestoreTransactionRepository(firestoreClient)
+	jobRepository := repository.NewFirestoreJobRepository(firestoreClient)
+	apiKeyRepository := repository.NewFirestoreAPIKeyRepository(firestoreClient)
+	categoryRuleRepository := repository.NewFirestoreCategoryRuleRepository(firestoreClient)
+	ruleRepository := repository.NewFirestoreRuleRepository(firestoreClient)
+
+	textExtractor, err := service.NewTextExtractor(os.Getenv("PDF_TEXT_EXTRACTOR"))
+	if err != nil {
+		log.Fatalf("failed to create text extractor: %v", err)
+	}
+
+	// Without the LLM classifier only the user's category rules are applied
+	var classifier service.TransactionClassifier
+	if os.Getenv("LLM_CATEGORIZATION_ENABLED") != "false" {
+		classifier = llmRepository
+	}
+	ruleService := service.NewRuleService(ruleRepository, transactionRepository)
+	categorizationService := service.NewCategorizationService(categoryRuleRepository, transactionRepository, classifier).
+		WithRules(ruleService)
 
-	pdfService := service.NewPDFService(llmRepository, transactionRepository)
-	transactionService := service.NewTransactionService(transactionRepository)
+	merchantNormalizer, err := newMerchantNormalizer()
+	if err != nil {
+		log.Fatalf("failed to load merchant aliases: %v", err)
+	}
+
+	pdfService := service.NewPDFService(textExtractor, llmRepository, statementRepository, transactionRepository).
+		WithMerchantNormalizer(merchantNormalizer).
+		WithRules(ruleService).
+		WithCategorizer(categorizationService)
+	if os.Getenv("OCR_ENABLED") != "false" {
+		ocrExtractor := service.NewOCRTextExtractor(os.Getenv("OCR_LANGUAGES"))
+		pdfService.WithOCR(ocrExtractor, envInt("OCR_MIN_CHARS_PER_PAGE", service.DefaultMinTextDensity))
+	}
+	if os.Getenv("LAYOUT_PARSERS_ENABLED") != "false" {
+		pdfService.WithLayoutParsers(service.DefaultLayoutRegistry())
+	}
+	if os.Getenv("PII_REDACTION_ENABLED") != "false" {
+		redactor := service.NewPIIRedactor()
+		pdfService.WithRedaction(redactor)
+		categorizationService.WithRedaction(redactor)
+	}
+	statementService := service.NewStatementService(statementRepository, transactionRepository)
+	transactionService := service.NewTransactionService(transactionRepository).
+		WithMerchantNormalizer(merchantNormalizer).
+		WithStatements(statementRepository)
+	jobService := service.NewJobService(pdfService, jobRepository, envInt("JOB_WORKERS", 2), envInt("JOB_QUEUE_SIZE", 100))
+	jobService.Start(ctx)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepository)
+
+	authenticator, err := newAuthenticator(ctx)
+	if err != nil {
+		log.Fatalf("failed to configure authentication: %v", err)
+	}
 
 	pingHandler := httphandler.NewPingHandler()
-	statementHandler := httphandler.NewStatementHandler(pdfService)
+	statementHandler := httphandler.NewStatementHandler(pdfService, jobService, statementService)
 	transactionHandler := httphandler.NewTransactionHandler(transactionService)
+	jobHandler := httphandler.NewJobHandler(jobService)
+	apiKeyHandler := httphandler.NewAPIKeyHandler(apiKeyService)
+	categoryHandler := httphandler.NewCategoryHandler(categorizationService)
+	ruleHandler := httphandler.NewRuleHandler(ruleService)
 
 	e := echo.New()
 	e.Use(middleware.RequestLogger())
 
 	e.GET("/ping", pingHandler.Ping)
-	e.POST("/statements", statementHandler.CreateStatement)
-	e.GET("/transactions", transactionHandler.GetTransactions)
 
-	if err := e.Start(":1323"); err != nil {
+	api := e.Group("", httphandler.NewAuthMiddleware(authenticator, apiKeyService))
+	api.POST("/statements", statementHandler.CreateStatement, httphandler.RequireScope(model.ScopeStatementsWrite))
+	api.GET("/statements", statementHandler.GetStatements, httphandler.RequireScope(model.ScopeStatementsRead))
+	api.GET("/statements/:id", statementHandler.GetStatement, httphandler.RequireScope(model.ScopeStatementsRead))
+	api.DELETE("/statements/:id", statementHandler.DeleteStatement, httphandler.RequireScope(model.ScopeStatementsWrite))
+	api.POST("/statements/:id/reparse", statementHandler.ReparseStatement, httphandler.RequireScope(model.ScopeStatementsWrite))
+	api.GET("/transactions", transactionHandler.GetTransactions, httphandler.RequireScope(model.ScopeTransactionsRead))
+	api.POST("/transactions", transactionHandler.CreateTransaction, httphandler.RequireScope(model.ScopeTransactionsWrite))
+	api.PATCH("/transactions/:id", transactionHandler.UpdateTransaction, httphandler.RequireScope(model.ScopeTransactionsWrite))
+	api.DELETE("/transactions/:id", transactionHandler.DeleteTransaction, httphandler.RequireScope(model.ScopeTransactionsWrite))
+	api.POST("/transactions/categorize", categoryHandler.CategorizeTransactions, httphandler.RequireScope(model.ScopeTransactionsWrite))
+	api.GET("/categories", categoryHandler.GetCategories, httphandler.RequireScope(model.ScopeTransactionsRead))
+	api.GET("/category-rules", categoryHandler.GetCategoryRules, httphandler.RequireScope(model.ScopeTransactionsRead))
+	api.POST("/category-rules", categoryHandler.CreateCategoryRule, httphandler.RequireScope(model.ScopeTransactionsWrite))
+	api.DELETE("/category-rules/:id", categoryHandler.DeleteCategoryRule, httphandler.RequireScope(model.ScopeTransactionsWrite))
+	api.GET("/rules", ruleHandler.GetRules, httphandler.RequireScope(model.ScopeTransactionsRead))
+	api.POST("/rules", ruleHandler.CreateRule, httphandler.RequireScope(model.ScopeTransactionsWrite))
+	api.POST("/rules/dry-run", ruleHandler.DryRun, httphandler.RequireScope(model.ScopeTransactionsRead))
+	api.GET("/rules/:id", ruleHandler.GetRule, httphandler.RequireScope(model.ScopeTransactionsRead))
+	api.PUT("/rules/:id", ruleHandler.UpdateRule, httphandler.RequireScope(model.ScopeTransactionsWrite))
+	api.DELETE("/rules/:id", ruleHandler.DeleteRule, httphandler.RequireScope(model.ScopeTransactionsWrite))
+	// Uploads with async=true are polled here, so writers may read their jobs
+	api.GET("/jobs/:id", jobHandler.GetJob, httphandler.RequireScope(model.ScopeStatementsWrite))
+
+	apiKeys := api.Group("/api-keys", httphandler.RequireUserToken)
+	apiKeys.POST("", apiKeyHandler.CreateAPIKey)
+	apiKeys.GET("", apiKeyHandler.GetAPIKeys)
+	apiKeys.DELETE("/:id", apiKeyHandler.RevokeAPIKey)
+
+	if err := (echo.StartConfig{Address: ":1323"}).Start(ctx, e); err != nil {
 		e.Logger.Error("failed to start server", "error", err)
 	}
+	// Let workers record interrupted jobs before the process exits
+	jobService.Wait()
+}
+
+// newLLMChain wraps the primary provider and each comma-separated fallback
+// provider with a per-call timeout, retries and a circuit breaker, trying
+// them in order
+func newLLMChain(primary, fallbacks string) (repository.LLMProvider, error) {
+	names := []string{primary}
+	for _, name := range strings.Split(fallbacks, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	retryConfig := repository.DefaultRetryConfig
+	retryConfig.MaxRetries = envInt("LLM_MAX_RETRIES", retryConfig.MaxRetries)
+	threshold := envInt("LLM_CIRCUIT_BREAKER_THRESHOLD", 5)
+	cooldown := time.Duration(envInt("LLM_CIRCUIT_BREAKER_COOLDOWN_SECONDS", 30)) * time.Second
+	timeout := time.Duration(envInt("LLM_TIMEOUT_SECONDS", int(repository.DefaultLLMTimeout/time.Second))) * time.Second
+
+	prompts := repository.DefaultPromptLibrary()
+	if dir := os.Getenv("LLM_PROMPT_DIR"); dir != "" {
+		var err error
+		if prompts, err = repository.LoadPromptLibrary(os.DirFS(dir)); err != nil {
+			return nil, fmt.Errorf("failed to load prompt templates: %w", err)
+		}
+	}
+
+	var providers []repository.LLMProvider
+	for _, name := range names {
+		provider, err := newLLMRepository(name, prompts)
+		if err != nil {
+			return nil, err
+		}
+		bounded := repository.NewTimeoutLLMRepository(provider, timeout)
+		retrying := repository.NewRetryingLLMRepository(bounded, retryConfig)
+		providers = append(providers, repository.NewCircuitBreakerLLMRepository(retrying, threshold, cooldown))
+	}
+
+	if len(providers) == 1 {
+		return providers[0], nil
+	}
+	return repository.NewFallbackLLMRepository(providers...), nil
+}
+
+// newLLMRepository creates the LLM provider with the given name:
+// gemini (default), openai for any OpenAI-compatible API, or ollama
+func newLLMRepository(provider string, prompts *repository.PromptLibrary) (repository.LLMProvider, error) {
+	switch provider {
+	case "", "gemini":
+		return repository.NewGeminiLLMRepository(os.Getenv("GEMINI_API_KEY"), os.Getenv("GEMINI_MODEL")).WithPrompts(prompts), nil
+	case "openai":
+		return repository.NewOpenAILLMRepository(os.Getenv("OPENAI_BASE_URL"), os.Getenv("OPENAI_API_KEY"), os.Getenv("OPENAI_MODEL")).WithPrompts(prompts), nil
+	case "ollama":
+		return repository.NewOllamaLLMRepository(os.Getenv("OLLAMA_BASE_URL"), os.Getenv("OLLAMA_MODEL")).WithPrompts(prompts), nil
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q", provider)
+	}
+}
+
+// newAuthenticator verifies bearer tokens issued by AUTH_ISSUER. Signing keys
+// come from AUTH_JWKS_FILE when set, otherwise from AUTH_JWKS_URL or the
+// issuer's OIDC discovery document.
+func newAuthenticator(ctx context.Context) (httphandler.Authenticator, error) {
+	issuer := os.Getenv("AUTH_ISSUER")
+	if issuer == "" {
+		return nil, fmt.Errorf("AUTH_ISSUER is required")
+	}
+
+	var keys auth.KeySet
+	if path := os.Getenv("AUTH_JWKS_FILE"); path != "" {
+		fileKeys, err := auth.LoadJWKSFile(path)
+		if err != nil {
+			return nil, err
+		}
+		keys = fileKeys
+	} else {
+		jwksURL := os.Getenv("AUTH_JWKS_URL")
+		if jwksURL == "" {
+			discovered, err := auth.DiscoverJWKSURL(ctx, issuer)
+			if err != nil {
+				return nil, err
+			}
+			jwksURL = discovered
+		}
+		keys = auth.NewRemoteKeySet(jwksURL)
+	}
+
+	return auth.NewJWTVerifier(issuer, os.Getenv("AUTH_AUDIENCE"), keys), nil
+}
+
+// newMerchantNormalizer uses the built-in merchant alias table, extended with
+// MERCHANT_ALIASES_FILE when it is set
+func newMerchantNormalizer() (*service.MerchantNormalizer, error) {
+	normalizer := service.DefaultMerchantNormalizer()
+	path := os.Getenv("MERCHANT_ALIASES_FILE")
+	if path == "" {
+		return normalizer, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := service.LoadMerchantAliases(data)
+	if err != nil {
+		return nil, err
+	}
+	return normalizer.WithAliases(aliases), nil
+}
+
+// envInt reads an integer environment variable, returning def when unset or invalid
+func envInt(key string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
 }
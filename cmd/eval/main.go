@@ -0,0 +1,101 @@
+// Command eval measures statement parsing accuracy against a golden corpus of
+// anonymized statement texts with expected results.
+//
+//	go run ./cmd/eval -corpus internal/evaluation/testdata/corpus
+//	go run ./cmd/eval -corpus ./corpus -provider gemini -record
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/tsongpon/helios/internal/evaluation"
+	"github.com/tsongpon/helios/internal/repository"
+)
+
+func main() {
+	corpus := flag.String("corpus", "", "directory of statement texts with .expected.json files (required)")
+	provider := flag.String("provider", "replay", "LLM provider: replay (recorded responses), gemini, openai or ollama")
+	modelName := flag.String("model", "", "model name (default: the provider's default)")
+	baseURL := flag.String("base-url", "", "API base URL for the openai and ollama providers")
+	promptDir := flag.String("prompts", "", "directory of prompt templates to evaluate instead of the built-in ones")
+	record := flag.Bool("record", false, "save each provider response as <case>.response.json for later replay")
+	jsonOutput := flag.Bool("json", false, "print the report as JSON")
+	minF1 := flag.Float64("min-f1", 0, "exit with status 1 when transaction F1 is below this value")
+	timeout := flag.Duration("timeout", repository.DefaultLLMTimeout, "timeout for parsing a single statement")
+	flag.Parse()
+
+	if *corpus == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	godotenv.Load()
+
+	cases, err := evaluation.LoadCorpus(*corpus)
+	if err != nil {
+		log.Fatalf("failed to load corpus: %v", err)
+	}
+
+	prompts := repository.DefaultPromptLibrary()
+	if *promptDir != "" {
+		if prompts, err = repository.LoadPromptLibrary(os.DirFS(*promptDir)); err != nil {
+			log.Fatalf("failed to load prompt templates: %v", err)
+		}
+	}
+
+	var llmRepository repository.LLMProvider
+	switch *provider {
+	case "replay":
+		server := evaluation.NewReplayServer(cases)
+		defer server.Close()
+		llmRepository = repository.NewOpenAILLMRepository(server.URL, "", "replay").WithPrompts(prompts)
+	case "gemini":
+		llmRepository = repository.NewGeminiLLMRepository(os.Getenv("GEMINI_API_KEY"), *modelName).WithPrompts(prompts)
+	case "openai":
+		llmRepository = repository.NewOpenAILLMRepository(*baseURL, os.Getenv("OPENAI_API_KEY"), *modelName).WithPrompts(prompts)
+	case "ollama":
+		llmRepository = repository.NewOllamaLLMRepository(*baseURL, *modelName).WithPrompts(prompts)
+	default:
+		log.Fatalf("unknown LLM provider %q", *provider)
+	}
+	if *provider != "replay" {
+		llmRepository = repository.NewRetryingLLMRepository(llmRepository, repository.DefaultRetryConfig)
+	}
+
+	var results []evaluation.CaseResult
+	for _, c := range cases {
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		statement, err := llmRepository.ParseStatement(ctx, c.Text)
+		cancel()
+		if err != nil {
+			log.Printf("%s: %v", c.Name, err)
+		} else if *record && *provider != "replay" {
+			if err := evaluation.RecordResponse(*corpus, c.Name, statement); err != nil {
+				log.Printf("%s: failed to record response: %v", c.Name, err)
+			}
+		}
+		results = append(results, evaluation.Compare(c, statement, err))
+	}
+
+	report := evaluation.Summarize(results)
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else {
+		err = report.Write(os.Stdout)
+	}
+	if err != nil {
+		log.Fatalf("failed to write report: %v", err)
+	}
+
+	if report.F1 < *minF1 {
+		fmt.Fprintf(os.Stderr, "transaction F1 %.3f is below %.3f\n", report.F1, *minF1)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,293 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KeySet resolves the public key that signed a token by its key ID
+type KeySet interface {
+	Key(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+// parseJWKS decodes a JSON Web Key Set, skipping encryption keys and key
+// types it does not support
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set jwks
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", k.Kid, err)
+		}
+		if key != nil {
+			keys[k.Kid] = key
+		}
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// StaticKeySet is a fixed set of keys, for local development and tests
+type StaticKeySet struct {
+	keys map[string]crypto.PublicKey
+}
+
+// NewStaticKeySet creates a key set from public keys by key ID
+func NewStaticKeySet(keys map[string]crypto.PublicKey) *StaticKeySet {
+	return &StaticKeySet{
+		keys: keys,
+	}
+}
+
+// LoadJWKSFile reads a static key set from a JWKS JSON file
+func LoadJWKSFile(path string) (*StaticKeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, err
+	}
+	return NewStaticKeySet(keys), nil
+}
+
+func (s *StaticKeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// minRefreshInterval limits how often an unknown key ID can trigger a JWKS
+// fetch, so forged tokens cannot hammer the identity provider. Failed fetches
+// count too, so an identity provider that is down is not retried on every
+// request
+const minRefreshInterval = time.Minute
+
+// RemoteKeySet fetches and caches an identity provider's JWKS, refetching
+// when a token is signed with a key it has not seen, e.g. after key rotation
+type RemoteKeySet struct {
+	url    string
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+	fetchErr  error
+	// refreshing is closed when the fetch in flight completes; lookups of
+	// unknown keys wait on it instead of starting their own fetch
+	refreshing chan struct{}
+}
+
+// NewRemoteKeySet creates a key set backed by the JWKS at url
+func NewRemoteKeySet(url string) *RemoteKeySet {
+	return &RemoteKeySet{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *RemoteKeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	if key, ok := s.keys[kid]; ok {
+		s.mu.Unlock()
+		return key, nil
+	}
+	if s.refreshing == nil {
+		if time.Since(s.fetchedAt) < minRefreshInterval {
+			err := s.fetchErr
+			s.mu.Unlock()
+			if err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		// The fetch runs outside the lock, so cached keys keep resolving
+		// while the identity provider is slow, and without the caller's
+		// cancellation, since other lookups wait on the same fetch
+		s.refreshing = make(chan struct{})
+		s.fetchedAt = time.Now()
+		go s.refresh(context.WithoutCancel(ctx), s.refreshing)
+	}
+	refreshing := s.refreshing
+	s.mu.Unlock()
+
+	select {
+	case <-refreshing:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+	if s.fetchErr != nil {
+		return nil, s.fetchErr
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// refresh fetches the key set and closes done once the result is stored.
+// Keys from the last successful fetch are kept when the fetch fails
+func (s *RemoteKeySet) refresh(ctx context.Context, done chan struct{}) {
+	keys, err := s.fetch(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err == nil {
+		s.keys = keys
+	}
+	s.fetchErr = err
+	s.refreshing = nil
+	close(done)
+}
+
+func (s *RemoteKeySet) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	var keys map[string]crypto.PublicKey
+	err := getJSON(ctx, s.client, s.url, func(data []byte) error {
+		var err error
+		keys, err = parseJWKS(data)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	return keys, nil
+}
+
+// DiscoverJWKSURL reads the jwks_uri from the issuer's OpenID Connect
+// discovery document
+func DiscoverJWKSURL(ctx context.Context, issuer string) (string, error) {
+	var config struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	url := strings.TrimRight(issuer, "/") + "/.well-known/openid-configuration"
+	err := getJSON(ctx, &http.Client{Timeout: 10 * time.Second}, url, func(data []byte) error {
+		return json.Unmarshal(data, &config)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to discover OIDC configuration: %w", err)
+	}
+	if config.JWKSURI == "" {
+		return "", errors.New("OIDC configuration has no jwks_uri")
+	}
+	return config.JWKSURI, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, decode func([]byte) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	return decode(data)
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tsongpon/helios/internal/model"
+)
+
+// signingMethods are the asymmetric algorithms accepted from the identity
+// provider; HMAC and "none" are rejected
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// JWTVerifier validates bearer tokens issued by an OIDC identity provider
+type JWTVerifier struct {
+	issuer   string
+	audience string
+	keys     KeySet
+}
+
+// NewJWTVerifier creates a verifier accepting tokens from issuer for
+// audience, signed by a key in keys. An empty audience skips the audience check.
+func NewJWTVerifier(issuer, audience string, keys KeySet) *JWTVerifier {
+	return &JWTVerifier{
+		issuer:   issuer,
+		audience: audience,
+		keys:     keys,
+	}
+}
+
+// Authenticate verifies the token's signature, issuer, audience and expiry
+// and returns its subject as the principal
+func (v *JWTVerifier) Authenticate(ctx context.Context, token string) (model.Principal, error) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuer(v.issuer),
+	}
+	if v.audience != "" {
+		options = append(options, jwt.WithAudience(v.audience))
+	}
+
+	claims := jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	}, options...)
+	if err != nil {
+		return model.Principal{}, fmt.Errorf("%w: %v", model.ErrUnauthorized, err)
+	}
+	if claims.Subject == "" {
+		return model.Principal{}, fmt.Errorf("%w: token has no subject", model.ErrUnauthorized)
+	}
+
+	return model.Principal{UserID: claims.Subject}, nil
+}
@@ -0,0 +1,315 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tsongpon/helios/internal/model"
+)
+
+const (
+	testIssuer   = "https://issuer.example.com"
+	testAudience = "helios-api"
+)
+
+func signToken(t *testing.T, method jwt.SigningMethod, key any, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss": testIssuer,
+		"aud": testAudience,
+		"sub": "user-1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func rsaJWKS(kid string, key *rsa.PublicKey) []byte {
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	data, _ := json.Marshal(map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   encode(key.N.Bytes()),
+			"e":   encode(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	})
+	return data
+}
+
+func TestJWTVerifier_Authenticate(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate EC key: %v", err)
+	}
+	keys := NewStaticKeySet(map[string]crypto.PublicKey{
+		"rsa": &rsaKey.PublicKey,
+		"ec":  &ecKey.PublicKey,
+	})
+	verifier := NewJWTVerifier(testIssuer, testAudience, keys)
+
+	t.Run("accepts valid tokens", func(t *testing.T) {
+		tokens := map[string]string{
+			"RS256": signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa", validClaims()),
+			"ES256": signToken(t, jwt.SigningMethodES256, ecKey, "ec", validClaims()),
+		}
+		for name, token := range tokens {
+			principal, err := verifier.Authenticate(context.Background(), token)
+			if err != nil {
+				t.Fatalf("%s: expected no error, got %v", name, err)
+			}
+			if principal.UserID != "user-1" {
+				t.Errorf("%s: expected user-1, got %q", name, principal.UserID)
+			}
+		}
+	})
+
+	t.Run("rejects invalid tokens", func(t *testing.T) {
+		with := func(key string, value any) jwt.MapClaims {
+			claims := validClaims()
+			if value == nil {
+				delete(claims, key)
+			} else {
+				claims[key] = value
+			}
+			return claims
+		}
+
+		otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatalf("failed to generate RSA key: %v", err)
+		}
+
+		tokens := map[string]string{
+			"expired":        signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa", with("exp", time.Now().Add(-time.Minute).Unix())),
+			"no expiry":      signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa", with("exp", nil)),
+			"wrong issuer":   signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa", with("iss", "https://evil.example.com")),
+			"wrong audience": signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa", with("aud", "other-api")),
+			"no subject":     signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa", with("sub", nil)),
+			"unknown kid":    signToken(t, jwt.SigningMethodRS256, rsaKey, "missing", validClaims()),
+			"wrong key":      signToken(t, jwt.SigningMethodRS256, otherKey, "rsa", validClaims()),
+			"HMAC":           signToken(t, jwt.SigningMethodHS256, []byte("secret"), "rsa", validClaims()),
+			"malformed":      "not-a-token",
+		}
+		for name, token := range tokens {
+			_, err := verifier.Authenticate(context.Background(), token)
+			if !errors.Is(err, model.ErrUnauthorized) {
+				t.Errorf("%s: expected ErrUnauthorized, got %v", name, err)
+			}
+		}
+	})
+
+	t.Run("skips the audience check when none is configured", func(t *testing.T) {
+		verifier := NewJWTVerifier(testIssuer, "", keys)
+		claims := validClaims()
+		claims["aud"] = "other-api"
+
+		_, err := verifier.Authenticate(context.Background(), signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa", claims))
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+}
+
+func TestLoadJWKSFile(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, rsaJWKS("local", &rsaKey.PublicKey), 0o600); err != nil {
+		t.Fatalf("failed to write JWKS: %v", err)
+	}
+
+	keys, err := LoadJWKSFile(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	verifier := NewJWTVerifier(testIssuer, testAudience, keys)
+	principal, err := verifier.Authenticate(context.Background(), signToken(t, jwt.SigningMethodRS256, rsaKey, "local", validClaims()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if principal.UserID != "user-1" {
+		t.Errorf("expected user-1, got %q", principal.UserID)
+	}
+}
+
+func TestRemoteKeySet(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+
+	fetches := 0
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{
+				"issuer":   server.URL,
+				"jwks_uri": server.URL + "/keys",
+			})
+		case "/keys":
+			fetches++
+			w.Write(rsaJWKS("remote", &rsaKey.PublicKey))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	jwksURL, err := DiscoverJWKSURL(context.Background(), server.URL+"/")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if jwksURL != server.URL+"/keys" {
+		t.Fatalf("expected %s/keys, got %s", server.URL, jwksURL)
+	}
+
+	keys := NewRemoteKeySet(jwksURL)
+	for i := 0; i < 2; i++ {
+		if _, err := keys.Key(context.Background(), "remote"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("expected keys to be cached after 1 fetch, got %d fetches", fetches)
+	}
+
+	// An unknown key ID right after a fetch must not trigger another request
+	if _, err := keys.Key(context.Background(), "rotated"); err == nil {
+		t.Error("expected error for unknown key ID")
+	}
+	if fetches != 1 {
+		t.Errorf("expected refetch to be rate limited, got %d fetches", fetches)
+	}
+}
+
+func TestRemoteKeySet_SlowRefresh(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+
+	var fetches atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fetches.Add(1) == 1 {
+			w.Write(rsaJWKS("remote", &rsaKey.PublicKey))
+			return
+		}
+		close(started)
+		<-release
+		w.Write(rsaJWKS("rotated", &rsaKey.PublicKey))
+	}))
+	defer server.Close()
+
+	keys := NewRemoteKeySet(server.URL)
+	if _, err := keys.Key(context.Background(), "remote"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	keys.mu.Lock()
+	keys.fetchedAt = time.Time{}
+	keys.mu.Unlock()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := keys.Key(context.Background(), "rotated")
+			errs <- err
+		}()
+	}
+	<-started
+
+	// A cached key must resolve while the refetch is still in flight
+	resolved := make(chan error, 1)
+	go func() {
+		_, err := keys.Key(context.Background(), "remote")
+		resolved <- err
+	}()
+	select {
+	case err := <-resolved:
+		if err != nil {
+			t.Errorf("expected no error for cached key, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("cached key lookup blocked on the JWKS fetch")
+	}
+
+	// A lookup that gives up stops waiting without cancelling the fetch
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := keys.Key(ctx, "rotated"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	}
+	if got := fetches.Load(); got != 2 {
+		t.Errorf("expected concurrent lookups to share 1 refetch, got %d fetches", got)
+	}
+}
+
+func TestRemoteKeySet_FailedFetch(t *testing.T) {
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	keys := NewRemoteKeySet(server.URL)
+	for i := 0; i < 3; i++ {
+		_, err := keys.Key(context.Background(), "remote")
+		if err == nil || !strings.Contains(err.Error(), "failed to fetch JWKS") {
+			t.Errorf("expected fetch error, got %v", err)
+		}
+	}
+	if got := fetches.Load(); got != 1 {
+		t.Errorf("expected retries after a failed fetch to be rate limited, got %d fetches", got)
+	}
+}
@@ -0,0 +1,161 @@
+package evaluation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+// Case is one anonymized statement in the golden corpus. A case named
+// ktc-2025-01 consists of:
+//
+//	ktc-2025-01.txt            extracted statement text
+//	ktc-2025-01.expected.json  expected parse result
+//	ktc-2025-01.response.json  recorded LLM response for replay (optional)
+type Case struct {
+	Name     string
+	Text     string
+	Expected model.Statement
+	Response string
+}
+
+// statementDocument is the JSON layout of expected and recorded response
+// files. It matches the schema the LLM is asked to return, so a recorded
+// response can be hand-corrected into an expected file.
+type statementDocument struct {
+	CardNumber string `json:"card_number"`
+	Statement  struct {
+		Bank            string  `json:"bank"`
+		StatementDate   string  `json:"statement_date"`
+		PeriodStart     string  `json:"period_start"`
+		PeriodEnd       string  `json:"period_end"`
+		PaymentDueDate  string  `json:"payment_due_date"`
+		PreviousBalance float64 `json:"previous_balance"`
+		TotalPayment    float64 `json:"total_payment"`
+		MinimumPayment  float64 `json:"minimum_payment"`
+		CreditLine      float64 `json:"credit_line"`
+	} `json:"statement"`
+	Transactions []transactionDocument `json:"transactions"`
+}
+
+type transactionDocument struct {
+	TransactionDate string  `json:"transaction_date"`
+	PostingDate     string  `json:"posting_date"`
+	Description     string  `json:"description"`
+	Amount          float64 `json:"amount"`
+	IsInstallment   bool    `json:"is_installment"`
+	InstallmentTerm string  `json:"installment_term"`
+}
+
+func (d statementDocument) toStatement() model.Statement {
+	statement := model.Statement{
+		Bank:            d.Statement.Bank,
+		CardNumber:      d.CardNumber,
+		StatementDate:   d.Statement.StatementDate,
+		PeriodStart:     d.Statement.PeriodStart,
+		PeriodEnd:       d.Statement.PeriodEnd,
+		PaymentDueDate:  d.Statement.PaymentDueDate,
+		PreviousBalance: d.Statement.PreviousBalance,
+		TotalPayment:    d.Statement.TotalPayment,
+		MinimumPayment:  d.Statement.MinimumPayment,
+		CreditLine:      d.Statement.CreditLine,
+	}
+	for _, t := range d.Transactions {
+		statement.Transactions = append(statement.Transactions, model.Transaction{
+			CardNumber:      d.CardNumber,
+			TransactionDate: t.TransactionDate,
+			PostingDate:     t.PostingDate,
+			Description:     t.Description,
+			Amount:          t.Amount,
+			IsInstallment:   t.IsInstallment,
+			InstallmentTerm: t.InstallmentTerm,
+		})
+	}
+	return statement
+}
+
+func documentFromStatement(statement model.Statement) statementDocument {
+	var d statementDocument
+	d.CardNumber = statement.CardNumber
+	d.Statement.Bank = statement.Bank
+	d.Statement.StatementDate = statement.StatementDate
+	d.Statement.PeriodStart = statement.PeriodStart
+	d.Statement.PeriodEnd = statement.PeriodEnd
+	d.Statement.PaymentDueDate = statement.PaymentDueDate
+	d.Statement.PreviousBalance = statement.PreviousBalance
+	d.Statement.TotalPayment = statement.TotalPayment
+	d.Statement.MinimumPayment = statement.MinimumPayment
+	d.Statement.CreditLine = statement.CreditLine
+	d.Transactions = []transactionDocument{}
+	for _, t := range statement.Transactions {
+		d.Transactions = append(d.Transactions, transactionDocument{
+			TransactionDate: t.TransactionDate,
+			PostingDate:     t.PostingDate,
+			Description:     t.Description,
+			Amount:          t.Amount,
+			IsInstallment:   t.IsInstallment,
+			InstallmentTerm: t.InstallmentTerm,
+		})
+	}
+	return d
+}
+
+// LoadCorpus reads every case in dir; cases without an expected file are an error
+func LoadCorpus(dir string) ([]Case, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var cases []Case
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".txt")
+		text, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		expectedPath := filepath.Join(dir, name+".expected.json")
+		expectedJSON, err := os.ReadFile(expectedPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read expected result for %s: %w", name, err)
+		}
+		var expected statementDocument
+		if err := json.Unmarshal(expectedJSON, &expected); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", expectedPath, err)
+		}
+
+		response, err := os.ReadFile(filepath.Join(dir, name+".response.json"))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read recorded response for %s: %w", name, err)
+		}
+
+		cases = append(cases, Case{
+			Name:     name,
+			Text:     string(text),
+			Expected: expected.toStatement(),
+			Response: string(response),
+		})
+	}
+
+	if len(cases) == 0 {
+		return nil, fmt.Errorf("no statement texts found in %s", dir)
+	}
+	return cases, nil
+}
+
+// RecordResponse writes statement as the recorded response of the case named
+// name in dir, for later replay
+func RecordResponse(dir, name string, statement model.Statement) error {
+	data, err := json.MarshalIndent(documentFromStatement(statement), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, name+".response.json"), append(data, '\n'), 0o644)
+}
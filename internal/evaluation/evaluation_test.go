@@ -0,0 +1,158 @@
+package evaluation
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/tsongpon/helios/internal/model"
+	"github.com/tsongpon/helios/internal/repository"
+)
+
+func TestLoadCorpus(t *testing.T) {
+	cases, err := LoadCorpus("testdata/corpus")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(cases) != 2 {
+		t.Fatalf("expected 2 cases, got %d", len(cases))
+	}
+	if cases[0].Name != "ktc-sample" || cases[0].Expected.Bank != "KTC" {
+		t.Errorf("unexpected first case: %s %s", cases[0].Name, cases[0].Expected.Bank)
+	}
+	if len(cases[0].Expected.Transactions) != 2 || cases[0].Response == "" {
+		t.Errorf("expected transactions and a recorded response")
+	}
+}
+
+func TestLoadCorpus_Empty(t *testing.T) {
+	if _, err := LoadCorpus(t.TempDir()); err == nil {
+		t.Error("expected error for empty corpus")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	expected := model.Statement{
+		Bank: "KTC",
+		Transactions: []model.Transaction{
+			{TransactionDate: "2025-01-05", PostingDate: "2025-01-06", Description: "SHOP A", Amount: 100},
+			{TransactionDate: "2025-01-07", PostingDate: "2025-01-08", Description: "SHOP B", Amount: 200},
+			{TransactionDate: "2025-01-09", PostingDate: "2025-01-10", Description: "SHOP C", Amount: 300},
+		},
+	}
+	actual := model.Statement{
+		Bank: "ktc",
+		Transactions: []model.Transaction{
+			{TransactionDate: "2025-01-07", PostingDate: "2025-01-08", Description: "shop  b", Amount: 200.001},
+			{TransactionDate: "2025-01-05", PostingDate: "2025-01-05", Description: "SHOP A", Amount: 100},
+			{TransactionDate: "2025-02-01", PostingDate: "2025-02-01", Description: "EXTRA", Amount: 1},
+		},
+	}
+
+	result := Compare(Case{Name: "case", Expected: expected}, actual, nil)
+
+	if result.Expected != 3 || result.Actual != 3 {
+		t.Errorf("expected 3 expected and 3 actual transactions, got %d and %d", result.Expected, result.Actual)
+	}
+	if result.Exact != 1 {
+		t.Errorf("expected 1 exact match, got %d", result.Exact)
+	}
+	if result.FieldMatches["posting_date"] != 1 || result.FieldMatches["amount"] != 2 {
+		t.Errorf("unexpected field matches: %v", result.FieldMatches)
+	}
+	if !result.StatementMatches["bank"] {
+		t.Error("expected bank to match case-insensitively")
+	}
+	if result.Correct() {
+		t.Error("expected case not to be correct")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	expected := model.Statement{
+		Bank:         "KTC",
+		Transactions: []model.Transaction{{TransactionDate: "2025-01-05", PostingDate: "2025-01-05", Description: "SHOP", Amount: 100}},
+	}
+	results := []CaseResult{
+		Compare(Case{Name: "ok", Expected: expected}, expected, nil),
+		Compare(Case{Name: "failed", Expected: expected}, model.Statement{}, errors.New("LLM down")),
+	}
+
+	report := Summarize(results)
+
+	if report.Cases != 2 || report.Errors != 1 {
+		t.Errorf("expected 2 cases and 1 error, got %d and %d", report.Cases, report.Errors)
+	}
+	if report.Precision != 1 || report.Recall != 0.5 {
+		t.Errorf("expected precision 1 and recall 0.5, got %v and %v", report.Precision, report.Recall)
+	}
+	if math.Abs(report.F1-2.0/3.0) > 1e-9 {
+		t.Errorf("expected F1 2/3, got %v", report.F1)
+	}
+	if len(report.Banks) != 1 || report.Banks[0].Accuracy != 0.5 {
+		t.Errorf("expected KTC accuracy 0.5, got %+v", report.Banks)
+	}
+	if len(report.Failures) != 1 || report.Failures[0] != "failed" {
+		t.Errorf("expected failed case to be listed, got %v", report.Failures)
+	}
+}
+
+func TestSummarize_FieldMismatch(t *testing.T) {
+	expected := model.Statement{
+		Bank: "SCB",
+		Transactions: []model.Transaction{
+			{TransactionDate: "2025-02-03", PostingDate: "2025-02-04", Description: "GRAB *FOOD BANGKOK", Amount: 230.5},
+			{TransactionDate: "2025-02-10", PostingDate: "2025-02-11", Description: "STARBUCKS CENTRAL WORLD", Amount: 1250},
+		},
+	}
+	actual := expected
+	actual.Transactions = slices.Clone(expected.Transactions)
+	actual.Transactions[1].PostingDate = "2025-02-10"
+
+	report := Summarize([]CaseResult{Compare(Case{Name: "scb", Expected: expected}, actual, nil)})
+
+	if report.Errors != 0 {
+		t.Errorf("expected no errors, got %d", report.Errors)
+	}
+	if report.Precision != 0.5 || report.Recall != 0.5 {
+		t.Errorf("expected precision and recall 0.5, got %v and %v", report.Precision, report.Recall)
+	}
+	if len(report.Banks) != 1 || report.Banks[0].Bank != "SCB" || report.Banks[0].Accuracy != 0 {
+		t.Errorf("expected SCB accuracy 0, got %+v", report.Banks)
+	}
+	if len(report.Failures) != 1 || report.Failures[0] != "scb" {
+		t.Errorf("expected scb case to be listed, got %v", report.Failures)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	cases, err := LoadCorpus("testdata/corpus")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	server := NewReplayServer(cases)
+	defer server.Close()
+	llm := repository.NewOpenAILLMRepository(server.URL, "", "replay")
+
+	var results []CaseResult
+	for _, c := range cases {
+		statement, err := llm.ParseStatement(context.Background(), c.Text)
+		results = append(results, Compare(c, statement, err))
+	}
+	report := Summarize(results)
+
+	if report.Errors != 0 {
+		t.Fatalf("expected no errors, got %d", report.Errors)
+	}
+	if report.Precision != 1 || report.Recall != 1 {
+		t.Errorf("expected precision and recall 1, got %v and %v", report.Precision, report.Recall)
+	}
+	if len(report.Banks) != 2 || report.Banks[0].Bank != "KTC" || report.Banks[0].Accuracy != 1 || report.Banks[1].Accuracy != 1 {
+		t.Errorf("unexpected bank scores: %+v", report.Banks)
+	}
+	if len(report.Failures) != 0 {
+		t.Errorf("expected the sample corpus to parse correctly, got failures %v", report.Failures)
+	}
+}
@@ -0,0 +1,306 @@
+package evaluation
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+// TransactionFields are scored per parsed transaction
+var TransactionFields = []string{"transaction_date", "posting_date", "description", "amount", "is_installment", "installment_term"}
+
+// StatementFields are scored per statement
+var StatementFields = []string{"bank", "card_number", "statement_date", "period_start", "period_end", "payment_due_date", "previous_balance", "total_payment", "minimum_payment", "credit_line"}
+
+// minAlignmentScore is the number of equal fields needed to treat a parsed
+// transaction as an attempt at an expected one
+const minAlignmentScore = 3
+
+// CaseResult compares one parsed statement with its expected result
+type CaseResult struct {
+	Name string
+	Bank string
+	// Err is set when the statement could not be parsed at all
+	Err error
+	// Expected and Actual are transaction counts
+	Expected int
+	Actual   int
+	// Exact counts parsed transactions matching an expected one on every field
+	Exact int
+	// FieldMatches counts aligned transactions whose field is correct
+	FieldMatches map[string]int
+	// StatementMatches records which statement fields are correct
+	StatementMatches map[string]bool
+}
+
+// Correct reports whether every transaction and statement field was parsed correctly
+func (r CaseResult) Correct() bool {
+	if r.Err != nil || r.Exact != r.Expected || r.Actual != r.Expected {
+		return false
+	}
+	for _, ok := range r.StatementMatches {
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Compare scores actual against the case's expected statement. Parsed
+// transactions are aligned greedily with the expected transaction sharing
+// the most fields.
+func Compare(c Case, actual model.Statement, err error) CaseResult {
+	result := CaseResult{
+		Name:             c.Name,
+		Bank:             c.Expected.Bank,
+		Err:              err,
+		Expected:         len(c.Expected.Transactions),
+		FieldMatches:     map[string]int{},
+		StatementMatches: map[string]bool{},
+	}
+	for _, field := range StatementFields {
+		result.StatementMatches[field] = false
+	}
+	if err != nil {
+		return result
+	}
+
+	result.Actual = len(actual.Transactions)
+	for _, field := range StatementFields {
+		result.StatementMatches[field] = statementField(c.Expected, field) == statementField(actual, field)
+	}
+
+	used := make([]bool, len(actual.Transactions))
+	for _, expected := range c.Expected.Transactions {
+		best, bestScore := -1, 0
+		for i, t := range actual.Transactions {
+			if used[i] {
+				continue
+			}
+			if score := alignmentScore(expected, t); score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		if best < 0 || bestScore < minAlignmentScore {
+			continue
+		}
+
+		used[best] = true
+		for _, field := range TransactionFields {
+			if transactionField(expected, field) == transactionField(actual.Transactions[best], field) {
+				result.FieldMatches[field]++
+			}
+		}
+		if bestScore == len(TransactionFields) {
+			result.Exact++
+		}
+	}
+	return result
+}
+
+func alignmentScore(a, b model.Transaction) int {
+	score := 0
+	for _, field := range TransactionFields {
+		if transactionField(a, field) == transactionField(b, field) {
+			score++
+		}
+	}
+	return score
+}
+
+func transactionField(t model.Transaction, field string) string {
+	switch field {
+	case "transaction_date":
+		return strings.TrimSpace(t.TransactionDate)
+	case "posting_date":
+		return strings.TrimSpace(t.PostingDate)
+	case "description":
+		return normalizeText(t.Description)
+	case "amount":
+		return formatAmount(t.Amount)
+	case "is_installment":
+		return strconv.FormatBool(t.IsInstallment)
+	case "installment_term":
+		return strings.TrimSpace(t.InstallmentTerm)
+	}
+	return ""
+}
+
+func statementField(s model.Statement, field string) string {
+	switch field {
+	case "bank":
+		return normalizeText(s.Bank)
+	case "card_number":
+		return normalizeText(s.CardNumber)
+	case "statement_date":
+		return strings.TrimSpace(s.StatementDate)
+	case "period_start":
+		return strings.TrimSpace(s.PeriodStart)
+	case "period_end":
+		return strings.TrimSpace(s.PeriodEnd)
+	case "payment_due_date":
+		return strings.TrimSpace(s.PaymentDueDate)
+	case "previous_balance":
+		return formatAmount(s.PreviousBalance)
+	case "total_payment":
+		return formatAmount(s.TotalPayment)
+	case "minimum_payment":
+		return formatAmount(s.MinimumPayment)
+	case "credit_line":
+		return formatAmount(s.CreditLine)
+	}
+	return ""
+}
+
+func normalizeText(s string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(s), " "))
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(math.Round(amount*100)/100, 'f', 2, 64)
+}
+
+// FieldScore is the precision and recall of one transaction field over the corpus
+type FieldScore struct {
+	Field     string  `json:"field"`
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+}
+
+// BankScore summarizes the results for statements of one bank
+type BankScore struct {
+	Bank      string  `json:"bank"`
+	Cases     int     `json:"cases"`
+	Errors    int     `json:"errors"`
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	// Accuracy is the share of statements parsed entirely correctly
+	Accuracy float64 `json:"accuracy"`
+}
+
+// Report aggregates case results over the whole corpus
+type Report struct {
+	Cases  int `json:"cases"`
+	Errors int `json:"errors"`
+	// Precision, Recall and F1 count exactly matching transactions
+	Precision         float64            `json:"precision"`
+	Recall            float64            `json:"recall"`
+	F1                float64            `json:"f1"`
+	TransactionFields []FieldScore       `json:"transaction_fields"`
+	StatementFields   map[string]float64 `json:"statement_fields"`
+	Banks             []BankScore        `json:"banks"`
+	Failures          []string           `json:"failures,omitempty"`
+}
+
+// Summarize aggregates results into a report
+func Summarize(results []CaseResult) Report {
+	report := Report{
+		Cases:           len(results),
+		StatementFields: map[string]float64{},
+	}
+
+	var expected, actual, exact int
+	fieldMatches := map[string]int{}
+	statementMatches := map[string]int{}
+	banks := map[string][]CaseResult{}
+	for _, r := range results {
+		if r.Err != nil {
+			report.Errors++
+		}
+		if !r.Correct() {
+			report.Failures = append(report.Failures, r.Name)
+		}
+		expected += r.Expected
+		actual += r.Actual
+		exact += r.Exact
+		for field, n := range r.FieldMatches {
+			fieldMatches[field] += n
+		}
+		for field, ok := range r.StatementMatches {
+			if ok {
+				statementMatches[field]++
+			}
+		}
+		banks[r.Bank] = append(banks[r.Bank], r)
+	}
+
+	report.Precision = ratio(exact, actual)
+	report.Recall = ratio(exact, expected)
+	if report.Precision+report.Recall > 0 {
+		report.F1 = 2 * report.Precision * report.Recall / (report.Precision + report.Recall)
+	}
+	for _, field := range TransactionFields {
+		report.TransactionFields = append(report.TransactionFields, FieldScore{
+			Field:     field,
+			Precision: ratio(fieldMatches[field], actual),
+			Recall:    ratio(fieldMatches[field], expected),
+		})
+	}
+	for _, field := range StatementFields {
+		report.StatementFields[field] = ratio(statementMatches[field], len(results))
+	}
+
+	for bank, bankResults := range banks {
+		score := BankScore{Bank: bank, Cases: len(bankResults)}
+		var bankExpected, bankActual, bankExact, correct int
+		for _, r := range bankResults {
+			if r.Err != nil {
+				score.Errors++
+			}
+			if r.Correct() {
+				correct++
+			}
+			bankExpected += r.Expected
+			bankActual += r.Actual
+			bankExact += r.Exact
+		}
+		score.Precision = ratio(bankExact, bankActual)
+		score.Recall = ratio(bankExact, bankExpected)
+		score.Accuracy = ratio(correct, len(bankResults))
+		report.Banks = append(report.Banks, score)
+	}
+	sort.Slice(report.Banks, func(i, j int) bool { return report.Banks[i].Bank < report.Banks[j].Bank })
+
+	return report
+}
+
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
+
+// Write prints the report as plain-text tables
+func (r Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Statements: %d (%d failed to parse)\n", r.Cases, r.Errors)
+	fmt.Fprintf(tw, "Transactions: precision %.3f, recall %.3f, F1 %.3f\n\n", r.Precision, r.Recall, r.F1)
+
+	fmt.Fprintln(tw, "TRANSACTION FIELD\tPRECISION\tRECALL")
+	for _, f := range r.TransactionFields {
+		fmt.Fprintf(tw, "%s\t%.3f\t%.3f\n", f.Field, f.Precision, f.Recall)
+	}
+
+	fmt.Fprintln(tw, "\nSTATEMENT FIELD\tACCURACY")
+	for _, field := range StatementFields {
+		fmt.Fprintf(tw, "%s\t%.3f\n", field, r.StatementFields[field])
+	}
+
+	fmt.Fprintln(tw, "\nBANK\tSTATEMENTS\tERRORS\tPRECISION\tRECALL\tACCURACY")
+	for _, b := range r.Banks {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.3f\t%.3f\t%.3f\n", b.Bank, b.Cases, b.Errors, b.Precision, b.Recall, b.Accuracy)
+	}
+
+	if len(r.Failures) > 0 {
+		fmt.Fprintf(tw, "\nNot fully correct: %s\n", strings.Join(r.Failures, ", "))
+	}
+	return tw.Flush()
+}
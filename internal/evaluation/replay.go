@@ -0,0 +1,55 @@
+package evaluation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+// NewReplayServer starts a fake OpenAI-compatible chat completions server
+// that answers each prompt with the recorded response of the case whose
+// statement text it contains. Use it with repository.NewOpenAILLMRepository
+// to evaluate the prompt and response parsing without calling a real LLM.
+func NewReplayServer(cases []Case) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var prompt strings.Builder
+		for _, m := range req.Messages {
+			prompt.WriteString(m.Content)
+		}
+
+		// Prefer the longest text so a case is not shadowed by a shorter
+		// one that happens to be a substring of it
+		var match *Case
+		for i := range cases {
+			c := &cases[i]
+			if c.Response == "" || !strings.Contains(prompt.String(), strings.TrimSpace(c.Text)) {
+				continue
+			}
+			if match == nil || len(c.Text) > len(match.Text) {
+				match = c
+			}
+		}
+		if match == nil {
+			http.Error(w, "no recorded response for prompt", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]string{"role": "assistant", "content": match.Response}},
+			},
+		})
+	}))
+}
@@ -0,0 +1,76 @@
+package httphandler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/tsongpon/helios/internal/model"
+)
+
+type APIKeyHandler struct {
+	apiKeyService APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+func (h *APIKeyHandler) CreateAPIKey(c *echo.Context) error {
+	var req CreateAPIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "invalid request body",
+		})
+	}
+
+	scopes := make([]model.Scope, len(req.Scopes))
+	for i, scope := range req.Scopes {
+		scopes[i] = model.Scope(scope)
+	}
+
+	key, secret, err := h.apiKeyService.CreateAPIKey(c.Request().Context(), currentUserID(c), req.Name, scopes)
+	if err != nil {
+		if errors.Is(err, model.ErrInvalidScope) {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "failed to create API key: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, CreateAPIKeyResponse{
+		APIKeyResponse: toAPIKeyResponse(key),
+		Key:            secret,
+	})
+}
+
+func (h *APIKeyHandler) GetAPIKeys(c *echo.Context) error {
+	keys, err := h.apiKeyService.GetAPIKeys(c.Request().Context(), currentUserID(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "failed to get API keys: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, toAPIKeyResponses(keys))
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(c *echo.Context) error {
+	if err := h.apiKeyService.RevokeAPIKey(c.Request().Context(), currentUserID(c), c.Param("id")); err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error: "API key not found",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "failed to revoke API key: " + err.Error(),
+		})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,137 @@
+package httphandler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+	"github.com/tsongpon/helios/internal/model"
+)
+
+type mockAPIKeyService struct {
+	key       model.APIKey
+	secret    string
+	keys      []model.APIKey
+	err       error
+	scopes    []model.Scope
+	revokedID string
+}
+
+func (m *mockAPIKeyService) CreateAPIKey(ctx context.Context, userID, name string, scopes []model.Scope) (model.APIKey, string, error) {
+	m.scopes = scopes
+	if m.err != nil {
+		return model.APIKey{}, "", m.err
+	}
+	return m.key, m.secret, nil
+}
+
+func (m *mockAPIKeyService) GetAPIKeys(ctx context.Context, userID string) ([]model.APIKey, error) {
+	return m.keys, m.err
+}
+
+func (m *mockAPIKeyService) RevokeAPIKey(ctx context.Context, userID, keyID string) error {
+	m.revokedID = keyID
+	return m.err
+}
+
+func newAPIKeyContext(method, target, body string) (*echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set(principalContextKey, model.Principal{UserID: "user123"})
+	return c, rec
+}
+
+func TestAPIKeyHandler_CreateAPIKey(t *testing.T) {
+	t.Run("returns the key once", func(t *testing.T) {
+		mockService := &mockAPIKeyService{
+			key:    model.APIKey{ID: "key-1", Name: "script", Prefix: "hk_abcdef", Scopes: []model.Scope{model.ScopeStatementsWrite}},
+			secret: "hk_abcdef123",
+		}
+		handler := NewAPIKeyHandler(mockService)
+		c, rec := newAPIKeyContext(http.MethodPost, "/api-keys", `{"name":"script","scopes":["statements:write"]}`)
+
+		if err := handler.CreateAPIKey(c); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, rec.Code)
+		}
+
+		var response CreateAPIKeyResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if response.Key != "hk_abcdef123" || response.ID != "key-1" {
+			t.Errorf("unexpected response %+v", response)
+		}
+		if len(mockService.scopes) != 1 || mockService.scopes[0] != model.ScopeStatementsWrite {
+			t.Errorf("expected statements:write scope, got %v", mockService.scopes)
+		}
+	})
+
+	t.Run("returns bad request for invalid scopes", func(t *testing.T) {
+		handler := NewAPIKeyHandler(&mockAPIKeyService{err: fmt.Errorf("%w: %q", model.ErrInvalidScope, "admin")})
+		c, rec := newAPIKeyContext(http.MethodPost, "/api-keys", `{"name":"script","scopes":["admin"]}`)
+
+		if err := handler.CreateAPIKey(c); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+}
+
+func TestAPIKeyHandler_GetAPIKeys(t *testing.T) {
+	handler := NewAPIKeyHandler(&mockAPIKeyService{keys: []model.APIKey{{ID: "key-1", Hash: "secret-hash"}}})
+	c, rec := newAPIKeyContext(http.MethodGet, "/api-keys", "")
+
+	if err := handler.GetAPIKeys(c); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "secret-hash") {
+		t.Error("expected the key hash not to be returned")
+	}
+}
+
+func TestAPIKeyHandler_RevokeAPIKey(t *testing.T) {
+	t.Run("revokes the key", func(t *testing.T) {
+		mockService := &mockAPIKeyService{}
+		handler := NewAPIKeyHandler(mockService)
+		c, rec := newAPIKeyContext(http.MethodDelete, "/api-keys/key-1", "")
+		c.SetPathValues(echo.PathValues{{Name: "id", Value: "key-1"}})
+
+		if err := handler.RevokeAPIKey(c); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if rec.Code != http.StatusNoContent {
+			t.Errorf("expected status %d, got %d", http.StatusNoContent, rec.Code)
+		}
+		if mockService.revokedID != "key-1" {
+			t.Errorf("expected key-1 to be revoked, got %q", mockService.revokedID)
+		}
+	})
+
+	t.Run("returns not found for unknown keys", func(t *testing.T) {
+		handler := NewAPIKeyHandler(&mockAPIKeyService{err: model.ErrNotFound})
+		c, rec := newAPIKeyContext(http.MethodDelete, "/api-keys/key-1", "")
+
+		if err := handler.RevokeAPIKey(c); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package httphandler
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"github.com/tsongpon/helios/internal/model"
+)
+
+// principalContextKey stores the authenticated model.Principal in the Echo context
+const principalContextKey = "principal"
+
+// NewAuthMiddleware rejects requests without a valid bearer token and
+// attaches the authenticated principal to the request. Tokens starting with
+// model.APIKeyPrefix, or sent in the X-API-Key header, are checked by
+// apiKeys; all others are user tokens checked by users.
+func NewAuthMiddleware(users, apiKeys Authenticator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			token, ok := bearerToken(c.Request().Header.Get("Authorization"))
+			if !ok {
+				token = strings.TrimSpace(c.Request().Header.Get("X-API-Key"))
+				if token == "" {
+					return unauthorized(c, "missing bearer token")
+				}
+			}
+
+			authenticator := users
+			if strings.HasPrefix(token, model.APIKeyPrefix) {
+				authenticator = apiKeys
+			}
+
+			principal, err := authenticator.Authenticate(c.Request().Context(), token)
+			if err != nil {
+				log.Printf("authentication failed: %v", err)
+				return unauthorized(c, "invalid bearer token")
+			}
+
+			c.Set(principalContextKey, principal)
+			return next(c)
+		}
+	}
+}
+
+// RequireScope rejects API keys that were not granted scope
+func RequireScope(scope model.Scope) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			if !currentPrincipal(c).HasScope(scope) {
+				return c.JSON(http.StatusForbidden, ErrorResponse{
+					Error: "API key is missing scope " + string(scope),
+				})
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireUserToken rejects API keys, e.g. so a leaked key cannot issue more keys
+func RequireUserToken(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c *echo.Context) error {
+		if currentPrincipal(c).APIKeyID != "" {
+			return c.JSON(http.StatusForbidden, ErrorResponse{
+				Error: "this endpoint requires a user token",
+			})
+		}
+		return next(c)
+	}
+}
+
+func bearerToken(header string) (string, bool) {
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+func unauthorized(c *echo.Context, message string) error {
+	c.Response().Header().Set("WWW-Authenticate", "Bearer")
+	return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: message})
+}
+
+func currentPrincipal(c *echo.Context) model.Principal {
+	principal, _ := c.Get(principalContextKey).(model.Principal)
+	return principal
+}
+
+// currentUserID returns the ID of the user authenticated by NewAuthMiddleware
+func currentUserID(c *echo.Context) string {
+	return currentPrincipal(c).UserID
+}
@@ -0,0 +1,169 @@
+package httphandler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+	"github.com/tsongpon/helios/internal/model"
+)
+
+type mockAuthenticator struct {
+	principal model.Principal
+	err       error
+	token     string
+}
+
+func (m *mockAuthenticator) Authenticate(ctx context.Context, token string) (model.Principal, error) {
+	m.token = token
+	if m.err != nil {
+		return model.Principal{}, m.err
+	}
+	return m.principal, nil
+}
+
+// serveAuth runs middleware in front of a handler that records the
+// authenticated user, returning the response, the user and whether the
+// handler was reached
+func serveAuth(t *testing.T, middleware echo.MiddlewareFunc, principal *model.Principal, headers map[string]string) (*httptest.ResponseRecorder, string, bool) {
+	t.Helper()
+	var userID string
+	called := false
+	next := func(c *echo.Context) error {
+		called = true
+		userID = currentUserID(c)
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/transactions", nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if principal != nil {
+		c.Set(principalContextKey, *principal)
+	}
+
+	if err := middleware(next)(c); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return rec, userID, called
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	t.Run("attaches the authenticated user", func(t *testing.T) {
+		users := &mockAuthenticator{principal: model.Principal{UserID: "user-1"}}
+		apiKeys := &mockAuthenticator{err: model.ErrUnauthorized}
+
+		rec, userID, called := serveAuth(t, NewAuthMiddleware(users, apiKeys), nil, map[string]string{"Authorization": "Bearer abc.def.ghi"})
+
+		if !called {
+			t.Fatal("expected next handler to be called")
+		}
+		if rec.Code != http.StatusNoContent {
+			t.Errorf("expected status %d, got %d", http.StatusNoContent, rec.Code)
+		}
+		if users.token != "abc.def.ghi" {
+			t.Errorf("expected token abc.def.ghi, got %q", users.token)
+		}
+		if userID != "user-1" {
+			t.Errorf("expected user-1, got %q", userID)
+		}
+	})
+
+	t.Run("authenticates API keys", func(t *testing.T) {
+		for _, headers := range []map[string]string{
+			{"Authorization": "Bearer hk_secret"},
+			{"X-API-Key": "hk_secret"},
+		} {
+			users := &mockAuthenticator{err: model.ErrUnauthorized}
+			apiKeys := &mockAuthenticator{principal: model.Principal{UserID: "user-2", APIKeyID: "key-1"}}
+
+			rec, userID, called := serveAuth(t, NewAuthMiddleware(users, apiKeys), nil, headers)
+
+			if !called || rec.Code != http.StatusNoContent {
+				t.Fatalf("%v: expected next handler to be called, got status %d", headers, rec.Code)
+			}
+			if apiKeys.token != "hk_secret" {
+				t.Errorf("%v: expected API key hk_secret, got %q", headers, apiKeys.token)
+			}
+			if users.token != "" {
+				t.Errorf("%v: expected user authenticator not to be called", headers)
+			}
+			if userID != "user-2" {
+				t.Errorf("%v: expected user-2, got %q", headers, userID)
+			}
+		}
+	})
+
+	t.Run("rejects a missing token", func(t *testing.T) {
+		for _, header := range []string{"", "Basic dXNlcjpwYXNz", "Bearer "} {
+			headers := map[string]string{}
+			if header != "" {
+				headers["Authorization"] = header
+			}
+			rec, _, called := serveAuth(t, NewAuthMiddleware(&mockAuthenticator{}, &mockAuthenticator{}), nil, headers)
+
+			if called {
+				t.Errorf("%q: expected next handler not to be called", header)
+			}
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("%q: expected status %d, got %d", header, http.StatusUnauthorized, rec.Code)
+			}
+			if rec.Header().Get("WWW-Authenticate") != "Bearer" {
+				t.Errorf("%q: expected WWW-Authenticate challenge", header)
+			}
+		}
+	})
+
+	t.Run("rejects an invalid token", func(t *testing.T) {
+		invalid := &mockAuthenticator{err: model.ErrUnauthorized}
+
+		rec, _, called := serveAuth(t, NewAuthMiddleware(invalid, invalid), nil, map[string]string{"Authorization": "Bearer expired"})
+
+		if called {
+			t.Error("expected next handler not to be called")
+		}
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("expected status %d, got %d", http.StatusUnauthorized, rec.Code)
+		}
+	})
+}
+
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal model.Principal
+		want      int
+	}{
+		{"user token", model.Principal{UserID: "user-1"}, http.StatusNoContent},
+		{"API key with scope", model.Principal{UserID: "user-1", APIKeyID: "key-1", Scopes: []model.Scope{model.ScopeStatementsWrite}}, http.StatusNoContent},
+		{"API key without scope", model.Principal{UserID: "user-1", APIKeyID: "key-1", Scopes: []model.Scope{model.ScopeTransactionsRead}}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, _, _ := serveAuth(t, RequireScope(model.ScopeStatementsWrite), &tt.principal, nil)
+
+			if rec.Code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+}
+
+func TestRequireUserToken(t *testing.T) {
+	rec, _, called := serveAuth(t, RequireUserToken, &model.Principal{UserID: "user-1", APIKeyID: "key-1", Scopes: model.Scopes}, nil)
+	if called || rec.Code != http.StatusForbidden {
+		t.Errorf("expected API key to be rejected with %d, got %d", http.StatusForbidden, rec.Code)
+	}
+
+	rec, _, called = serveAuth(t, RequireUserToken, &model.Principal{UserID: "user-1"}, nil)
+	if !called || rec.Code != http.StatusNoContent {
+		t.Errorf("expected user token to be accepted, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,115 @@
+package httphandler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/tsongpon/helios/internal/model"
+)
+
+type CategoryHandler struct {
+	categorizationService CategorizationService
+}
+
+func NewCategoryHandler(categorizationService CategorizationService) *CategoryHandler {
+	return &CategoryHandler{
+		categorizationService: categorizationService,
+	}
+}
+
+func (h *CategoryHandler) GetCategories(c *echo.Context) error {
+	return c.JSON(http.StatusOK, h.categorizationService.GetCategories())
+}
+
+func (h *CategoryHandler) CreateCategoryRule(c *echo.Context) error {
+	var req CreateCategoryRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "invalid request body",
+		})
+	}
+
+	rule, err := h.categorizationService.CreateCategoryRule(c.Request().Context(), currentUserID(c), model.CategoryRule{
+		Pattern:  req.Pattern,
+		IsRegex:  req.IsRegex,
+		Category: req.Category,
+	})
+	if err != nil {
+		if errors.Is(err, model.ErrInvalidCategoryRule) {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "failed to create category rule: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, toCategoryRuleResponse(rule))
+}
+
+func (h *CategoryHandler) GetCategoryRules(c *echo.Context) error {
+	rules, err := h.categorizationService.GetCategoryRules(c.Request().Context(), currentUserID(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "failed to get category rules: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, toCategoryRuleResponses(rules))
+}
+
+func (h *CategoryHandler) DeleteCategoryRule(c *echo.Context) error {
+	if err := h.categorizationService.DeleteCategoryRule(c.Request().Context(), currentUserID(c), c.Param("id")); err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error: "category rule not found",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "failed to delete category rule: " + err.Error(),
+		})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// CategorizeTransactions runs the rules and the classifier over the user's
+// saved transactions in a date range
+func (h *CategoryHandler) CategorizeTransactions(c *echo.Context) error {
+	var req CategorizeTransactionsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "invalid request body",
+		})
+	}
+
+	from, err := time.Parse("2006-01-02", req.Start)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "start is required (format: YYYY-MM-DD)",
+		})
+	}
+	to, err := time.Parse("2006-01-02", req.End)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "end is required (format: YYYY-MM-DD)",
+		})
+	}
+
+	query := model.TransactionQuery{
+		From:   from,
+		To:     to,
+		Filter: model.TransactionFilter{StatementID: req.StatementID},
+	}
+	updated, err := h.categorizationService.Recategorize(c.Request().Context(), currentUserID(c), query, req.Overwrite)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "failed to categorize transactions: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, CategorizeTransactionsResponse{Updated: updated})
+}
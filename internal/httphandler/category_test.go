@@ -0,0 +1,131 @@
+package httphandler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+type mockCategorizationService struct {
+	rule      model.CategoryRule
+	rules     []model.CategoryRule
+	err       error
+	deletedID string
+	query     model.TransactionQuery
+	overwrite bool
+	updated   int
+}
+
+func (m *mockCategorizationService) GetCategories() []string {
+	return model.Categories
+}
+
+func (m *mockCategorizationService) CreateCategoryRule(ctx context.Context, userID string, rule model.CategoryRule) (model.CategoryRule, error) {
+	m.rule = rule
+	rule.ID = "rule-1"
+	return rule, m.err
+}
+
+func (m *mockCategorizationService) GetCategoryRules(ctx context.Context, userID string) ([]model.CategoryRule, error) {
+	return m.rules, m.err
+}
+
+func (m *mockCategorizationService) DeleteCategoryRule(ctx context.Context, userID, ruleID string) error {
+	m.deletedID = ruleID
+	return m.err
+}
+
+func (m *mockCategorizationService) Recategorize(ctx context.Context, userID string, query model.TransactionQuery, overwrite bool) (int, error) {
+	m.query = query
+	m.overwrite = overwrite
+	return m.updated, m.err
+}
+
+func TestCategoryHandler_CreateCategoryRule(t *testing.T) {
+	t.Run("creates the rule", func(t *testing.T) {
+		mockService := &mockCategorizationService{}
+		handler := NewCategoryHandler(mockService)
+		c, rec := newAPIKeyContext(http.MethodPost, "/category-rules", `{"pattern":"^GRAB","is_regex":true,"category":"transport"}`)
+
+		if err := handler.CreateCategoryRule(c); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, rec.Code)
+		}
+
+		var response CategoryRuleResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if response.ID != "rule-1" || response.Pattern != "^GRAB" || !response.IsRegex || response.Category != "transport" {
+			t.Errorf("unexpected response %+v", response)
+		}
+	})
+
+	t.Run("returns bad request for invalid rules", func(t *testing.T) {
+		handler := NewCategoryHandler(&mockCategorizationService{err: fmt.Errorf("%w: unknown category %q", model.ErrInvalidCategoryRule, "rides")})
+		c, rec := newAPIKeyContext(http.MethodPost, "/category-rules", `{"pattern":"grab","category":"rides"}`)
+
+		if err := handler.CreateCategoryRule(c); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+}
+
+func TestCategoryHandler_DeleteCategoryRule(t *testing.T) {
+	handler := NewCategoryHandler(&mockCategorizationService{err: model.ErrNotFound})
+	c, rec := newAPIKeyContext(http.MethodDelete, "/category-rules/rule-1", "")
+
+	if err := handler.DeleteCategoryRule(c); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestCategoryHandler_CategorizeTransactions(t *testing.T) {
+	t.Run("recategorizes the range", func(t *testing.T) {
+		mockService := &mockCategorizationService{updated: 7}
+		handler := NewCategoryHandler(mockService)
+		c, rec := newAPIKeyContext(http.MethodPost, "/transactions/categorize", `{"start":"2024-12-01","end":"2024-12-31","statement_id":"stmt-1","overwrite":true}`)
+
+		if err := handler.CategorizeTransactions(c); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+		}
+
+		var response CategorizeTransactionsResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if response.Updated != 7 {
+			t.Errorf("expected 7 updated, got %d", response.Updated)
+		}
+		if mockService.query.Filter.StatementID != "stmt-1" || !mockService.overwrite || mockService.query.To.Day() != 31 {
+			t.Errorf("unexpected query %+v, overwrite %v", mockService.query, mockService.overwrite)
+		}
+	})
+
+	t.Run("requires a date range", func(t *testing.T) {
+		handler := NewCategoryHandler(&mockCategorizationService{})
+		c, rec := newAPIKeyContext(http.MethodPost, "/transactions/categorize", `{"start":"2024-12-01"}`)
+
+		if err := handler.CategorizeTransactions(c); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+}
@@ -1,35 +1,410 @@
 package httphandler
 
-import "github.com/tsongpon/helios/internal/model"
+import (
+	"time"
+
+	"github.com/tsongpon/helios/internal/model"
+)
 
 type TransactionResponse struct {
-	CardNumber      string  `json:"card_number"`
-	UserID          string  `json:"user_id"`
-	TransactionDate string  `json:"transaction_date"`
-	PostingDate     string  `json:"posting_date"`
-	Description     string  `json:"description"`
-	Amount          float64 `json:"amount"`
-	IsInstallment   bool    `json:"is_installment"`
-	InstallmentTerm string  `json:"installment_term"`
+	ID              string   `json:"id"`
+	CardNumber      string   `json:"card_number"`
+	UserID          string   `json:"user_id"`
+	StatementID     string   `json:"statement_id"`
+	TransactionDate string   `json:"transaction_date"`
+	PostingDate     string   `json:"posting_date"`
+	Description     string   `json:"description"`
+	Merchant        string   `json:"merchant"`
+	Amount          float64  `json:"amount"`
+	IsInstallment   bool     `json:"is_installment"`
+	InstallmentTerm string   `json:"installment_term"`
+	Category        string   `json:"category"`
+	Notes           string   `json:"notes"`
+	Source          string   `json:"source"`
+	Tags            []string `json:"tags"`
+	IsBusiness      bool     `json:"is_business"`
+	// Original holds the parsed values of fields the user has edited
+	Original *TransactionFieldsResponse `json:"original,omitempty"`
+}
+
+// TransactionFieldsResponse holds values for the editable transaction
+// fields; fields that are not set are omitted
+type TransactionFieldsResponse struct {
+	TransactionDate *string  `json:"transaction_date,omitempty"`
+	PostingDate     *string  `json:"posting_date,omitempty"`
+	Description     *string  `json:"description,omitempty"`
+	Amount          *float64 `json:"amount,omitempty"`
+	Category        *string  `json:"category,omitempty"`
+	Notes           *string  `json:"notes,omitempty"`
+}
+
+// CreateTransactionRequest is the body of POST /transactions
+type CreateTransactionRequest struct {
+	TransactionDate string   `json:"transaction_date"`
+	PostingDate     string   `json:"posting_date"`
+	Description     string   `json:"description"`
+	Amount          *float64 `json:"amount"`
+	CardNumber      string   `json:"card_number"`
+	Category        string   `json:"category"`
+	Notes           string   `json:"notes"`
+}
+
+// UpdateTransactionRequest is the body of PATCH /transactions/{id}; only the
+// fields present are changed
+type UpdateTransactionRequest struct {
+	TransactionDate *string  `json:"transaction_date"`
+	PostingDate     *string  `json:"posting_date"`
+	Description     *string  `json:"description"`
+	Amount          *float64 `json:"amount"`
+	Category        *string  `json:"category"`
+	Notes           *string  `json:"notes"`
+}
+
+// TransactionPageResponse is one page of transactions; pass NextCursor as the
+// cursor query parameter to get the next page. It is omitted on the last page.
+type TransactionPageResponse struct {
+	Transactions []TransactionResponse `json:"transactions"`
+	NextCursor   string                `json:"next_cursor,omitempty"`
+}
+
+type StatementResponse struct {
+	ID              string                 `json:"id"`
+	Bank            string                 `json:"bank"`
+	CardNumber      string                 `json:"card_number"`
+	StatementDate   string                 `json:"statement_date"`
+	PeriodStart     string                 `json:"period_start"`
+	PeriodEnd       string                 `json:"period_end"`
+	PaymentDueDate  string                 `json:"payment_due_date"`
+	PreviousBalance float64                `json:"previous_balance"`
+	TotalPayment    float64                `json:"total_payment"`
+	MinimumPayment  float64                `json:"minimum_payment"`
+	CreditLine      float64                `json:"credit_line"`
+	FileHash        string                 `json:"file_hash"`
+	PromptVersion   string                 `json:"prompt_version"`
+	Model           string                 `json:"model"`
+	CreatedAt       time.Time              `json:"created_at"`
+	Reconciliation  ReconciliationResponse `json:"reconciliation"`
+	Transactions    []TransactionResponse  `json:"transactions,omitempty"`
+}
+
+type ReconciliationResponse struct {
+	Status      string  `json:"status"`
+	Delta       float64 `json:"delta"`
+	NeedsReview bool    `json:"needs_review"`
+}
+
+type JobResponse struct {
+	ID           string                `json:"id"`
+	Status       string                `json:"status"`
+	StatementID  string                `json:"statement_id,omitempty"`
+	Transactions []TransactionResponse `json:"transactions,omitempty"`
+	Error        string                `json:"error,omitempty"`
+	CreatedAt    time.Time             `json:"created_at"`
+	UpdatedAt    time.Time             `json:"updated_at"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CreateAPIKeyResponse is the only response that includes the key itself
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+type CreateCategoryRuleRequest struct {
+	Pattern  string `json:"pattern"`
+	IsRegex  bool   `json:"is_regex"`
+	Category string `json:"category"`
+}
+
+type CategoryRuleResponse struct {
+	ID        string    `json:"id"`
+	Pattern   string    `json:"pattern"`
+	IsRegex   bool      `json:"is_regex"`
+	Category  string    `json:"category"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CategorizeTransactionsRequest selects the transactions to categorize;
+// Overwrite replaces categories that were assigned automatically before
+type CategorizeTransactionsRequest struct {
+	Start       string `json:"start"`
+	End         string `json:"end"`
+	StatementID string `json:"statement_id"`
+	Overwrite   bool   `json:"overwrite"`
+}
+
+type CategorizeTransactionsResponse struct {
+	Updated int `json:"updated"`
+}
+
+// RuleRequest is the body of POST /rules and PUT /rules/{id}
+type RuleRequest struct {
+	Name      string             `json:"name"`
+	Condition string             `json:"condition"`
+	Actions   RuleActionsRequest `json:"actions"`
+}
+
+type RuleActionsRequest struct {
+	SetCategory  string   `json:"set_category"`
+	AddTags      []string `json:"add_tags"`
+	MarkBusiness bool     `json:"mark_business"`
+}
+
+type RuleResponse struct {
+	ID        string              `json:"id"`
+	Name      string              `json:"name"`
+	Condition string              `json:"condition"`
+	Actions   RuleActionsResponse `json:"actions"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+type RuleActionsResponse struct {
+	SetCategory  string   `json:"set_category,omitempty"`
+	AddTags      []string `json:"add_tags,omitempty"`
+	MarkBusiness bool     `json:"mark_business"`
+}
+
+// DryRunRuleRequest is the body of POST /rules/dry-run: a rule and the date
+// range of saved transactions to try it on
+type DryRunRuleRequest struct {
+	RuleRequest
+	Start       string `json:"start"`
+	End         string `json:"end"`
+	StatementID string `json:"statement_id"`
+}
+
+// RuleChangeResponse is a transaction as it is now and the fields the rule
+// would change it to
+type RuleChangeResponse struct {
+	Transaction TransactionResponse `json:"transaction"`
+	Category    string              `json:"category"`
+	Tags        []string            `json:"tags"`
+	IsBusiness  bool                `json:"is_business"`
+}
+
+type DryRunRuleResponse struct {
+	Changed int                  `json:"changed"`
+	Changes []RuleChangeResponse `json:"changes"`
 }
 
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
+type DuplicateStatementResponse struct {
+	Error       string `json:"error"`
+	StatementID string `json:"statement_id"`
+}
+
 func toTransactionResponses(transactions []model.Transaction) []TransactionResponse {
 	responses := make([]TransactionResponse, len(transactions))
 	for i, t := range transactions {
-		responses[i] = TransactionResponse{
-			CardNumber:      t.CardNumber,
-			UserID:          t.UserID,
-			TransactionDate: t.TransactionDate,
-			PostingDate:     t.PostingDate,
-			Description:     t.Description,
-			Amount:          t.Amount,
-			IsInstallment:   t.IsInstallment,
-			InstallmentTerm: t.InstallmentTerm,
+		responses[i] = toTransactionResponse(t)
+	}
+	return responses
+}
+
+func toTransactionResponse(t model.Transaction) TransactionResponse {
+	response := TransactionResponse{
+		ID:              t.ID,
+		CardNumber:      t.CardNumber,
+		UserID:          t.UserID,
+		StatementID:     t.StatementID,
+		TransactionDate: t.TransactionDate,
+		PostingDate:     t.PostingDate,
+		Description:     t.Description,
+		Merchant:        t.Merchant,
+		Amount:          t.Amount,
+		IsInstallment:   t.IsInstallment,
+		InstallmentTerm: t.InstallmentTerm,
+		Category:        t.Category,
+		Notes:           t.Notes,
+		Source:          string(t.Source),
+		Tags:            nonNilTags(t.Tags),
+		IsBusiness:      t.IsBusiness,
+	}
+	if !t.Original.IsEmpty() {
+		response.Original = &TransactionFieldsResponse{
+			TransactionDate: t.Original.TransactionDate,
+			PostingDate:     t.Original.PostingDate,
+			Description:     t.Original.Description,
+			Amount:          t.Original.Amount,
+			Category:        t.Original.Category,
+			Notes:           t.Original.Notes,
 		}
 	}
+	return response
+}
+
+func toTransactionPageResponse(page model.TransactionPage) TransactionPageResponse {
+	return TransactionPageResponse{
+		Transactions: toTransactionResponses(page.Transactions),
+		NextCursor:   page.NextCursor,
+	}
+}
+
+func toStatementResponse(statement model.Statement) StatementResponse {
+	return StatementResponse{
+		ID:              statement.ID,
+		Bank:            statement.Bank,
+		CardNumber:      statement.CardNumber,
+		StatementDate:   statement.StatementDate,
+		PeriodStart:     statement.PeriodStart,
+		PeriodEnd:       statement.PeriodEnd,
+		PaymentDueDate:  statement.PaymentDueDate,
+		PreviousBalance: statement.PreviousBalance,
+		TotalPayment:    statement.TotalPayment,
+		MinimumPayment:  statement.MinimumPayment,
+		CreditLine:      statement.CreditLine,
+		FileHash:        statement.FileHash,
+		PromptVersion:   statement.PromptVersion,
+		Model:           statement.Model,
+		CreatedAt:       statement.CreatedAt,
+		Reconciliation: ReconciliationResponse{
+			Status:      string(statement.ReconciliationStatus),
+			Delta:       statement.ReconciliationDelta,
+			NeedsReview: statement.ReconciliationStatus == model.ReconciliationMismatched,
+		},
+		Transactions: toTransactionResponses(statement.Transactions),
+	}
+}
+
+func toStatementResponses(statements []model.Statement) []StatementResponse {
+	responses := make([]StatementResponse, len(statements))
+	for i, s := range statements {
+		responses[i] = toStatementResponse(s)
+	}
+	return responses
+}
+
+func toJobResponse(job model.Job) JobResponse {
+	response := JobResponse{
+		ID:          job.ID,
+		Status:      string(job.Status),
+		StatementID: job.StatementID,
+		Error:       job.Error,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+	}
+	if job.Status == model.JobStatusDone {
+		response.Transactions = toTransactionResponses(job.Transactions)
+	}
+	return response
+}
+
+func toAPIKeyResponse(key model.APIKey) APIKeyResponse {
+	response := APIKeyResponse{
+		ID:        key.ID,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Scopes:    make([]string, len(key.Scopes)),
+		CreatedAt: key.CreatedAt,
+	}
+	for i, scope := range key.Scopes {
+		response.Scopes[i] = string(scope)
+	}
+	if !key.LastUsedAt.IsZero() {
+		response.LastUsedAt = &key.LastUsedAt
+	}
+	if key.Revoked() {
+		response.RevokedAt = &key.RevokedAt
+	}
+	return response
+}
+
+func toAPIKeyResponses(keys []model.APIKey) []APIKeyResponse {
+	responses := make([]APIKeyResponse, len(keys))
+	for i, k := range keys {
+		responses[i] = toAPIKeyResponse(k)
+	}
 	return responses
 }
+
+func toCategoryRuleResponse(rule model.CategoryRule) CategoryRuleResponse {
+	return CategoryRuleResponse{
+		ID:        rule.ID,
+		Pattern:   rule.Pattern,
+		IsRegex:   rule.IsRegex,
+		Category:  rule.Category,
+		CreatedAt: rule.CreatedAt,
+	}
+}
+
+func toCategoryRuleResponses(rules []model.CategoryRule) []CategoryRuleResponse {
+	responses := make([]CategoryRuleResponse, len(rules))
+	for i, r := range rules {
+		responses[i] = toCategoryRuleResponse(r)
+	}
+	return responses
+}
+
+func toRule(req RuleRequest) model.Rule {
+	return model.Rule{
+		Name:      req.Name,
+		Condition: req.Condition,
+		Actions: model.RuleActions{
+			SetCategory:  req.Actions.SetCategory,
+			AddTags:      req.Actions.AddTags,
+			MarkBusiness: req.Actions.MarkBusiness,
+		},
+	}
+}
+
+func toRuleResponse(rule model.Rule) RuleResponse {
+	return RuleResponse{
+		ID:        rule.ID,
+		Name:      rule.Name,
+		Condition: rule.Condition,
+		Actions: RuleActionsResponse{
+			SetCategory:  rule.Actions.SetCategory,
+			AddTags:      rule.Actions.AddTags,
+			MarkBusiness: rule.Actions.MarkBusiness,
+		},
+		CreatedAt: rule.CreatedAt,
+		UpdatedAt: rule.UpdatedAt,
+	}
+}
+
+func toRuleResponses(rules []model.Rule) []RuleResponse {
+	responses := make([]RuleResponse, len(rules))
+	for i, r := range rules {
+		responses[i] = toRuleResponse(r)
+	}
+	return responses
+}
+
+func toDryRunRuleResponse(changes []model.RuleChange) DryRunRuleResponse {
+	responses := make([]RuleChangeResponse, len(changes))
+	for i, c := range changes {
+		responses[i] = RuleChangeResponse{
+			Transaction: toTransactionResponse(c.Before),
+			Category:    c.After.Category,
+			Tags:        nonNilTags(c.After.Tags),
+			IsBusiness:  c.After.IsBusiness,
+		}
+	}
+	return DryRunRuleResponse{Changed: len(changes), Changes: responses}
+}
+
+// nonNilTags makes transactions without tags render as an empty list
+func nonNilTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
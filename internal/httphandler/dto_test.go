@@ -10,6 +10,7 @@ func TestToTransactionResponses(t *testing.T) {
 	t.Run("converts transactions to responses correctly", func(t *testing.T) {
 		transactions := []model.Transaction{
 			{
+				ID:              "txn-1",
 				UserID:          "user123",
 				CardNumber:      "1234-XXXX-XXXX-5678",
 				TransactionDate: "2024-12-15",
@@ -38,6 +39,9 @@ func TestToTransactionResponses(t *testing.T) {
 		}
 
 		// Check first transaction
+		if responses[0].ID != "txn-1" {
+			t.Errorf("expected ID txn-1, got %s", responses[0].ID)
+		}
 		if responses[0].UserID != "user123" {
 			t.Errorf("expected UserID user123, got %s", responses[0].UserID)
 		}
@@ -128,3 +132,73 @@ func TestToTransactionResponses(t *testing.T) {
 		}
 	})
 }
+
+func TestToStatementResponse(t *testing.T) {
+	statement := model.Statement{
+		ID:                   "stmt-1",
+		Bank:                 "KTC",
+		CardNumber:           "1234-56XX-XXXX-7890",
+		StatementDate:        "2025-01-20",
+		PeriodStart:          "2024-12-21",
+		PeriodEnd:            "2025-01-20",
+		PaymentDueDate:       "2025-02-06",
+		PreviousBalance:      5000.00,
+		TotalPayment:         15000.00,
+		MinimumPayment:       1500.00,
+		CreditLine:           100000.00,
+		FileHash:             "abc123",
+		ReconciliationStatus: model.ReconciliationMismatched,
+		ReconciliationDelta:  -250.00,
+		Transactions: []model.Transaction{
+			{StatementID: "stmt-1", Description: "AMAZON", Amount: 100.50},
+		},
+	}
+
+	response := toStatementResponse(statement)
+
+	if response.ID != "stmt-1" {
+		t.Errorf("expected ID stmt-1, got %s", response.ID)
+	}
+	if response.Bank != "KTC" {
+		t.Errorf("expected Bank KTC, got %s", response.Bank)
+	}
+	if response.CardNumber != "1234-56XX-XXXX-7890" {
+		t.Errorf("expected CardNumber 1234-56XX-XXXX-7890, got %s", response.CardNumber)
+	}
+	if response.PeriodStart != "2024-12-21" || response.PeriodEnd != "2025-01-20" {
+		t.Errorf("unexpected period %s - %s", response.PeriodStart, response.PeriodEnd)
+	}
+	if response.PaymentDueDate != "2025-02-06" {
+		t.Errorf("expected PaymentDueDate 2025-02-06, got %s", response.PaymentDueDate)
+	}
+	if response.PreviousBalance != 5000.00 {
+		t.Errorf("expected PreviousBalance 5000.00, got %f", response.PreviousBalance)
+	}
+	if response.TotalPayment != 15000.00 {
+		t.Errorf("expected TotalPayment 15000.00, got %f", response.TotalPayment)
+	}
+	if response.MinimumPayment != 1500.00 {
+		t.Errorf("expected MinimumPayment 1500.00, got %f", response.MinimumPayment)
+	}
+	if response.CreditLine != 100000.00 {
+		t.Errorf("expected CreditLine 100000.00, got %f", response.CreditLine)
+	}
+	if response.FileHash != "abc123" {
+		t.Errorf("expected FileHash abc123, got %s", response.FileHash)
+	}
+	if response.Reconciliation.Status != "mismatched" {
+		t.Errorf("expected reconciliation status mismatched, got %s", response.Reconciliation.Status)
+	}
+	if response.Reconciliation.Delta != -250.00 {
+		t.Errorf("expected reconciliation delta -250.00, got %f", response.Reconciliation.Delta)
+	}
+	if !response.Reconciliation.NeedsReview {
+		t.Error("expected mismatched statement to need review")
+	}
+	if len(response.Transactions) != 1 {
+		t.Fatalf("expected 1 transaction, got %d", len(response.Transactions))
+	}
+	if response.Transactions[0].StatementID != "stmt-1" {
+		t.Errorf("expected StatementID stmt-1, got %s", response.Transactions[0].StatementID)
+	}
+}
@@ -0,0 +1,39 @@
+package httphandler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/tsongpon/helios/internal/model"
+)
+
+type JobHandler struct {
+	jobService JobService
+}
+
+func NewJobHandler(jobService JobService) *JobHandler {
+	return &JobHandler{
+		jobService: jobService,
+	}
+}
+
+func (h *JobHandler) GetJob(c *echo.Context) error {
+	jobID := c.Param("id")
+
+	userID := currentUserID(c)
+
+	job, err := h.jobService.GetJob(c.Request().Context(), userID, jobID)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error: "job not found",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "failed to get job: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, toJobResponse(job))
+}
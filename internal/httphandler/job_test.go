@@ -0,0 +1,173 @@
+package httphandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/tsongpon/helios/internal/model"
+)
+
+type mockJobService struct {
+	job             model.Job
+	err             error
+	receivedContent []byte
+}
+
+func (m *mockJobService) Submit(ctx context.Context, userID string, content []byte, password string) (model.Job, error) {
+	m.receivedContent = content
+	if m.err != nil {
+		return model.Job{}, m.err
+	}
+	return m.job, nil
+}
+
+func (m *mockJobService) GetJob(ctx context.Context, userID, jobID string) (model.Job, error) {
+	if m.err != nil {
+		return model.Job{}, m.err
+	}
+	return m.job, nil
+}
+
+func TestJobHandler_GetJob(t *testing.T) {
+	t.Run("returns finished job with transactions", func(t *testing.T) {
+		mockService := &mockJobService{
+			job: model.Job{
+				ID:     "job-1",
+				UserID: "1234567890",
+				Status: model.JobStatusDone,
+				Transactions: []model.Transaction{
+					{Description: "AMAZON", Amount: 100.50},
+				},
+				CreatedAt: time.Date(2024, 12, 15, 0, 0, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2024, 12, 15, 0, 1, 0, 0, time.UTC),
+			},
+		}
+		handler := NewJobHandler(mockService)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/jobs/job-1", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPathValues(echo.PathValues{{Name: "id", Value: "job-1"}})
+
+		err := handler.GetJob(c)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected status %d, got %d", http.StatusOK, rec.Code)
+		}
+
+		var response JobResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+
+		if response.ID != "job-1" {
+			t.Errorf("expected ID job-1, got %s", response.ID)
+		}
+		if response.Status != "done" {
+			t.Errorf("expected status done, got %s", response.Status)
+		}
+		if len(response.Transactions) != 1 {
+			t.Fatalf("expected 1 transaction, got %d", len(response.Transactions))
+		}
+		if response.Transactions[0].Description != "AMAZON" {
+			t.Errorf("expected description AMAZON, got %s", response.Transactions[0].Description)
+		}
+	})
+
+	t.Run("returns failed job with error", func(t *testing.T) {
+		mockService := &mockJobService{
+			job: model.Job{
+				ID:     "job-1",
+				Status: model.JobStatusFailed,
+				Error:  "failed to parse statement: boom",
+			},
+		}
+		handler := NewJobHandler(mockService)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/jobs/job-1", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPathValues(echo.PathValues{{Name: "id", Value: "job-1"}})
+
+		err := handler.GetJob(c)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		var response JobResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+
+		if response.Status != "failed" {
+			t.Errorf("expected status failed, got %s", response.Status)
+		}
+		if response.Error != "failed to parse statement: boom" {
+			t.Errorf("unexpected error: %s", response.Error)
+		}
+	})
+
+	t.Run("returns not found for unknown job", func(t *testing.T) {
+		mockService := &mockJobService{err: model.ErrNotFound}
+		handler := NewJobHandler(mockService)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/jobs/missing", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPathValues(echo.PathValues{{Name: "id", Value: "missing"}})
+
+		err := handler.GetJob(c)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+		}
+	})
+
+	t.Run("returns error when service fails", func(t *testing.T) {
+		mockService := &mockJobService{err: errors.New("database error")}
+		handler := NewJobHandler(mockService)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/jobs/job-1", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPathValues(echo.PathValues{{Name: "id", Value: "job-1"}})
+
+		err := handler.GetJob(c)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rec.Code)
+		}
+
+		var response ErrorResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+
+		if response.Error != "failed to get job: database error" {
+			t.Errorf("unexpected error message: %s", response.Error)
+		}
+	})
+}
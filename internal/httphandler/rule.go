@@ -0,0 +1,133 @@
+package httphandler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/tsongpon/helios/internal/model"
+)
+
+type RuleHandler struct {
+	ruleService RuleService
+}
+
+func NewRuleHandler(ruleService RuleService) *RuleHandler {
+	return &RuleHandler{
+		ruleService: ruleService,
+	}
+}
+
+func (h *RuleHandler) CreateRule(c *echo.Context) error {
+	var req RuleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "invalid request body",
+		})
+	}
+
+	rule, err := h.ruleService.CreateRule(c.Request().Context(), currentUserID(c), toRule(req))
+	if err != nil {
+		return ruleError(c, "failed to create rule: ", err)
+	}
+
+	return c.JSON(http.StatusCreated, toRuleResponse(rule))
+}
+
+func (h *RuleHandler) GetRules(c *echo.Context) error {
+	rules, err := h.ruleService.GetRules(c.Request().Context(), currentUserID(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "failed to get rules: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, toRuleResponses(rules))
+}
+
+func (h *RuleHandler) GetRule(c *echo.Context) error {
+	rule, err := h.ruleService.GetRule(c.Request().Context(), currentUserID(c), c.Param("id"))
+	if err != nil {
+		return ruleError(c, "failed to get rule: ", err)
+	}
+
+	return c.JSON(http.StatusOK, toRuleResponse(rule))
+}
+
+func (h *RuleHandler) UpdateRule(c *echo.Context) error {
+	var req RuleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "invalid request body",
+		})
+	}
+
+	rule, err := h.ruleService.UpdateRule(c.Request().Context(), currentUserID(c), c.Param("id"), toRule(req))
+	if err != nil {
+		return ruleError(c, "failed to update rule: ", err)
+	}
+
+	return c.JSON(http.StatusOK, toRuleResponse(rule))
+}
+
+func (h *RuleHandler) DeleteRule(c *echo.Context) error {
+	if err := h.ruleService.DeleteRule(c.Request().Context(), currentUserID(c), c.Param("id")); err != nil {
+		return ruleError(c, "failed to delete rule: ", err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DryRun reports which of the user's saved transactions in a date range a
+// rule would change, without saving anything
+func (h *RuleHandler) DryRun(c *echo.Context) error {
+	var req DryRunRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "invalid request body",
+		})
+	}
+
+	from, err := time.Parse("2006-01-02", req.Start)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "start is required (format: YYYY-MM-DD)",
+		})
+	}
+	to, err := time.Parse("2006-01-02", req.End)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "end is required (format: YYYY-MM-DD)",
+		})
+	}
+
+	query := model.TransactionQuery{
+		From:   from,
+		To:     to,
+		Filter: model.TransactionFilter{StatementID: req.StatementID},
+	}
+	changes, err := h.ruleService.DryRun(c.Request().Context(), currentUserID(c), toRule(req.RuleRequest), query)
+	if err != nil {
+		return ruleError(c, "failed to dry run rule: ", err)
+	}
+
+	return c.JSON(http.StatusOK, toDryRunRuleResponse(changes))
+}
+
+// ruleError maps an error from the rule service to a response
+func ruleError(c *echo.Context, message string, err error) error {
+	switch {
+	case errors.Is(err, model.ErrInvalidRule):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+	case errors.Is(err, model.ErrNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error: "rule not found",
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, ErrorResponse{
+		Error: message + err.Error(),
+	})
+}
@@ -0,0 +1,149 @@
+package httphandler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+type mockRuleService struct {
+	rule      model.Rule
+	rules     []model.Rule
+	changes   []model.RuleChange
+	err       error
+	ruleID    string
+	deletedID string
+	query     model.TransactionQuery
+}
+
+func (m *mockRuleService) CreateRule(ctx context.Context, userID string, rule model.Rule) (model.Rule, error) {
+	m.rule = rule
+	rule.ID = "rule-1"
+	return rule, m.err
+}
+
+func (m *mockRuleService) GetRules(ctx context.Context, userID string) ([]model.Rule, error) {
+	return m.rules, m.err
+}
+
+func (m *mockRuleService) GetRule(ctx context.Context, userID, ruleID string) (model.Rule, error) {
+	m.ruleID = ruleID
+	return m.rule, m.err
+}
+
+func (m *mockRuleService) UpdateRule(ctx context.Context, userID, ruleID string, rule model.Rule) (model.Rule, error) {
+	m.ruleID = ruleID
+	m.rule = rule
+	rule.ID = ruleID
+	return rule, m.err
+}
+
+func (m *mockRuleService) DeleteRule(ctx context.Context, userID, ruleID string) error {
+	m.deletedID = ruleID
+	return m.err
+}
+
+func (m *mockRuleService) DryRun(ctx context.Context, userID string, rule model.Rule, query model.TransactionQuery) ([]model.RuleChange, error) {
+	m.rule = rule
+	m.query = query
+	return m.changes, m.err
+}
+
+func TestRuleHandler_CreateRule(t *testing.T) {
+	t.Run("creates the rule", func(t *testing.T) {
+		mockService := &mockRuleService{}
+		handler := NewRuleHandler(mockService)
+		c, rec := newAPIKeyContext(http.MethodPost, "/rules", `{"name":"Work rides","condition":"merchant == \"Grab\" and amount > 500","actions":{"set_category":"transport","add_tags":["work"],"mark_business":true}}`)
+
+		if err := handler.CreateRule(c); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, rec.Code)
+		}
+
+		var response RuleResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if response.ID != "rule-1" || response.Name != "Work rides" || response.Condition != `merchant == "Grab" and amount > 500` {
+			t.Errorf("unexpected response %+v", response)
+		}
+		if a := response.Actions; a.SetCategory != "transport" || !slices.Equal(a.AddTags, []string{"work"}) || !a.MarkBusiness {
+			t.Errorf("unexpected actions %+v", a)
+		}
+	})
+
+	t.Run("returns bad request for invalid rules", func(t *testing.T) {
+		handler := NewRuleHandler(&mockRuleService{err: fmt.Errorf("%w: unknown field %q at position 0", model.ErrInvalidRule, "payee")})
+		c, rec := newAPIKeyContext(http.MethodPost, "/rules", `{"condition":"payee == \"Grab\"","actions":{"mark_business":true}}`)
+
+		if err := handler.CreateRule(c); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+}
+
+func TestRuleHandler_GetRule_NotFound(t *testing.T) {
+	handler := NewRuleHandler(&mockRuleService{err: model.ErrNotFound})
+	c, rec := newAPIKeyContext(http.MethodGet, "/rules/rule-1", "")
+
+	if err := handler.GetRule(c); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestRuleHandler_DryRun(t *testing.T) {
+	t.Run("returns the changes", func(t *testing.T) {
+		mockService := &mockRuleService{changes: []model.RuleChange{{
+			Before: model.Transaction{ID: "t1", Merchant: "Grab"},
+			After:  model.Transaction{ID: "t1", Merchant: "Grab", Category: "transport", Tags: []string{"work"}, IsBusiness: true},
+		}}}
+		handler := NewRuleHandler(mockService)
+		c, rec := newAPIKeyContext(http.MethodPost, "/rules/dry-run", `{"condition":"merchant == \"Grab\"","actions":{"set_category":"transport"},"start":"2024-12-01","end":"2024-12-31"}`)
+
+		if err := handler.DryRun(c); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+		}
+
+		var response DryRunRuleResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if response.Changed != 1 || response.Changes[0].Transaction.ID != "t1" || response.Changes[0].Transaction.Category != "" {
+			t.Fatalf("unexpected response %+v", response)
+		}
+		if change := response.Changes[0]; change.Category != "transport" || !slices.Equal(change.Tags, []string{"work"}) || !change.IsBusiness {
+			t.Errorf("unexpected change %+v", change)
+		}
+		if mockService.rule.Condition != `merchant == "Grab"` || mockService.query.To.Day() != 31 {
+			t.Errorf("unexpected rule %+v or query %+v", mockService.rule, mockService.query)
+		}
+	})
+
+	t.Run("requires a date range", func(t *testing.T) {
+		handler := NewRuleHandler(&mockRuleService{})
+		c, rec := newAPIKeyContext(http.MethodPost, "/rules/dry-run", `{"condition":"amount > 5","actions":{"mark_business":true}}`)
+
+		if err := handler.DryRun(c); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+}
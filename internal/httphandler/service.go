@@ -3,15 +3,56 @@ package httphandler
 import (
 	"context"
 	"io"
-	"time"
 
 	"github.com/tsongpon/helios/internal/model"
 )
 
 type PDFService interface {
-	ExtractText(ctx context.Context, userID string, file io.Reader, password string) ([]model.Transaction, error)
+	ExtractText(ctx context.Context, userID string, file io.Reader, password string) (model.Statement, error)
+	ReparseStatement(ctx context.Context, userID, statementID string) (model.Statement, error)
+}
+
+type StatementService interface {
+	GetStatements(ctx context.Context, userID string) ([]model.Statement, error)
+	GetStatement(ctx context.Context, userID, statementID string) (model.Statement, error)
+	DeleteStatement(ctx context.Context, userID, statementID string) error
 }
 
 type TransactionService interface {
-	GetTransactions(ctx context.Context, userID string, from, to time.Time) ([]model.Transaction, error)
+	GetTransactions(ctx context.Context, userID string, query model.TransactionQuery) (model.TransactionPage, error)
+	CreateTransaction(ctx context.Context, userID string, transaction model.Transaction) (model.Transaction, error)
+	UpdateTransaction(ctx context.Context, userID, transactionID string, patch model.TransactionPatch) (model.Transaction, error)
+	DeleteTransaction(ctx context.Context, userID, transactionID string) error
+}
+
+type CategorizationService interface {
+	GetCategories() []string
+	CreateCategoryRule(ctx context.Context, userID string, rule model.CategoryRule) (model.CategoryRule, error)
+	GetCategoryRules(ctx context.Context, userID string) ([]model.CategoryRule, error)
+	DeleteCategoryRule(ctx context.Context, userID, ruleID string) error
+	Recategorize(ctx context.Context, userID string, query model.TransactionQuery, overwrite bool) (int, error)
+}
+
+type RuleService interface {
+	CreateRule(ctx context.Context, userID string, rule model.Rule) (model.Rule, error)
+	GetRules(ctx context.Context, userID string) ([]model.Rule, error)
+	GetRule(ctx context.Context, userID, ruleID string) (model.Rule, error)
+	UpdateRule(ctx context.Context, userID, ruleID string, rule model.Rule) (model.Rule, error)
+	DeleteRule(ctx context.Context, userID, ruleID string) error
+	DryRun(ctx context.Context, userID string, rule model.Rule, query model.TransactionQuery) ([]model.RuleChange, error)
+}
+
+type JobService interface {
+	Submit(ctx context.Context, userID string, content []byte, password string) (model.Job, error)
+	GetJob(ctx context.Context, userID, jobID string) (model.Job, error)
+}
+
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, userID, name string, scopes []model.Scope) (model.APIKey, string, error)
+	GetAPIKeys(ctx context.Context, userID string) ([]model.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID, keyID string) error
+}
+
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (model.Principal, error)
 }
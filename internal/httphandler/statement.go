@@ -1,18 +1,25 @@
 package httphandler
 
 import (
+	"errors"
+	"io"
 	"net/http"
 
 	"github.com/labstack/echo/v5"
+	"github.com/tsongpon/helios/internal/model"
 )
 
 type StatementHandler struct {
-	pdfService PDFService
+	pdfService       PDFService
+	jobService       JobService
+	statementService StatementService
 }
 
-func NewStatementHandler(pdfService PDFService) *StatementHandler {
+func NewStatementHandler(pdfService PDFService, jobService JobService, statementService StatementService) *StatementHandler {
 	return &StatementHandler{
-		pdfService: pdfService,
+		pdfService:       pdfService,
+		jobService:       jobService,
+		statementService: statementService,
 	}
 }
 
@@ -47,17 +54,118 @@ func (h *StatementHandler) CreateStatement(c *echo.Context) error {
 	}
 	defer src.Close()
 
-	// Fix userID for now
-	// TODO: Implement user authentication and authorization
-	userID := "1234567890"
+	userID := currentUserID(c)
+
+	// Queue the statement for background processing when requested
+	if c.QueryParam("async") == "true" {
+		return h.createStatementJob(c, userID, src, password)
+	}
 
 	// Extract text from PDF and parse transactions
-	transactions, err := h.pdfService.ExtractText(c.Request().Context(), userID, src, password)
+	statement, err := h.pdfService.ExtractText(c.Request().Context(), userID, src, password)
 	if err != nil {
+		var duplicate *model.DuplicateStatementError
+		if errors.As(err, &duplicate) {
+			c.Response().Header().Set("Location", "/statements/"+duplicate.StatementID)
+			return c.JSON(http.StatusConflict, DuplicateStatementResponse{
+				Error:       "statement has already been uploaded",
+				StatementID: duplicate.StatementID,
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error: "failed to extract text from PDF: " + err.Error(),
 		})
 	}
 
-	return c.JSON(http.StatusOK, toTransactionResponses(transactions))
+	return c.JSON(http.StatusOK, toStatementResponse(statement))
+}
+
+func (h *StatementHandler) createStatementJob(c *echo.Context, userID string, src io.Reader, password string) error {
+	content, err := io.ReadAll(src)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "failed to read uploaded file",
+		})
+	}
+
+	job, err := h.jobService.Submit(c.Request().Context(), userID, content, password)
+	if err != nil {
+		if errors.Is(err, model.ErrJobQueueFull) {
+			return c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+				Error: "too many statements are being processed, please retry later",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "failed to queue statement: " + err.Error(),
+		})
+	}
+
+	c.Response().Header().Set("Location", "/jobs/"+job.ID)
+	return c.JSON(http.StatusAccepted, toJobResponse(job))
+}
+
+func (h *StatementHandler) GetStatements(c *echo.Context) error {
+	userID := currentUserID(c)
+
+	statements, err := h.statementService.GetStatements(c.Request().Context(), userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "failed to get statements: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, toStatementResponses(statements))
+}
+
+func (h *StatementHandler) GetStatement(c *echo.Context) error {
+	userID := currentUserID(c)
+
+	statement, err := h.statementService.GetStatement(c.Request().Context(), userID, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error: "statement not found",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "failed to get statement: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, toStatementResponse(statement))
+}
+
+func (h *StatementHandler) DeleteStatement(c *echo.Context) error {
+	userID := currentUserID(c)
+
+	if err := h.statementService.DeleteStatement(c.Request().Context(), userID, c.Param("id")); err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error: "statement not found",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "failed to delete statement: " + err.Error(),
+		})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *StatementHandler) ReparseStatement(c *echo.Context) error {
+	userID := currentUserID(c)
+
+	statement, err := h.pdfService.ReparseStatement(c.Request().Context(), userID, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error: "statement not found",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "failed to reparse statement: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, toStatementResponse(statement))
 }
@@ -16,34 +16,77 @@ import (
 )
 
 type mockPDFService struct {
-	transactions []model.Transaction
-	err          error
+	statement model.Statement
+	err       error
 }
 
-func (m *mockPDFService) ExtractText(ctx context.Context, userID string, file io.Reader, password string) ([]model.Transaction, error) {
+func (m *mockPDFService) ExtractText(ctx context.Context, userID string, file io.Reader, password string) (model.Statement, error) {
+	if m.err != nil {
+		return model.Statement{}, m.err
+	}
+	return m.statement, nil
+}
+
+func (m *mockPDFService) ReparseStatement(ctx context.Context, userID, statementID string) (model.Statement, error) {
+	if m.err != nil {
+		return model.Statement{}, m.err
+	}
+	return m.statement, nil
+}
+
+type mockStatementService struct {
+	statements []model.Statement
+	statement  model.Statement
+	err        error
+	deletedID  string
+}
+
+func (m *mockStatementService) GetStatements(ctx context.Context, userID string) ([]model.Statement, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
-	return m.transactions, nil
+	return m.statements, nil
+}
+
+func (m *mockStatementService) GetStatement(ctx context.Context, userID, statementID string) (model.Statement, error) {
+	if m.err != nil {
+		return model.Statement{}, m.err
+	}
+	return m.statement, nil
+}
+
+func (m *mockStatementService) DeleteStatement(ctx context.Context, userID, statementID string) error {
+	m.deletedID = statementID
+	return m.err
 }
 
 func TestStatementHandler_CreateStatement(t *testing.T) {
-	t.Run("returns transactions successfully", func(t *testing.T) {
+	t.Run("returns statement with transactions successfully", func(t *testing.T) {
 		mockService := &mockPDFService{
-			transactions: []model.Transaction{
-				{
-					UserID:          "1234567890",
-					CardNumber:      "1234-XXXX-XXXX-5678",
-					TransactionDate: "2024-12-15",
-					PostingDate:     "2024-12-16",
-					Description:     "AMAZON",
-					Amount:          100.50,
-					IsInstallment:   false,
+			statement: model.Statement{
+				ID:             "stmt-1",
+				Bank:           "KTC",
+				CardNumber:     "1234-XXXX-XXXX-5678",
+				PaymentDueDate: "2025-01-06",
+				TotalPayment:   15000.00,
+				MinimumPayment: 1500.00,
+				CreditLine:     100000.00,
+				Transactions: []model.Transaction{
+					{
+						UserID:          "1234567890",
+						StatementID:     "stmt-1",
+						CardNumber:      "1234-XXXX-XXXX-5678",
+						TransactionDate: "2024-12-15",
+						PostingDate:     "2024-12-16",
+						Description:     "AMAZON",
+						Amount:          100.50,
+						IsInstallment:   false,
+					},
 				},
 			},
 		}
 
-		handler := NewStatementHandler(mockService)
+		handler := NewStatementHandler(mockService, &mockJobService{}, &mockStatementService{})
 
 		body := &bytes.Buffer{}
 		writer := multipart.NewWriter(body)
@@ -70,23 +113,45 @@ func TestStatementHandler_CreateStatement(t *testing.T) {
 			t.Errorf("expected status %d, got %d", http.StatusOK, rec.Code)
 		}
 
-		var response []TransactionResponse
+		var response StatementResponse
 		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
 			t.Fatalf("failed to unmarshal response: %v", err)
 		}
 
-		if len(response) != 1 {
-			t.Fatalf("expected 1 transaction, got %d", len(response))
+		if response.ID != "stmt-1" {
+			t.Errorf("expected ID stmt-1, got %s", response.ID)
+		}
+		if response.CardNumber != "1234-XXXX-XXXX-5678" {
+			t.Errorf("expected card number 1234-XXXX-XXXX-5678, got %s", response.CardNumber)
+		}
+		if response.TotalPayment != 15000.00 {
+			t.Errorf("expected total payment 15000.00, got %f", response.TotalPayment)
+		}
+		if response.MinimumPayment != 1500.00 {
+			t.Errorf("expected minimum payment 1500.00, got %f", response.MinimumPayment)
+		}
+		if response.PaymentDueDate != "2025-01-06" {
+			t.Errorf("expected payment due date 2025-01-06, got %s", response.PaymentDueDate)
+		}
+		if response.CreditLine != 100000.00 {
+			t.Errorf("expected credit line 100000.00, got %f", response.CreditLine)
+		}
+
+		if len(response.Transactions) != 1 {
+			t.Fatalf("expected 1 transaction, got %d", len(response.Transactions))
 		}
 
-		if response[0].Description != "AMAZON" {
-			t.Errorf("expected description AMAZON, got %s", response[0].Description)
+		if response.Transactions[0].Description != "AMAZON" {
+			t.Errorf("expected description AMAZON, got %s", response.Transactions[0].Description)
+		}
+		if response.Transactions[0].StatementID != "stmt-1" {
+			t.Errorf("expected statement ID stmt-1, got %s", response.Transactions[0].StatementID)
 		}
 	})
 
 	t.Run("returns error when file is missing", func(t *testing.T) {
 		mockService := &mockPDFService{}
-		handler := NewStatementHandler(mockService)
+		handler := NewStatementHandler(mockService, &mockJobService{}, &mockStatementService{})
 
 		e := echo.New()
 		req := httptest.NewRequest(http.MethodPost, "/statements", nil)
@@ -115,7 +180,7 @@ func TestStatementHandler_CreateStatement(t *testing.T) {
 
 	t.Run("returns error when file is not PDF", func(t *testing.T) {
 		mockService := &mockPDFService{}
-		handler := NewStatementHandler(mockService)
+		handler := NewStatementHandler(mockService, &mockJobService{}, &mockStatementService{})
 
 		body := &bytes.Buffer{}
 		writer := multipart.NewWriter(body)
@@ -156,7 +221,7 @@ func TestStatementHandler_CreateStatement(t *testing.T) {
 		mockService := &mockPDFService{
 			err: errors.New("failed to parse PDF"),
 		}
-		handler := NewStatementHandler(mockService)
+		handler := NewStatementHandler(mockService, &mockJobService{}, &mockStatementService{})
 
 		body := &bytes.Buffer{}
 		writer := multipart.NewWriter(body)
@@ -195,9 +260,9 @@ func TestStatementHandler_CreateStatement(t *testing.T) {
 
 	t.Run("accepts PDF with application/pdf content type", func(t *testing.T) {
 		mockService := &mockPDFService{
-			transactions: []model.Transaction{},
+			statement: model.Statement{},
 		}
-		handler := NewStatementHandler(mockService)
+		handler := NewStatementHandler(mockService, &mockJobService{}, &mockStatementService{})
 
 		body := &bytes.Buffer{}
 		writer := multipart.NewWriter(body)
@@ -227,4 +292,363 @@ func TestStatementHandler_CreateStatement(t *testing.T) {
 			t.Errorf("expected status %d, got %d", http.StatusOK, rec.Code)
 		}
 	})
+
+	t.Run("returns conflict for duplicate upload", func(t *testing.T) {
+		mockService := &mockPDFService{
+			err: &model.DuplicateStatementError{StatementID: "stmt-1"},
+		}
+		handler := NewStatementHandler(mockService, &mockJobService{}, &mockStatementService{})
+
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("file", "test.pdf")
+		if err != nil {
+			t.Fatalf("failed to create form file: %v", err)
+		}
+		part.Write([]byte("fake pdf content"))
+		writer.Close()
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/statements", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err = handler.CreateStatement(c)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if rec.Code != http.StatusConflict {
+			t.Errorf("expected status %d, got %d", http.StatusConflict, rec.Code)
+		}
+
+		if rec.Header().Get("Location") != "/statements/stmt-1" {
+			t.Errorf("expected Location /statements/stmt-1, got %s", rec.Header().Get("Location"))
+		}
+
+		var response DuplicateStatementResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+
+		if response.StatementID != "stmt-1" {
+			t.Errorf("expected statement ID stmt-1, got %s", response.StatementID)
+		}
+	})
+
+	t.Run("queues statement when async is requested", func(t *testing.T) {
+		mockService := &mockPDFService{}
+		mockJobs := &mockJobService{
+			job: model.Job{ID: "job-1", Status: model.JobStatusQueued},
+		}
+		handler := NewStatementHandler(mockService, mockJobs, &mockStatementService{})
+
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("file", "test.pdf")
+		if err != nil {
+			t.Fatalf("failed to create form file: %v", err)
+		}
+		part.Write([]byte("fake pdf content"))
+		writer.Close()
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/statements?async=true", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err = handler.CreateStatement(c)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if rec.Code != http.StatusAccepted {
+			t.Errorf("expected status %d, got %d", http.StatusAccepted, rec.Code)
+		}
+
+		if rec.Header().Get("Location") != "/jobs/job-1" {
+			t.Errorf("expected Location /jobs/job-1, got %s", rec.Header().Get("Location"))
+		}
+
+		if string(mockJobs.receivedContent) != "fake pdf content" {
+			t.Errorf("expected job to receive file content, got %q", mockJobs.receivedContent)
+		}
+
+		var response JobResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+
+		if response.ID != "job-1" {
+			t.Errorf("expected job ID job-1, got %s", response.ID)
+		}
+		if response.Status != "queued" {
+			t.Errorf("expected status queued, got %s", response.Status)
+		}
+	})
+
+	t.Run("returns service unavailable when job queue is full", func(t *testing.T) {
+		mockService := &mockPDFService{}
+		mockJobs := &mockJobService{err: model.ErrJobQueueFull}
+		handler := NewStatementHandler(mockService, mockJobs, &mockStatementService{})
+
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("file", "test.pdf")
+		if err != nil {
+			t.Fatalf("failed to create form file: %v", err)
+		}
+		part.Write([]byte("fake pdf content"))
+		writer.Close()
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/statements?async=true", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err = handler.CreateStatement(c)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status %d, got %d", http.StatusServiceUnavailable, rec.Code)
+		}
+	})
+}
+
+func TestStatementHandler_GetStatements(t *testing.T) {
+	t.Run("returns statements successfully", func(t *testing.T) {
+		mockStatements := &mockStatementService{
+			statements: []model.Statement{
+				{ID: "stmt-1", Bank: "KTC", TotalPayment: 15000.00},
+				{ID: "stmt-2", Bank: "SCB", TotalPayment: 2000.00},
+			},
+		}
+		handler := NewStatementHandler(&mockPDFService{}, &mockJobService{}, mockStatements)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/statements", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.GetStatements(c)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected status %d, got %d", http.StatusOK, rec.Code)
+		}
+
+		var response []StatementResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+
+		if len(response) != 2 {
+			t.Fatalf("expected 2 statements, got %d", len(response))
+		}
+		if response[1].Bank != "SCB" {
+			t.Errorf("expected bank SCB, got %s", response[1].Bank)
+		}
+	})
+
+	t.Run("returns error when service fails", func(t *testing.T) {
+		mockStatements := &mockStatementService{err: errors.New("database error")}
+		handler := NewStatementHandler(&mockPDFService{}, &mockJobService{}, mockStatements)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/statements", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := handler.GetStatements(c)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rec.Code)
+		}
+	})
+}
+
+func TestStatementHandler_GetStatement(t *testing.T) {
+	t.Run("returns statement with transactions", func(t *testing.T) {
+		mockStatements := &mockStatementService{
+			statement: model.Statement{
+				ID:   "stmt-1",
+				Bank: "KTC",
+				Transactions: []model.Transaction{
+					{StatementID: "stmt-1", Description: "AMAZON", Amount: 100.50},
+				},
+			},
+		}
+		handler := NewStatementHandler(&mockPDFService{}, &mockJobService{}, mockStatements)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/statements/stmt-1", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPathValues(echo.PathValues{{Name: "id", Value: "stmt-1"}})
+
+		err := handler.GetStatement(c)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected status %d, got %d", http.StatusOK, rec.Code)
+		}
+
+		var response StatementResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+
+		if response.ID != "stmt-1" {
+			t.Errorf("expected ID stmt-1, got %s", response.ID)
+		}
+		if len(response.Transactions) != 1 {
+			t.Fatalf("expected 1 transaction, got %d", len(response.Transactions))
+		}
+	})
+
+	t.Run("returns not found for unknown statement", func(t *testing.T) {
+		mockStatements := &mockStatementService{err: model.ErrNotFound}
+		handler := NewStatementHandler(&mockPDFService{}, &mockJobService{}, mockStatements)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/statements/missing", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPathValues(echo.PathValues{{Name: "id", Value: "missing"}})
+
+		err := handler.GetStatement(c)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+		}
+	})
+}
+
+func TestStatementHandler_DeleteStatement(t *testing.T) {
+	t.Run("deletes statement", func(t *testing.T) {
+		mockStatements := &mockStatementService{}
+		handler := NewStatementHandler(&mockPDFService{}, &mockJobService{}, mockStatements)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodDelete, "/statements/stmt-1", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPathValues(echo.PathValues{{Name: "id", Value: "stmt-1"}})
+
+		err := handler.DeleteStatement(c)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if rec.Code != http.StatusNoContent {
+			t.Errorf("expected status %d, got %d", http.StatusNoContent, rec.Code)
+		}
+		if mockStatements.deletedID != "stmt-1" {
+			t.Errorf("expected stmt-1 to be deleted, got %s", mockStatements.deletedID)
+		}
+	})
+
+	t.Run("returns not found for unknown statement", func(t *testing.T) {
+		mockStatements := &mockStatementService{err: model.ErrNotFound}
+		handler := NewStatementHandler(&mockPDFService{}, &mockJobService{}, mockStatements)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodDelete, "/statements/missing", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPathValues(echo.PathValues{{Name: "id", Value: "missing"}})
+
+		err := handler.DeleteStatement(c)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+		}
+	})
+}
+
+func TestStatementHandler_ReparseStatement(t *testing.T) {
+	t.Run("returns reparsed statement", func(t *testing.T) {
+		mockService := &mockPDFService{
+			statement: model.Statement{
+				ID: "stmt-1",
+				Transactions: []model.Transaction{
+					{StatementID: "stmt-1", Description: "AMAZON", Amount: 100.50},
+				},
+			},
+		}
+		handler := NewStatementHandler(mockService, &mockJobService{}, &mockStatementService{})
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/statements/stmt-1/reparse", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPathValues(echo.PathValues{{Name: "id", Value: "stmt-1"}})
+
+		err := handler.ReparseStatement(c)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected status %d, got %d", http.StatusOK, rec.Code)
+		}
+
+		var response StatementResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+
+		if len(response.Transactions) != 1 {
+			t.Fatalf("expected 1 transaction, got %d", len(response.Transactions))
+		}
+	})
+
+	t.Run("returns not found for unknown statement", func(t *testing.T) {
+		mockService := &mockPDFService{err: model.ErrNotFound}
+		handler := NewStatementHandler(mockService, &mockJobService{}, &mockStatementService{})
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/statements/missing/reparse", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPathValues(echo.PathValues{{Name: "id", Value: "missing"}})
+
+		err := handler.ReparseStatement(c)
+
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+		}
+	})
 }
@@ -1,10 +1,17 @@
 package httphandler
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v5"
+	"github.com/tsongpon/helios/internal/model"
 )
 
 type TransactionHandler struct {
@@ -41,15 +48,268 @@ func (h *TransactionHandler) GetTransactions(c *echo.Context) error {
 		})
 	}
 
-	// Fix userID for now
-	userID := "1234567890"
+	filter, err := parseTransactionFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+	}
 
-	transactions, err := h.transactionService.GetTransactions(c.Request().Context(), userID, from, to)
+	query := model.TransactionQuery{
+		From:   from,
+		To:     to,
+		Filter: filter,
+		Cursor: c.QueryParam("cursor"),
+	}
+	if limit := c.QueryParam("limit"); limit != "" {
+		query.Limit, err = strconv.Atoi(limit)
+		if err != nil || query.Limit < 1 || query.Limit > model.MaxTransactionPageSize {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: fmt.Sprintf("limit must be between 1 and %d", model.MaxTransactionPageSize),
+			})
+		}
+	}
+
+	userID := currentUserID(c)
+
+	page, err := h.transactionService.GetTransactions(c.Request().Context(), userID, query)
 	if err != nil {
+		if errors.Is(err, model.ErrInvalidCursor) {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: "invalid cursor",
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error: "failed to get transactions: " + err.Error(),
 		})
 	}
 
-	return c.JSON(http.StatusOK, toTransactionResponses(transactions))
+	return c.JSON(http.StatusOK, toTransactionPageResponse(page))
+}
+
+func (h *TransactionHandler) CreateTransaction(c *echo.Context) error {
+	var req CreateTransactionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "invalid request body",
+		})
+	}
+
+	transaction, err := toManualTransaction(req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+	}
+
+	transaction, err = h.transactionService.CreateTransaction(c.Request().Context(), currentUserID(c), transaction)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "failed to create transaction: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, toTransactionResponse(transaction))
+}
+
+func (h *TransactionHandler) DeleteTransaction(c *echo.Context) error {
+	if err := h.transactionService.DeleteTransaction(c.Request().Context(), currentUserID(c), c.Param("id")); err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error: "transaction not found",
+			})
+		}
+		if errors.Is(err, model.ErrNotManualTransaction) {
+			return c.JSON(http.StatusConflict, ErrorResponse{
+				Error: err.Error() + "; delete the statement instead",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "failed to delete transaction: " + err.Error(),
+		})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *TransactionHandler) UpdateTransaction(c *echo.Context) error {
+	var req UpdateTransactionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "invalid request body",
+		})
+	}
+
+	patch, err := toTransactionPatch(req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: err.Error(),
+		})
+	}
+
+	transaction, err := h.transactionService.UpdateTransaction(c.Request().Context(), currentUserID(c), c.Param("id"), patch)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error: "transaction not found",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: "failed to update transaction: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, toTransactionResponse(transaction))
+}
+
+// toManualTransaction validates a create request; the transaction date,
+// description and amount are required
+func toManualTransaction(req CreateTransactionRequest) (model.Transaction, error) {
+	if req.TransactionDate == "" || strings.TrimSpace(req.Description) == "" || req.Amount == nil {
+		return model.Transaction{}, errors.New("transaction_date, description and amount are required")
+	}
+
+	update := UpdateTransactionRequest{
+		TransactionDate: &req.TransactionDate,
+		Description:     &req.Description,
+		Amount:          req.Amount,
+	}
+	if strings.TrimSpace(req.Category) != "" {
+		update.Category = &req.Category
+	}
+	patch, err := toTransactionPatch(update)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	if req.PostingDate != "" {
+		if _, err := time.Parse("2006-01-02", req.PostingDate); err != nil {
+			return model.Transaction{}, errors.New("invalid posting_date format, expected YYYY-MM-DD")
+		}
+	}
+
+	transaction := model.Transaction{
+		TransactionDate: *patch.TransactionDate,
+		PostingDate:     req.PostingDate,
+		Description:     *patch.Description,
+		Amount:          *patch.Amount,
+		CardNumber:      strings.TrimSpace(req.CardNumber),
+		Notes:           req.Notes,
+	}
+	if patch.Category != nil {
+		transaction.Category = *patch.Category
+	}
+	return transaction, nil
+}
+
+// toTransactionPatch validates the fields of an update request
+func toTransactionPatch(req UpdateTransactionRequest) (model.TransactionPatch, error) {
+	patch := model.TransactionPatch{
+		TransactionDate: req.TransactionDate,
+		PostingDate:     req.PostingDate,
+		Amount:          req.Amount,
+		Category:        req.Category,
+		Notes:           req.Notes,
+	}
+	if req.TransactionDate != nil {
+		if _, err := time.Parse("2006-01-02", *req.TransactionDate); err != nil {
+			return model.TransactionPatch{}, errors.New("invalid transaction_date format, expected YYYY-MM-DD")
+		}
+	}
+	if req.PostingDate != nil {
+		if _, err := time.Parse("2006-01-02", *req.PostingDate); err != nil {
+			return model.TransactionPatch{}, errors.New("invalid posting_date format, expected YYYY-MM-DD")
+		}
+	}
+	if req.Description != nil {
+		description := strings.Join(strings.Fields(*req.Description), " ")
+		if description == "" {
+			return model.TransactionPatch{}, errors.New("description must not be empty")
+		}
+		patch.Description = &description
+	}
+	if req.Amount != nil && (math.IsNaN(*req.Amount) || math.IsInf(*req.Amount, 0)) {
+		return model.TransactionPatch{}, errors.New("invalid amount")
+	}
+	if req.Category != nil {
+		// An empty category leaves the transaction uncategorized
+		category := strings.ToLower(strings.TrimSpace(*req.Category))
+		if category != "" && !slices.Contains(model.Categories, category) {
+			return model.TransactionPatch{}, fmt.Errorf("unknown category %q", category)
+		}
+		patch.Category = &category
+	}
+	if patch.IsEmpty() {
+		return model.TransactionPatch{}, errors.New("no fields to update")
+	}
+
+	return patch, nil
+}
+
+// parseTransactionFilter reads the optional GET /transactions filters
+func parseTransactionFilter(c *echo.Context) (model.TransactionFilter, error) {
+	filter := model.TransactionFilter{
+		CardNumber:  strings.TrimSpace(c.QueryParam("card_number")),
+		StatementID: strings.TrimSpace(c.QueryParam("statement_id")),
+		Category:    strings.TrimSpace(c.QueryParam("category")),
+		Merchant:    strings.TrimSpace(c.QueryParam("merchant")),
+		Tag:         strings.ToLower(strings.TrimSpace(c.QueryParam("tag"))),
+		Description: strings.TrimSpace(c.QueryParam("description")),
+		Type:        model.TransactionType(c.QueryParam("type")),
+		Source:      model.TransactionSource(c.QueryParam("source")),
+	}
+
+	var err error
+	if filter.MinAmount, err = parseAmountParam(c, "min_amount"); err != nil {
+		return model.TransactionFilter{}, err
+	}
+	if filter.MaxAmount, err = parseAmountParam(c, "max_amount"); err != nil {
+		return model.TransactionFilter{}, err
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil && *filter.MinAmount > *filter.MaxAmount {
+		return model.TransactionFilter{}, errors.New("min_amount must not be greater than max_amount")
+	}
+
+	switch filter.Type {
+	case "", model.TransactionTypeDebit, model.TransactionTypeCredit:
+	default:
+		return model.TransactionFilter{}, errors.New("invalid type, expected debit or credit")
+	}
+
+	switch filter.Source {
+	case "", model.TransactionSourceStatement, model.TransactionSourceManual:
+	default:
+		return model.TransactionFilter{}, errors.New("invalid source, expected statement or manual")
+	}
+
+	if value := c.QueryParam("installment"); value != "" {
+		installment, err := strconv.ParseBool(value)
+		if err != nil {
+			return model.TransactionFilter{}, errors.New("invalid installment, expected true or false")
+		}
+		filter.Installment = &installment
+	}
+
+	if value := c.QueryParam("business"); value != "" {
+		business, err := strconv.ParseBool(value)
+		if err != nil {
+			return model.TransactionFilter{}, errors.New("invalid business, expected true or false")
+		}
+		filter.Business = &business
+	}
+
+	return filter, nil
+}
+
+// parseAmountParam reads an optional amount query parameter, nil when it is
+// not set
+func parseAmountParam(c *echo.Context, name string) (*float64, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return nil, nil
+	}
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return nil, fmt.Errorf("invalid %s, expected a number", name)
+	}
+	return &amount, nil
 }
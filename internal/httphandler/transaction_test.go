@@ -6,8 +6,8 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
-	"time"
 
 	"github.com/labstack/echo/v5"
 	"github.com/tsongpon/helios/internal/model"
@@ -15,14 +15,49 @@ import (
 
 type mockTransactionService struct {
 	transactions []model.Transaction
+	nextCursor   string
 	err          error
+	userID       string
+	query        model.TransactionQuery
+	patch        model.TransactionPatch
+	created      model.Transaction
+	deletedID    string
 }
 
-func (m *mockTransactionService) GetTransactions(ctx context.Context, userID string, from, to time.Time) ([]model.Transaction, error) {
+func (m *mockTransactionService) CreateTransaction(ctx context.Context, userID string, transaction model.Transaction) (model.Transaction, error) {
+	m.created = transaction
 	if m.err != nil {
-		return nil, m.err
+		return model.Transaction{}, m.err
 	}
-	return m.transactions, nil
+	transaction.ID = "txn-manual"
+	transaction.UserID = userID
+	transaction.Source = model.TransactionSourceManual
+	return transaction, nil
+}
+
+func (m *mockTransactionService) DeleteTransaction(ctx context.Context, userID, transactionID string) error {
+	m.deletedID = transactionID
+	return m.err
+}
+
+func (m *mockTransactionService) GetTransactions(ctx context.Context, userID string, query model.TransactionQuery) (model.TransactionPage, error) {
+	m.userID = userID
+	m.query = query
+	if m.err != nil {
+		return model.TransactionPage{}, m.err
+	}
+	return model.TransactionPage{Transactions: m.transactions, NextCursor: m.nextCursor}, nil
+}
+
+func (m *mockTransactionService) UpdateTransaction(ctx context.Context, userID, transactionID string, patch model.TransactionPatch) (model.Transaction, error) {
+	m.userID = userID
+	m.patch = patch
+	if m.err != nil {
+		return model.Transaction{}, m.err
+	}
+	transaction := model.Transaction{ID: transactionID, Description: "TOPS", Amount: 12}
+	transaction.ApplyOverrides(patch)
+	return transaction, nil
 }
 
 func TestTransactionHandler_GetTransactions(t *testing.T) {
@@ -47,6 +82,7 @@ func TestTransactionHandler_GetTransactions(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, "/transactions?start=2024-12-01&end=2024-12-31", nil)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
+		c.Set(principalContextKey, model.Principal{UserID: "1234567890"})
 
 		err := handler.GetTransactions(c)
 
@@ -58,17 +94,21 @@ func TestTransactionHandler_GetTransactions(t *testing.T) {
 			t.Errorf("expected status %d, got %d", http.StatusOK, rec.Code)
 		}
 
-		var response []TransactionResponse
+		if mockService.userID != "1234567890" {
+			t.Errorf("expected user 1234567890, got %q", mockService.userID)
+		}
+
+		var response TransactionPageResponse
 		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
 			t.Fatalf("failed to unmarshal response: %v", err)
 		}
 
-		if len(response) != 1 {
-			t.Fatalf("expected 1 transaction, got %d", len(response))
+		if len(response.Transactions) != 1 {
+			t.Fatalf("expected 1 transaction, got %d", len(response.Transactions))
 		}
 
-		if response[0].Description != "AMAZON" {
-			t.Errorf("expected description AMAZON, got %s", response[0].Description)
+		if response.Transactions[0].Description != "AMAZON" {
+			t.Errorf("expected description AMAZON, got %s", response.Transactions[0].Description)
 		}
 	})
 
@@ -231,13 +271,325 @@ func TestTransactionHandler_GetTransactions(t *testing.T) {
 			t.Errorf("expected status %d, got %d", http.StatusOK, rec.Code)
 		}
 
-		var response []TransactionResponse
+		var response TransactionPageResponse
 		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
 			t.Fatalf("failed to unmarshal response: %v", err)
 		}
 
-		if len(response) != 0 {
-			t.Errorf("expected 0 transactions, got %d", len(response))
+		if len(response.Transactions) != 0 {
+			t.Errorf("expected 0 transactions, got %d", len(response.Transactions))
+		}
+		if response.NextCursor != "" {
+			t.Errorf("expected no next cursor, got %q", response.NextCursor)
+		}
+	})
+
+	t.Run("passes limit and cursor and returns the next cursor", func(t *testing.T) {
+		mockService := &mockTransactionService{
+			transactions: []model.Transaction{{ID: "txn-1"}},
+			nextCursor:   "next-page",
+		}
+		handler := NewTransactionHandler(mockService)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/transactions?start=2024-12-01&end=2024-12-31&limit=1&cursor=this-page", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if err := handler.GetTransactions(c); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if mockService.query.Limit != 1 || mockService.query.Cursor != "this-page" {
+			t.Errorf("unexpected query %+v", mockService.query)
+		}
+
+		var response TransactionPageResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if response.NextCursor != "next-page" {
+			t.Errorf("expected next cursor next-page, got %q", response.NextCursor)
+		}
+	})
+
+	t.Run("returns error for invalid limit", func(t *testing.T) {
+		for _, limit := range []string{"0", "-1", "abc", "501"} {
+			handler := NewTransactionHandler(&mockTransactionService{})
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/transactions?start=2024-12-01&end=2024-12-31&limit="+limit, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			if err := handler.GetTransactions(c); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("limit %s: expected status %d, got %d", limit, http.StatusBadRequest, rec.Code)
+			}
+		}
+	})
+
+	t.Run("returns error for invalid cursor", func(t *testing.T) {
+		handler := NewTransactionHandler(&mockTransactionService{err: model.ErrInvalidCursor})
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/transactions?start=2024-12-01&end=2024-12-31&cursor=garbage", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if err := handler.GetTransactions(c); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("passes filters to the service", func(t *testing.T) {
+		mockService := &mockTransactionService{}
+		handler := NewTransactionHandler(mockService)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/transactions?start=2024-12-01&end=2024-12-31&card_number=1234-XXXX-XXXX-5678&statement_id=stmt-1&category=dining&min_amount=10&max_amount=99.5&type=debit&installment=true&description=amazon&merchant=Lazada&tag=Work&business=false", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if err := handler.GetTransactions(c); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+		}
+
+		f := mockService.query.Filter
+		if f.CardNumber != "1234-XXXX-XXXX-5678" || f.StatementID != "stmt-1" || f.Category != "dining" || f.Description != "amazon" || f.Merchant != "Lazada" {
+			t.Errorf("unexpected filter %+v", f)
+		}
+		if f.MinAmount == nil || *f.MinAmount != 10 || f.MaxAmount == nil || *f.MaxAmount != 99.5 {
+			t.Errorf("unexpected amount range %v-%v", f.MinAmount, f.MaxAmount)
+		}
+		if f.Type != model.TransactionTypeDebit || f.Installment == nil || !*f.Installment {
+			t.Errorf("unexpected type or installment filter %+v", f)
+		}
+		if f.Tag != "work" || f.Business == nil || *f.Business {
+			t.Errorf("unexpected tag or business filter %+v", f)
 		}
 	})
+
+	t.Run("returns error for invalid filters", func(t *testing.T) {
+		for _, params := range []string{"min_amount=abc", "max_amount=NaN", "min_amount=10&max_amount=5", "type=refund", "installment=maybe", "business=yes"} {
+			mockService := &mockTransactionService{}
+			handler := NewTransactionHandler(mockService)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/transactions?start=2024-12-01&end=2024-12-31&"+params, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			if err := handler.GetTransactions(c); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status %d, got %d", params, http.StatusBadRequest, rec.Code)
+			}
+		}
+	})
+
+	t.Run("reports min_amount first when both amounts are invalid", func(t *testing.T) {
+		handler := NewTransactionHandler(&mockTransactionService{})
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/transactions?start=2024-12-01&end=2024-12-31&min_amount=abc&max_amount=xyz", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if err := handler.GetTransactions(c); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		var response ErrorResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if response.Error != "invalid min_amount, expected a number" {
+			t.Errorf("unexpected error message: %s", response.Error)
+		}
+	})
+}
+
+func TestTransactionHandler_UpdateTransaction(t *testing.T) {
+	newContext := func(body string) (*echo.Context, *httptest.ResponseRecorder) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPatch, "/transactions/txn-1", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPathValues(echo.PathValues{{Name: "id", Value: "txn-1"}})
+		c.Set(principalContextKey, model.Principal{UserID: "user123"})
+		return c, rec
+	}
+
+	t.Run("returns the edited transaction with original values", func(t *testing.T) {
+		mockService := &mockTransactionService{}
+		handler := NewTransactionHandler(mockService)
+		c, rec := newContext(`{"amount": 120, "description": "  Tops   Supermarket ", "notes": "weekly shop"}`)
+
+		if err := handler.UpdateTransaction(c); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+		if mockService.userID != "user123" {
+			t.Errorf("expected user123, got %q", mockService.userID)
+		}
+
+		var response TransactionResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if response.Amount != 120 || response.Description != "Tops Supermarket" || response.Notes != "weekly shop" {
+			t.Errorf("expected edits in response, got %+v", response)
+		}
+		if response.Original == nil || response.Original.Amount == nil || *response.Original.Amount != 12 {
+			t.Errorf("expected original amount 12, got %+v", response.Original)
+		}
+	})
+
+	t.Run("returns error for invalid edits", func(t *testing.T) {
+		for _, body := range []string{`{}`, `{"transaction_date": "15/12/2024"}`, `{"description": "  "}`, `{"category": "dining"}`, `not json`} {
+			handler := NewTransactionHandler(&mockTransactionService{})
+			c, rec := newContext(body)
+
+			if err := handler.UpdateTransaction(c); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status %d, got %d", body, http.StatusBadRequest, rec.Code)
+			}
+		}
+	})
+
+	t.Run("reports the transaction date first when both dates are invalid", func(t *testing.T) {
+		handler := NewTransactionHandler(&mockTransactionService{})
+		c, rec := newContext(`{"transaction_date": "15/12/2024", "posting_date": "16/12/2024"}`)
+
+		if err := handler.UpdateTransaction(c); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		var response ErrorResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if response.Error != "invalid transaction_date format, expected YYYY-MM-DD" {
+			t.Errorf("unexpected error message: %s", response.Error)
+		}
+	})
+
+	t.Run("returns not found for unknown transactions", func(t *testing.T) {
+		handler := NewTransactionHandler(&mockTransactionService{err: model.ErrNotFound})
+		c, rec := newContext(`{"notes": "hi"}`)
+
+		if err := handler.UpdateTransaction(c); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+		}
+	})
+}
+
+func TestTransactionHandler_CreateTransaction(t *testing.T) {
+	newContext := func(body string) (*echo.Context, *httptest.ResponseRecorder) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/transactions", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(principalContextKey, model.Principal{UserID: "user123"})
+		return c, rec
+	}
+
+	t.Run("creates a manual transaction", func(t *testing.T) {
+		mockService := &mockTransactionService{}
+		handler := NewTransactionHandler(mockService)
+		c, rec := newContext(`{"transaction_date": "2024-12-20", "description": "Street food", "amount": 80, "category": " Food "}`)
+
+		if err := handler.CreateTransaction(c); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+		}
+		if mockService.created.Description != "Street food" || mockService.created.Amount != 80 || mockService.created.Category != "food" {
+			t.Errorf("unexpected transaction passed to service: %+v", mockService.created)
+		}
+
+		var response TransactionResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if response.Source != "manual" || response.ID != "txn-manual" {
+			t.Errorf("expected manual transaction in response, got %+v", response)
+		}
+	})
+
+	t.Run("returns error for invalid transactions", func(t *testing.T) {
+		for _, body := range []string{
+			`{"description": "Street food", "amount": 80}`,
+			`{"transaction_date": "2024-12-20", "amount": 80}`,
+			`{"transaction_date": "2024-12-20", "description": "Street food"}`,
+			`{"transaction_date": "20/12/2024", "description": "Street food", "amount": 80}`,
+			`{"transaction_date": "2024-12-20", "posting_date": "tomorrow", "description": "Street food", "amount": 80}`,
+			`{"transaction_date": "2024-12-20", "description": "Street food", "amount": 80, "category": "dining"}`,
+		} {
+			handler := NewTransactionHandler(&mockTransactionService{})
+			c, rec := newContext(body)
+
+			if err := handler.CreateTransaction(c); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status %d, got %d", body, http.StatusBadRequest, rec.Code)
+			}
+		}
+	})
+}
+
+func TestTransactionHandler_DeleteTransaction(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"deletes manual transactions", nil, http.StatusNoContent},
+		{"refuses parsed transactions", model.ErrNotManualTransaction, http.StatusConflict},
+		{"returns not found for unknown transactions", model.ErrNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &mockTransactionService{err: tt.err}
+			handler := NewTransactionHandler(mockService)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodDelete, "/transactions/txn-1", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPathValues(echo.PathValues{{Name: "id", Value: "txn-1"}})
+
+			if err := handler.DeleteTransaction(c); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if rec.Code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, rec.Code)
+			}
+			if mockService.deletedID != "txn-1" {
+				t.Errorf("expected txn-1, got %q", mockService.deletedID)
+			}
+		})
+	}
 }
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// CategoryOther is assigned to transactions no other category fits
+const CategoryOther = "other"
+
+// Categories is the taxonomy transactions are classified into
+var Categories = []string{
+	"food",
+	"groceries",
+	"travel",
+	"transport",
+	"shopping",
+	"utilities",
+	"entertainment",
+	"health",
+	"education",
+	"insurance",
+	"fees",
+	"payments",
+	CategoryOther,
+}
+
+// CategoryRule assigns Category to transactions whose description contains
+// Pattern, or matches it as a regular expression when IsRegex is set.
+// Matching is case-insensitive.
+type CategoryRule struct {
+	ID        string
+	UserID    string
+	Pattern   string
+	IsRegex   bool
+	Category  string
+	CreatedAt time.Time
+}
+
+// Matcher compiles the rule into a description matcher
+func (r CategoryRule) Matcher() (func(description string) bool, error) {
+	if !r.IsRegex {
+		pattern := strings.ToLower(r.Pattern)
+		return func(description string) bool {
+			return strings.Contains(strings.ToLower(description), pattern)
+		}, nil
+	}
+
+	re, err := regexp.Compile("(?i)" + r.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	return re.MatchString, nil
+}
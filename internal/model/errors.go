@@ -0,0 +1,47 @@
+package model
+
+import "errors"
+
+// ErrNotFound is returned by repositories when the requested entity does not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrUnauthorized is returned when a request's credentials are missing,
+// malformed, expired or otherwise invalid
+var ErrUnauthorized = errors.New("unauthorized")
+
+// ErrAlreadyExists is returned by repositories when creating an entity whose
+// ID is already taken
+var ErrAlreadyExists = errors.New("already exists")
+
+// DuplicateStatementError is returned when the uploaded PDF has already been
+// processed for the user; StatementID points at the existing statement
+type DuplicateStatementError struct {
+	StatementID string
+}
+
+func (e *DuplicateStatementError) Error() string {
+	return "statement has already been uploaded as " + e.StatementID
+}
+
+// ErrJobQueueFull is returned when a statement cannot be queued because
+// every worker is busy and the queue has reached its capacity
+var ErrJobQueueFull = errors.New("job queue is full")
+
+// ErrInvalidCursor is returned when a pagination cursor is malformed
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrInvalidScope is returned when an API key is requested with no scopes or
+// a scope that does not exist
+var ErrInvalidScope = errors.New("invalid scope")
+
+// ErrNotManualTransaction is returned when deleting a transaction that was
+// parsed from a statement; those are removed with their statement
+var ErrNotManualTransaction = errors.New("only manual transactions can be deleted")
+
+// ErrInvalidCategoryRule is returned when a rule has no pattern, an invalid
+// regular expression or a category outside the taxonomy
+var ErrInvalidCategoryRule = errors.New("invalid category rule")
+
+// ErrInvalidRule is returned when a rule's condition does not parse or its
+// actions are empty or invalid
+var ErrInvalidRule = errors.New("invalid rule")
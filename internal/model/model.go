@@ -1,13 +1,218 @@
 package model
 
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
 type Transaction struct {
 	ID              string
 	UserID          string
+	StatementID     string
 	CardNumber      string
 	TransactionDate string
 	PostingDate     string
 	Description     string
+	// Merchant is the canonical merchant name derived from Description
+	Merchant        string
 	Amount          float64
 	IsInstallment   bool
 	InstallmentTerm string
+	Category        string
+	Notes           string
+	Source          TransactionSource
+	// Tags and IsBusiness are set by the user's rules
+	Tags       []string
+	IsBusiness bool
+	// Overrides are the user's edits, already applied to the fields above,
+	// and Original holds the parsed values they replaced
+	Overrides TransactionPatch
+	Original  TransactionPatch
+}
+
+// TransactionSource tells where a transaction came from
+type TransactionSource string
+
+const (
+	// TransactionSourceStatement is a transaction parsed from an uploaded statement
+	TransactionSourceStatement TransactionSource = "statement"
+	// TransactionSourceManual is a transaction entered by the user, e.g. a cash payment
+	TransactionSourceManual TransactionSource = "manual"
+)
+
+// TransactionPatch holds values for the user-editable fields of a
+// transaction; nil fields are not set
+type TransactionPatch struct {
+	TransactionDate *string
+	PostingDate     *string
+	Description     *string
+	Amount          *float64
+	Category        *string
+	Notes           *string
+}
+
+// IsEmpty reports whether no field is set
+func (p TransactionPatch) IsEmpty() bool {
+	return p == TransactionPatch{}
+}
+
+// ApplyOverrides sets the fields in patch as user overrides, remembering the
+// value each one replaces in Original. Setting a field back to its original
+// value removes the override.
+func (t *Transaction) ApplyOverrides(patch TransactionPatch) {
+	applyOverride(&t.TransactionDate, patch.TransactionDate, &t.Overrides.TransactionDate, &t.Original.TransactionDate)
+	applyOverride(&t.PostingDate, patch.PostingDate, &t.Overrides.PostingDate, &t.Original.PostingDate)
+	applyOverride(&t.Description, patch.Description, &t.Overrides.Description, &t.Original.Description)
+	applyOverride(&t.Amount, patch.Amount, &t.Overrides.Amount, &t.Original.Amount)
+	applyOverride(&t.Category, patch.Category, &t.Overrides.Category, &t.Original.Category)
+	applyOverride(&t.Notes, patch.Notes, &t.Overrides.Notes, &t.Original.Notes)
+}
+
+func applyOverride[T comparable](field *T, value *T, override, original **T) {
+	if value == nil {
+		return
+	}
+	if *original == nil {
+		current := *field
+		*original = &current
+	}
+	*field = *value
+	if *value == **original {
+		*override, *original = nil, nil
+		return
+	}
+	v := *value
+	*override = &v
+}
+
+const (
+	// DefaultTransactionPageSize is used when a query has no limit
+	DefaultTransactionPageSize = 100
+	// MaxTransactionPageSize caps the number of transactions in one page
+	MaxTransactionPageSize = 500
+)
+
+// TransactionQuery selects one page of a user's transactions
+type TransactionQuery struct {
+	From   time.Time
+	To     time.Time
+	Filter TransactionFilter
+	Limit  int
+	Cursor string
+}
+
+type TransactionType string
+
+const (
+	// TransactionTypeDebit is a purchase or fee, with a positive amount
+	TransactionTypeDebit TransactionType = "debit"
+	// TransactionTypeCredit is a payment, refund or cashback, with a negative amount
+	TransactionTypeCredit TransactionType = "credit"
+)
+
+// TransactionFilter narrows a TransactionQuery; zero fields match everything
+type TransactionFilter struct {
+	CardNumber  string
+	StatementID string
+	Category    string
+	Merchant    string
+	Tag         string
+	Business    *bool
+	MinAmount   *float64
+	MaxAmount   *float64
+	Type        TransactionType
+	Source      TransactionSource
+	Installment *bool
+	// Description matches a case-insensitive substring of the description
+	Description string
+}
+
+// Matches reports whether t passes every filter
+func (f TransactionFilter) Matches(t Transaction) bool {
+	switch {
+	case f.CardNumber != "" && t.CardNumber != f.CardNumber,
+		f.StatementID != "" && t.StatementID != f.StatementID,
+		f.Category != "" && t.Category != f.Category,
+		f.Merchant != "" && t.Merchant != f.Merchant,
+		f.Tag != "" && !slices.Contains(t.Tags, f.Tag),
+		f.Business != nil && t.IsBusiness != *f.Business,
+		f.MinAmount != nil && t.Amount < *f.MinAmount,
+		f.MaxAmount != nil && t.Amount > *f.MaxAmount,
+		f.Type == TransactionTypeDebit && t.Amount <= 0,
+		f.Type == TransactionTypeCredit && t.Amount >= 0,
+		f.Source != "" && t.Source != f.Source,
+		f.Installment != nil && t.IsInstallment != *f.Installment,
+		f.Description != "" && !strings.Contains(strings.ToLower(t.Description), strings.ToLower(f.Description)):
+		return false
+	}
+	return true
+}
+
+// TransactionPage is a page of transactions ordered by transaction date then
+// ID; NextCursor is empty on the last page
+type TransactionPage struct {
+	Transactions []Transaction
+	NextCursor   string
+}
+
+type Statement struct {
+	ID                   string
+	UserID               string
+	Bank                 string
+	CardNumber           string
+	StatementDate        string
+	PeriodStart          string
+	PeriodEnd            string
+	PaymentDueDate       string
+	PreviousBalance      float64
+	TotalPayment         float64
+	MinimumPayment       float64
+	CreditLine           float64
+	FileHash             string
+	SourceText           string
+	PromptVersion        string
+	Model                string
+	Transactions         []Transaction
+	CreatedAt            time.Time
+	ReconciliationStatus ReconciliationStatus
+	ReconciliationDelta  float64
+}
+
+type ReconciliationStatus string
+
+const (
+	ReconciliationMatched     ReconciliationStatus = "matched"
+	ReconciliationMismatched  ReconciliationStatus = "mismatched"
+	ReconciliationUnavailable ReconciliationStatus = "unavailable"
+)
+
+type JobStatus string
+
+const (
+	JobStatusQueued     JobStatus = "queued"
+	JobStatusExtracting JobStatus = "extracting"
+	JobStatusParsing    JobStatus = "parsing"
+	JobStatusSaving     JobStatus = "saving"
+	JobStatusDone       JobStatus = "done"
+	JobStatusFailed     JobStatus = "failed"
+)
+
+type Job struct {
+	ID           string
+	UserID       string
+	Status       JobStatus
+	StatementID  string
+	Transactions []Transaction
+	Error        string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// JobUpload is the uploaded PDF of a job, kept until the job finishes so it
+// can be processed again after a restart. The PDF's password is never stored;
+// PasswordProtected records that one was given.
+type JobUpload struct {
+	Content           []byte
+	PasswordProtected bool
 }
@@ -0,0 +1,75 @@
+package model
+
+import "testing"
+
+func TestTransactionFilter_Matches(t *testing.T) {
+	amount := func(v float64) *float64 { return &v }
+	yes := true
+
+	purchase := Transaction{CardNumber: "1234-XXXX-XXXX-5678", StatementID: "stmt-1", Description: "AMAZON Marketplace", Amount: 100.50, IsInstallment: true, Category: "shopping", Merchant: "Amazon", Tags: []string{"online"}, IsBusiness: true}
+	refund := Transaction{CardNumber: "9999-XXXX-XXXX-0000", StatementID: "stmt-2", Description: "REFUND", Amount: -20}
+
+	tests := []struct {
+		name   string
+		filter TransactionFilter
+		want   [2]bool
+	}{
+		{"empty", TransactionFilter{}, [2]bool{true, true}},
+		{"card number", TransactionFilter{CardNumber: "1234-XXXX-XXXX-5678"}, [2]bool{true, false}},
+		{"statement", TransactionFilter{StatementID: "stmt-2"}, [2]bool{false, true}},
+		{"category", TransactionFilter{Category: "shopping"}, [2]bool{true, false}},
+		{"merchant", TransactionFilter{Merchant: "Amazon"}, [2]bool{true, false}},
+		{"min amount", TransactionFilter{MinAmount: amount(0)}, [2]bool{true, false}},
+		{"max amount", TransactionFilter{MaxAmount: amount(100)}, [2]bool{false, true}},
+		{"debit", TransactionFilter{Type: TransactionTypeDebit}, [2]bool{true, false}},
+		{"credit", TransactionFilter{Type: TransactionTypeCredit}, [2]bool{false, true}},
+		{"installment", TransactionFilter{Installment: &yes}, [2]bool{true, false}},
+		{"description", TransactionFilter{Description: "marketplace"}, [2]bool{true, false}},
+		{"tag", TransactionFilter{Tag: "online"}, [2]bool{true, false}},
+		{"business", TransactionFilter{Business: &yes}, [2]bool{true, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := [2]bool{tt.filter.Matches(purchase), tt.filter.Matches(refund)}
+			if got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTransaction_ApplyOverrides(t *testing.T) {
+	amount := func(v float64) *float64 { return &v }
+	transaction := Transaction{Description: "TOPS", Amount: 12}
+
+	transaction.ApplyOverrides(TransactionPatch{Amount: amount(120)})
+	transaction.ApplyOverrides(TransactionPatch{Amount: amount(125)})
+	if transaction.Amount != 125 || *transaction.Overrides.Amount != 125 {
+		t.Errorf("expected amount 125 to be overridden, got %+v", transaction)
+	}
+	if *transaction.Original.Amount != 12 {
+		t.Errorf("expected original amount 12 to be kept, got %v", *transaction.Original.Amount)
+	}
+
+	transaction.ApplyOverrides(TransactionPatch{Amount: amount(12)})
+	if transaction.Amount != 12 || !transaction.Overrides.IsEmpty() || !transaction.Original.IsEmpty() {
+		t.Errorf("expected reverting to the parsed amount to remove the override, got %+v", transaction)
+	}
+}
+
+func TestCategoryRule_Matcher(t *testing.T) {
+	keyword, err := CategoryRule{Pattern: "grab"}.Matcher()
+	if err != nil || !keyword("GRABFOOD BANGKOK") || keyword("LAZADA") {
+		t.Errorf("expected case-insensitive keyword match, err %v", err)
+	}
+
+	regex, err := CategoryRule{Pattern: `^bts\b`, IsRegex: true}.Matcher()
+	if err != nil || !regex("BTS RABBIT") || regex("SUBWAY BTS") {
+		t.Errorf("expected case-insensitive regex match, err %v", err)
+	}
+
+	if _, err := (CategoryRule{Pattern: "(", IsRegex: true}).Matcher(); err == nil {
+		t.Errorf("expected invalid regex error")
+	}
+}
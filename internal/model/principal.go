@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	UserID string
+	// APIKeyID is set when the caller authenticated with an API key, which
+	// may only do what its Scopes allow; user tokens are not restricted
+	APIKeyID string
+	Scopes   []Scope
+}
+
+// HasScope reports whether the caller may perform actions covered by scope
+func (p Principal) HasScope(scope Scope) bool {
+	if p.APIKeyID == "" {
+		return true
+	}
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Scope is a permission that can be granted to an API key
+type Scope string
+
+const (
+	ScopeStatementsRead    Scope = "statements:read"
+	ScopeStatementsWrite   Scope = "statements:write"
+	ScopeTransactionsRead  Scope = "transactions:read"
+	ScopeTransactionsWrite Scope = "transactions:write"
+)
+
+// Scopes lists every scope an API key can be granted
+var Scopes = []Scope{
+	ScopeStatementsRead,
+	ScopeStatementsWrite,
+	ScopeTransactionsRead,
+	ScopeTransactionsWrite,
+}
+
+// APIKeyPrefix starts every issued API key, which tells them apart from JWTs
+const APIKeyPrefix = "hk_"
+
+// APIKey lets a machine client act on behalf of UserID. Only the SHA-256
+// hash of the key is stored; the key itself is shown once when it is issued.
+type APIKey struct {
+	ID         string
+	UserID     string
+	Name       string
+	Prefix     string
+	Hash       string
+	Scopes     []Scope
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+	RevokedAt  time.Time
+}
+
+// Revoked reports whether the key has been revoked
+func (k APIKey) Revoked() bool {
+	return !k.RevokedAt.IsZero()
+}
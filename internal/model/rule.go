@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// Rule applies Actions to a user's transactions that match Condition, an
+// expression such as `merchant == "Grab" and amount > 500`
+type Rule struct {
+	ID        string
+	UserID    string
+	Name      string
+	Condition string
+	Actions   RuleActions
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// RuleActions are the changes a rule makes to a matching transaction
+type RuleActions struct {
+	SetCategory  string
+	AddTags      []string
+	MarkBusiness bool
+}
+
+func (a RuleActions) IsEmpty() bool {
+	return a.SetCategory == "" && len(a.AddTags) == 0 && !a.MarkBusiness
+}
+
+// RuleChange is a transaction before and after a rule's actions are applied
+type RuleChange struct {
+	Before Transaction
+	After  Transaction
+}
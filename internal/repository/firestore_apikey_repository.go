@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/tsongpon/helios/internal/model"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type FirestoreAPIKeyRepository struct {
+	client *firestore.Client
+}
+
+func NewFirestoreAPIKeyRepository(client *firestore.Client) *FirestoreAPIKeyRepository {
+	return &FirestoreAPIKeyRepository{
+		client: client,
+	}
+}
+
+func (r *FirestoreAPIKeyRepository) Save(ctx context.Context, key model.APIKey) error {
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	doc := map[string]any{
+		"user_id":      key.UserID,
+		"name":         key.Name,
+		"prefix":       key.Prefix,
+		"hash":         key.Hash,
+		"scopes":       scopes,
+		"created_at":   key.CreatedAt,
+		"last_used_at": key.LastUsedAt,
+		"revoked_at":   key.RevokedAt,
+	}
+
+	if _, err := r.client.Collection("api_keys").Doc(key.ID).Set(ctx, doc); err != nil {
+		return fmt.Errorf("failed to save API key: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateLastUsedAt writes only the key's last use, leaving a concurrent
+// revocation in place
+func (r *FirestoreAPIKeyRepository) UpdateLastUsedAt(ctx context.Context, keyID string, usedAt time.Time) error {
+	return r.update(ctx, keyID, "last_used_at", usedAt)
+}
+
+func (r *FirestoreAPIKeyRepository) Revoke(ctx context.Context, keyID string, revokedAt time.Time) error {
+	return r.update(ctx, keyID, "revoked_at", revokedAt)
+}
+
+func (r *FirestoreAPIKeyRepository) update(ctx context.Context, keyID, field string, value any) error {
+	_, err := r.client.Collection("api_keys").Doc(keyID).
+		Update(ctx, []firestore.Update{{Path: field, Value: value}}, firestore.Exists)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return model.ErrNotFound
+		}
+		return fmt.Errorf("failed to update API key: %w", err)
+	}
+	return nil
+}
+
+func (r *FirestoreAPIKeyRepository) GetAPIKey(ctx context.Context, keyID string) (model.APIKey, error) {
+	doc, err := r.client.Collection("api_keys").Doc(keyID).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return model.APIKey{}, model.ErrNotFound
+		}
+		return model.APIKey{}, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	return apiKeyFromDoc(doc.Ref.ID, doc.Data()), nil
+}
+
+func (r *FirestoreAPIKeyRepository) GetAPIKeyByHash(ctx context.Context, hash string) (model.APIKey, error) {
+	docs, err := r.client.Collection("api_keys").
+		Where("hash", "==", hash).
+		Limit(1).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		return model.APIKey{}, fmt.Errorf("failed to get API key: %w", err)
+	}
+	if len(docs) == 0 {
+		return model.APIKey{}, model.ErrNotFound
+	}
+
+	return apiKeyFromDoc(docs[0].Ref.ID, docs[0].Data()), nil
+}
+
+func (r *FirestoreAPIKeyRepository) GetAPIKeys(ctx context.Context, userID string) ([]model.APIKey, error) {
+	docs, err := r.client.Collection("api_keys").
+		Where("user_id", "==", userID).
+		OrderBy("created_at", firestore.Desc).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+
+	keys := make([]model.APIKey, 0, len(docs))
+	for _, doc := range docs {
+		keys = append(keys, apiKeyFromDoc(doc.Ref.ID, doc.Data()))
+	}
+
+	return keys, nil
+}
+
+func apiKeyFromDoc(id string, data map[string]any) model.APIKey {
+	key := model.APIKey{
+		ID:         id,
+		UserID:     stringVal(data, "user_id"),
+		Name:       stringVal(data, "name"),
+		Prefix:     stringVal(data, "prefix"),
+		Hash:       stringVal(data, "hash"),
+		CreatedAt:  timeVal(data, "created_at"),
+		LastUsedAt: timeVal(data, "last_used_at"),
+		RevokedAt:  timeVal(data, "revoked_at"),
+	}
+	if items, ok := data["scopes"].([]any); ok {
+		for _, item := range items {
+			if scope, ok := item.(string); ok {
+				key.Scopes = append(key.Scopes, model.Scope(scope))
+			}
+		}
+	}
+	return key
+}
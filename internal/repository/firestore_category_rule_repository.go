@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"github.com/tsongpon/helios/internal/model"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type FirestoreCategoryRuleRepository struct {
+	client *firestore.Client
+}
+
+func NewFirestoreCategoryRuleRepository(client *firestore.Client) *FirestoreCategoryRuleRepository {
+	return &FirestoreCategoryRuleRepository{
+		client: client,
+	}
+}
+
+func (r *FirestoreCategoryRuleRepository) Save(ctx context.Context, rule model.CategoryRule) error {
+	doc := map[string]any{
+		"user_id":    rule.UserID,
+		"pattern":    rule.Pattern,
+		"is_regex":   rule.IsRegex,
+		"category":   rule.Category,
+		"created_at": rule.CreatedAt,
+	}
+
+	if _, err := r.client.Collection("category_rules").Doc(rule.ID).Set(ctx, doc); err != nil {
+		return fmt.Errorf("failed to save category rule: %w", err)
+	}
+
+	return nil
+}
+
+func (r *FirestoreCategoryRuleRepository) GetCategoryRule(ctx context.Context, ruleID string) (model.CategoryRule, error) {
+	doc, err := r.client.Collection("category_rules").Doc(ruleID).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return model.CategoryRule{}, model.ErrNotFound
+		}
+		return model.CategoryRule{}, fmt.Errorf("failed to get category rule: %w", err)
+	}
+
+	return categoryRuleFromDoc(doc.Ref.ID, doc.Data()), nil
+}
+
+// GetCategoryRules returns the user's rules oldest first, the order in which
+// they are evaluated
+func (r *FirestoreCategoryRuleRepository) GetCategoryRules(ctx context.Context, userID string) ([]model.CategoryRule, error) {
+	docs, err := r.client.Collection("category_rules").
+		Where("user_id", "==", userID).
+		OrderBy("created_at", firestore.Asc).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category rules: %w", err)
+	}
+
+	rules := make([]model.CategoryRule, 0, len(docs))
+	for _, doc := range docs {
+		rules = append(rules, categoryRuleFromDoc(doc.Ref.ID, doc.Data()))
+	}
+
+	return rules, nil
+}
+
+func (r *FirestoreCategoryRuleRepository) Delete(ctx context.Context, ruleID string) error {
+	if _, err := r.client.Collection("category_rules").Doc(ruleID).Delete(ctx); err != nil {
+		return fmt.Errorf("failed to delete category rule: %w", err)
+	}
+	return nil
+}
+
+func categoryRuleFromDoc(id string, data map[string]any) model.CategoryRule {
+	return model.CategoryRule{
+		ID:        id,
+		UserID:    stringVal(data, "user_id"),
+		Pattern:   stringVal(data, "pattern"),
+		IsRegex:   boolVal(data, "is_regex"),
+		Category:  stringVal(data, "category"),
+		CreatedAt: timeVal(data, "created_at"),
+	}
+}
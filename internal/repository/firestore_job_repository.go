@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"cloud.google.com/go/firestore"
+	"github.com/tsongpon/helios/internal/model"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// uploadChunkSize keeps each chunk of an upload below the 1 MiB Firestore
+// document limit
+const uploadChunkSize = 900 * 1024
+
+type FirestoreJobRepository struct {
+	client *firestore.Client
+}
+
+func NewFirestoreJobRepository(client *firestore.Client) *FirestoreJobRepository {
+	return &FirestoreJobRepository{
+		client: client,
+	}
+}
+
+func (r *FirestoreJobRepository) Save(ctx context.Context, job model.Job) error {
+	transactions := make([]map[string]any, len(job.Transactions))
+	for i, t := range job.Transactions {
+		transactions[i] = transactionToDoc(t)
+		transactions[i]["id"] = t.ID
+	}
+
+	doc := map[string]any{
+		"user_id":      job.UserID,
+		"status":       string(job.Status),
+		"statement_id": job.StatementID,
+		"transactions": transactions,
+		"error":        job.Error,
+		"created_at":   job.CreatedAt,
+		"updated_at":   job.UpdatedAt,
+	}
+
+	if _, err := r.client.Collection("jobs").Doc(job.ID).Set(ctx, doc); err != nil {
+		return fmt.Errorf("failed to save job: %w", err)
+	}
+
+	return nil
+}
+
+func (r *FirestoreJobRepository) GetJob(ctx context.Context, jobID string) (model.Job, error) {
+	doc, err := r.client.Collection("jobs").Doc(jobID).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return model.Job{}, model.ErrNotFound
+		}
+		return model.Job{}, fmt.Errorf("failed to get job: %w", err)
+	}
+
+	return jobFromDoc(doc.Ref.ID, doc.Data()), nil
+}
+
+// GetUnfinishedJobs returns the jobs of all users that are queued or being
+// processed
+func (r *FirestoreJobRepository) GetUnfinishedJobs(ctx context.Context) ([]model.Job, error) {
+	statuses := []string{
+		string(model.JobStatusQueued),
+		string(model.JobStatusExtracting),
+		string(model.JobStatusParsing),
+		string(model.JobStatusSaving),
+	}
+	docs, err := r.client.Collection("jobs").Where("status", "in", statuses).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unfinished jobs: %w", err)
+	}
+
+	jobs := make([]model.Job, len(docs))
+	for i, doc := range docs {
+		jobs[i] = jobFromDoc(doc.Ref.ID, doc.Data())
+	}
+	return jobs, nil
+}
+
+// SaveUpload stores the PDF in chunks below the Firestore document size
+// limit. The upload document is written last, so it only exists once every
+// chunk does.
+func (r *FirestoreJobRepository) SaveUpload(ctx context.Context, jobID string, upload model.JobUpload) error {
+	ref := r.client.Collection("job_uploads").Doc(jobID)
+	chunks := 0
+	for start := 0; start < len(upload.Content); start += uploadChunkSize {
+		chunk := upload.Content[start:min(start+uploadChunkSize, len(upload.Content))]
+		if _, err := ref.Collection("chunks").Doc(strconv.Itoa(chunks)).Set(ctx, map[string]any{"data": chunk}); err != nil {
+			return fmt.Errorf("failed to save upload: %w", err)
+		}
+		chunks++
+	}
+
+	doc := map[string]any{
+		"password_protected": upload.PasswordProtected,
+		"chunks":             chunks,
+	}
+	if _, err := ref.Set(ctx, doc); err != nil {
+		return fmt.Errorf("failed to save upload: %w", err)
+	}
+	return nil
+}
+
+func (r *FirestoreJobRepository) GetUpload(ctx context.Context, jobID string) (model.JobUpload, error) {
+	ref := r.client.Collection("job_uploads").Doc(jobID)
+	doc, err := ref.Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return model.JobUpload{}, model.ErrNotFound
+		}
+		return model.JobUpload{}, fmt.Errorf("failed to get upload: %w", err)
+	}
+
+	data := doc.Data()
+	chunks, _ := data["chunks"].(int64)
+	refs := make([]*firestore.DocumentRef, chunks)
+	for i := range refs {
+		refs[i] = ref.Collection("chunks").Doc(strconv.Itoa(i))
+	}
+	// Uploads stored before passwords were dropped hold one in plain text
+	_, legacyPassword := data["password"]
+	upload := model.JobUpload{PasswordProtected: boolVal(data, "password_protected") || legacyPassword}
+	if len(refs) == 0 {
+		return upload, nil
+	}
+	chunkDocs, err := r.client.GetAll(ctx, refs)
+	if err != nil {
+		return model.JobUpload{}, fmt.Errorf("failed to get upload: %w", err)
+	}
+	for _, chunkDoc := range chunkDocs {
+		if !chunkDoc.Exists() {
+			return model.JobUpload{}, model.ErrNotFound
+		}
+		chunk, _ := chunkDoc.Data()["data"].([]byte)
+		upload.Content = append(upload.Content, chunk...)
+	}
+	return upload, nil
+}
+
+// DeleteUpload removes the upload document first, so a partly deleted upload
+// is never read back
+func (r *FirestoreJobRepository) DeleteUpload(ctx context.Context, jobID string) error {
+	ref := r.client.Collection("job_uploads").Doc(jobID)
+	if _, err := ref.Delete(ctx); err != nil {
+		return fmt.Errorf("failed to delete upload: %w", err)
+	}
+
+	chunks, err := ref.Collection("chunks").DocumentRefs(ctx).GetAll()
+	if err != nil {
+		return fmt.Errorf("failed to delete upload: %w", err)
+	}
+	for _, chunk := range chunks {
+		if _, err := chunk.Delete(ctx); err != nil {
+			return fmt.Errorf("failed to delete upload: %w", err)
+		}
+	}
+	return nil
+}
+
+func jobFromDoc(id string, data map[string]any) model.Job {
+	job := model.Job{
+		ID:          id,
+		UserID:      stringVal(data, "user_id"),
+		Status:      model.JobStatus(stringVal(data, "status")),
+		StatementID: stringVal(data, "statement_id"),
+		Error:       stringVal(data, "error"),
+		CreatedAt:   timeVal(data, "created_at"),
+		UpdatedAt:   timeVal(data, "updated_at"),
+	}
+	if items, ok := data["transactions"].([]any); ok {
+		for _, item := range items {
+			if m, ok := item.(map[string]any); ok {
+				job.Transactions = append(job.Transactions, transactionFromDoc(stringVal(m, "id"), m))
+			}
+		}
+	}
+	return job
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"github.com/tsongpon/helios/internal/model"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type FirestoreRuleRepository struct {
+	client *firestore.Client
+}
+
+func NewFirestoreRuleRepository(client *firestore.Client) *FirestoreRuleRepository {
+	return &FirestoreRuleRepository{
+		client: client,
+	}
+}
+
+func (r *FirestoreRuleRepository) Save(ctx context.Context, rule model.Rule) error {
+	doc := map[string]any{
+		"user_id":       rule.UserID,
+		"name":          rule.Name,
+		"condition":     rule.Condition,
+		"set_category":  rule.Actions.SetCategory,
+		"add_tags":      rule.Actions.AddTags,
+		"mark_business": rule.Actions.MarkBusiness,
+		"created_at":    rule.CreatedAt,
+		"updated_at":    rule.UpdatedAt,
+	}
+
+	if _, err := r.client.Collection("rules").Doc(rule.ID).Set(ctx, doc); err != nil {
+		return fmt.Errorf("failed to save rule: %w", err)
+	}
+
+	return nil
+}
+
+func (r *FirestoreRuleRepository) GetRule(ctx context.Context, ruleID string) (model.Rule, error) {
+	doc, err := r.client.Collection("rules").Doc(ruleID).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return model.Rule{}, model.ErrNotFound
+		}
+		return model.Rule{}, fmt.Errorf("failed to get rule: %w", err)
+	}
+
+	return ruleFromDoc(doc.Ref.ID, doc.Data()), nil
+}
+
+// GetRules returns the user's rules oldest first, the order in which they
+// are evaluated
+func (r *FirestoreRuleRepository) GetRules(ctx context.Context, userID string) ([]model.Rule, error) {
+	docs, err := r.client.Collection("rules").
+		Where("user_id", "==", userID).
+		OrderBy("created_at", firestore.Asc).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rules: %w", err)
+	}
+
+	rules := make([]model.Rule, 0, len(docs))
+	for _, doc := range docs {
+		rules = append(rules, ruleFromDoc(doc.Ref.ID, doc.Data()))
+	}
+
+	return rules, nil
+}
+
+func (r *FirestoreRuleRepository) Delete(ctx context.Context, ruleID string) error {
+	if _, err := r.client.Collection("rules").Doc(ruleID).Delete(ctx); err != nil {
+		return fmt.Errorf("failed to delete rule: %w", err)
+	}
+	return nil
+}
+
+func ruleFromDoc(id string, data map[string]any) model.Rule {
+	return model.Rule{
+		ID:        id,
+		UserID:    stringVal(data, "user_id"),
+		Name:      stringVal(data, "name"),
+		Condition: stringVal(data, "condition"),
+		Actions: model.RuleActions{
+			SetCategory:  stringVal(data, "set_category"),
+			AddTags:      stringsVal(data, "add_tags"),
+			MarkBusiness: boolVal(data, "mark_business"),
+		},
+		CreatedAt: timeVal(data, "created_at"),
+		UpdatedAt: timeVal(data, "updated_at"),
+	}
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"github.com/tsongpon/helios/internal/model"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type FirestoreStatementRepository struct {
+	client *firestore.Client
+}
+
+func NewFirestoreStatementRepository(client *firestore.Client) *FirestoreStatementRepository {
+	return &FirestoreStatementRepository{
+		client: client,
+	}
+}
+
+// Create saves a new statement, returning model.ErrAlreadyExists when a
+// statement with the same ID exists
+func (r *FirestoreStatementRepository) Create(ctx context.Context, statement model.Statement) error {
+	if _, err := r.client.Collection("statements").Doc(statement.ID).Create(ctx, statementToDoc(statement)); err != nil {
+		if status.Code(err) == codes.AlreadyExists {
+			return model.ErrAlreadyExists
+		}
+		return fmt.Errorf("failed to create statement: %w", err)
+	}
+
+	return nil
+}
+
+func (r *FirestoreStatementRepository) Save(ctx context.Context, statement model.Statement) error {
+	if _, err := r.client.Collection("statements").Doc(statement.ID).Set(ctx, statementToDoc(statement)); err != nil {
+		return fmt.Errorf("failed to save statement: %w", err)
+	}
+
+	return nil
+}
+
+func statementToDoc(statement model.Statement) map[string]any {
+	return map[string]any{
+		"user_id":               statement.UserID,
+		"bank":                  statement.Bank,
+		"card_number":           statement.CardNumber,
+		"statement_date":        statement.StatementDate,
+		"period_start":          statement.PeriodStart,
+		"period_end":            statement.PeriodEnd,
+		"payment_due_date":      statement.PaymentDueDate,
+		"previous_balance":      statement.PreviousBalance,
+		"total_payment":         statement.TotalPayment,
+		"minimum_payment":       statement.MinimumPayment,
+		"credit_line":           statement.CreditLine,
+		"file_hash":             statement.FileHash,
+		"source_text":           statement.SourceText,
+		"prompt_version":        statement.PromptVersion,
+		"model":                 statement.Model,
+		"created_at":            statement.CreatedAt,
+		"reconciliation_status": string(statement.ReconciliationStatus),
+		"reconciliation_delta":  statement.ReconciliationDelta,
+	}
+}
+
+func (r *FirestoreStatementRepository) GetStatement(ctx context.Context, statementID string) (model.Statement, error) {
+	doc, err := r.client.Collection("statements").Doc(statementID).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return model.Statement{}, model.ErrNotFound
+		}
+		return model.Statement{}, fmt.Errorf("failed to get statement: %w", err)
+	}
+
+	return statementFromDoc(doc.Ref.ID, doc.Data()), nil
+}
+
+func (r *FirestoreStatementRepository) GetStatements(ctx context.Context, userID string) ([]model.Statement, error) {
+	docs, err := r.client.Collection("statements").
+		Where("user_id", "==", userID).
+		OrderBy("created_at", firestore.Desc).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statements: %w", err)
+	}
+
+	statements := make([]model.Statement, 0, len(docs))
+	for _, doc := range docs {
+		statements = append(statements, statementFromDoc(doc.Ref.ID, doc.Data()))
+	}
+
+	return statements, nil
+}
+
+func (r *FirestoreStatementRepository) GetStatementByFileHash(ctx context.Context, userID, fileHash string) (model.Statement, error) {
+	docs, err := r.client.Collection("statements").
+		Where("user_id", "==", userID).
+		Where("file_hash", "==", fileHash).
+		Limit(1).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		return model.Statement{}, fmt.Errorf("failed to get statement: %w", err)
+	}
+	if len(docs) == 0 {
+		return model.Statement{}, model.ErrNotFound
+	}
+
+	return statementFromDoc(docs[0].Ref.ID, docs[0].Data()), nil
+}
+
+// UpdateReconciliation updates only the reconciliation fields, so it cannot
+// overwrite a concurrent reparse of the statement
+func (r *FirestoreStatementRepository) UpdateReconciliation(ctx context.Context, statementID string, reconciliationStatus model.ReconciliationStatus, delta float64) error {
+	_, err := r.client.Collection("statements").Doc(statementID).Update(ctx, []firestore.Update{
+		{Path: "reconciliation_status", Value: string(reconciliationStatus)},
+		{Path: "reconciliation_delta", Value: delta},
+	}, firestore.Exists)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return model.ErrNotFound
+		}
+		return fmt.Errorf("failed to update statement: %w", err)
+	}
+	return nil
+}
+
+func (r *FirestoreStatementRepository) Delete(ctx context.Context, statementID string) error {
+	if _, err := r.client.Collection("statements").Doc(statementID).Delete(ctx); err != nil {
+		return fmt.Errorf("failed to delete statement: %w", err)
+	}
+
+	return nil
+}
+
+func statementFromDoc(id string, data map[string]any) model.Statement {
+	return model.Statement{
+		ID:                   id,
+		UserID:               stringVal(data, "user_id"),
+		Bank:                 stringVal(data, "bank"),
+		CardNumber:           stringVal(data, "card_number"),
+		StatementDate:        stringVal(data, "statement_date"),
+		PeriodStart:          stringVal(data, "period_start"),
+		PeriodEnd:            stringVal(data, "period_end"),
+		PaymentDueDate:       stringVal(data, "payment_due_date"),
+		PreviousBalance:      floatVal(data, "previous_balance"),
+		TotalPayment:         floatVal(data, "total_payment"),
+		MinimumPayment:       floatVal(data, "minimum_payment"),
+		CreditLine:           floatVal(data, "credit_line"),
+		FileHash:             stringVal(data, "file_hash"),
+		SourceText:           stringVal(data, "source_text"),
+		PromptVersion:        stringVal(data, "prompt_version"),
+		Model:                stringVal(data, "model"),
+		CreatedAt:            timeVal(data, "created_at"),
+		ReconciliationStatus: model.ReconciliationStatus(stringVal(data, "reconciliation_status")),
+		ReconciliationDelta:  floatVal(data, "reconciliation_delta"),
+	}
+}
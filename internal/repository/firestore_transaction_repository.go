@@ -2,11 +2,15 @@ package repository
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"cloud.google.com/go/firestore"
 	"github.com/tsongpon/helios/internal/model"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type FirestoreTransactionRepository struct {
@@ -20,25 +24,18 @@ func NewFirestoreTransactionRepository(client *firestore.Client) *FirestoreTrans
 }
 
 func (r *FirestoreTransactionRepository) Save(ctx context.Context, transactions []model.Transaction) error {
-	batch := r.client.Batch()
 	collection := r.client.Collection("transactions")
 
-	for _, t := range transactions {
-		doc := map[string]any{
-			"card_number":      t.CardNumber,
-			"user_id":          t.UserID,
-			"transaction_date": t.TransactionDate,
-			"posting_date":     t.PostingDate,
-			"description":      t.Description,
-			"amount":           t.Amount,
-			"is_installment":   t.IsInstallment,
-			"installment_term": t.InstallmentTerm,
-		}
+	err := r.commitInBatches(ctx, len(transactions), func(batch *firestore.WriteBatch, i int) {
+		// Transactions with a deterministic ID are upserted so re-parsing
+		// an overlapping statement does not create duplicates
+		t := transactions[i]
 		docRef := collection.NewDoc()
-		batch.Set(docRef, doc)
-	}
-
-	_, err := batch.Commit(ctx)
+		if t.ID != "" {
+			docRef = collection.Doc(t.ID)
+		}
+		batch.Set(docRef, transactionToDoc(t))
+	})
 	if err != nil {
 		return fmt.Errorf("failed to save transactions: %w", err)
 	}
@@ -46,14 +43,164 @@ func (r *FirestoreTransactionRepository) Save(ctx context.Context, transactions
 	return nil
 }
 
-func (r *FirestoreTransactionRepository) GetTransactions(ctx context.Context, userID string, from, to time.Time) ([]model.Transaction, error) {
-	fromStr := from.Format("2006-01-02")
-	toStr := to.Format("2006-01-02")
+// maxBatchWrites is the most writes Firestore accepts in one batch
+const maxBatchWrites = 500
 
-	docs, err := r.client.Collection("transactions").
+// commitInBatches calls write for each of n items and commits the writes in
+// batches of at most maxBatchWrites. Batches committed before an error stay
+// committed, so callers only write what is safe to apply partially.
+func (r *FirestoreTransactionRepository) commitInBatches(ctx context.Context, n int, write func(batch *firestore.WriteBatch, i int)) error {
+	for start := 0; start < n; start += maxBatchWrites {
+		batch := r.client.Batch()
+		for i := start; i < min(start+maxBatchWrites, n); i++ {
+			write(batch, i)
+		}
+		if _, err := batch.Commit(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetTransactions returns one page of transactions ordered by transaction
+// date then document ID. The cursor encodes the last returned transaction and
+// resumes the query after it with StartAfter.
+//
+// Equality and tag filters are part of the Firestore query. Amount, type and
+// description filters cannot be combined with the transaction date range in
+// a single query, and older documents have no source or business flag, so
+// these filters are applied to the results, reading further batches until
+// the page is full. At most maxTransactionScan documents are read per page;
+// when filters match too few of them, the page is returned short with a
+// cursor that resumes after the last document read.
+func (r *FirestoreTransactionRepository) GetTransactions(ctx context.Context, userID string, query model.TransactionQuery) (model.TransactionPage, error) {
+	q := r.client.Collection("transactions").
 		Where("user_id", "==", userID).
-		Where("transaction_date", ">=", fromStr).
-		Where("transaction_date", "<=", toStr).
+		Where("transaction_date", ">=", query.From.Format("2006-01-02")).
+		Where("transaction_date", "<=", query.To.Format("2006-01-02"))
+	f := query.Filter
+	if f.CardNumber != "" {
+		q = q.Where("card_number", "==", f.CardNumber)
+	}
+	if f.StatementID != "" {
+		q = q.Where("statement_id", "==", f.StatementID)
+	}
+	if f.Category != "" {
+		q = q.Where("category", "==", f.Category)
+	}
+	if f.Merchant != "" {
+		q = q.Where("merchant", "==", f.Merchant)
+	}
+	if f.Installment != nil {
+		q = q.Where("is_installment", "==", *f.Installment)
+	}
+	if f.Tag != "" {
+		q = q.Where("tags", "array-contains", f.Tag)
+	}
+	q = q.OrderBy("transaction_date", firestore.Asc).
+		OrderBy(firestore.DocumentID, firestore.Asc)
+
+	var after *transactionCursor
+	if query.Cursor != "" {
+		cursor, err := decodeTransactionCursor(query.Cursor)
+		if err != nil {
+			return model.TransactionPage{}, err
+		}
+		after = &cursor
+	}
+
+	// Collect one extra match to learn whether another page follows
+	batchSize := query.Limit + 1
+	if f.MinAmount != nil || f.MaxAmount != nil || f.Type != "" || f.Source != "" || f.Business != nil || f.Description != "" {
+		batchSize = max(batchSize, minTransactionBatchSize)
+	}
+	var matches []model.Transaction
+	scanned := 0
+	for len(matches) <= query.Limit {
+		if scanned >= maxTransactionScan {
+			page := model.TransactionPage{Transactions: matches, NextCursor: encodeTransactionCursor(*after)}
+			if page.Transactions == nil {
+				page.Transactions = []model.Transaction{}
+			}
+			return page, nil
+		}
+
+		batch := q
+		if after != nil {
+			batch = batch.StartAfter(after.TransactionDate, after.ID)
+		}
+		docs, err := batch.Limit(batchSize).Documents(ctx).GetAll()
+		if err != nil {
+			return model.TransactionPage{}, fmt.Errorf("failed to get transactions: %w", err)
+		}
+
+		scanned += len(docs)
+		for _, doc := range docs {
+			t := transactionFromDoc(doc.Ref.ID, doc.Data())
+			if f.Matches(t) {
+				matches = append(matches, t)
+			}
+		}
+		if len(docs) < batchSize {
+			break
+		}
+		last := docs[len(docs)-1]
+		after = &transactionCursor{TransactionDate: stringVal(last.Data(), "transaction_date"), ID: last.Ref.ID}
+	}
+
+	page := model.TransactionPage{Transactions: matches}
+	if len(matches) > query.Limit {
+		page.Transactions = matches[:query.Limit]
+		last := page.Transactions[query.Limit-1]
+		page.NextCursor = encodeTransactionCursor(transactionCursor{TransactionDate: last.TransactionDate, ID: last.ID})
+	}
+	if page.Transactions == nil {
+		page.Transactions = []model.Transaction{}
+	}
+
+	return page, nil
+}
+
+func (r *FirestoreTransactionRepository) GetTransaction(ctx context.Context, transactionID string) (model.Transaction, error) {
+	doc, err := r.client.Collection("transactions").Doc(transactionID).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return model.Transaction{}, model.ErrNotFound
+		}
+		return model.Transaction{}, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	return transactionFromDoc(doc.Ref.ID, doc.Data()), nil
+}
+
+// GetTransactionsByIDs returns the transactions that exist among ids
+func (r *FirestoreTransactionRepository) GetTransactionsByIDs(ctx context.Context, ids []string) ([]model.Transaction, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	refs := make([]*firestore.DocumentRef, len(ids))
+	for i, id := range ids {
+		refs[i] = r.client.Collection("transactions").Doc(id)
+	}
+	docs, err := r.client.GetAll(ctx, refs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	var transactions []model.Transaction
+	for _, doc := range docs {
+		if doc.Exists() {
+			transactions = append(transactions, transactionFromDoc(doc.Ref.ID, doc.Data()))
+		}
+	}
+
+	return transactions, nil
+}
+
+func (r *FirestoreTransactionRepository) GetTransactionsByStatement(ctx context.Context, statementID string) ([]model.Transaction, error) {
+	docs, err := r.client.Collection("transactions").
+		Where("statement_id", "==", statementID).
 		Documents(ctx).
 		GetAll()
 	if err != nil {
@@ -62,23 +209,204 @@ func (r *FirestoreTransactionRepository) GetTransactions(ctx context.Context, us
 
 	transactions := make([]model.Transaction, 0, len(docs))
 	for _, doc := range docs {
-		data := doc.Data()
-		t := model.Transaction{
-			UserID:          stringVal(data, "user_id"),
-			CardNumber:      stringVal(data, "card_number"),
-			TransactionDate: stringVal(data, "transaction_date"),
-			PostingDate:     stringVal(data, "posting_date"),
-			Description:     stringVal(data, "description"),
-			Amount:          floatVal(data, "amount"),
-			IsInstallment:   boolVal(data, "is_installment"),
-			InstallmentTerm: stringVal(data, "installment_term"),
-		}
-		transactions = append(transactions, t)
+		transactions = append(transactions, transactionFromDoc(doc.Ref.ID, doc.Data()))
 	}
 
 	return transactions, nil
 }
 
+// Update reads the transaction, applies update and saves it in a Firestore
+// transaction, so concurrent updates of the same transaction are not lost.
+// Errors returned by update are returned unchanged.
+func (r *FirestoreTransactionRepository) Update(ctx context.Context, transactionID string, update func(*model.Transaction) error) (model.Transaction, error) {
+	docRef := r.client.Collection("transactions").Doc(transactionID)
+
+	var transaction model.Transaction
+	var updateErr error
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(docRef)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				updateErr = model.ErrNotFound
+			}
+			return err
+		}
+		transaction = transactionFromDoc(doc.Ref.ID, doc.Data())
+		if updateErr = update(&transaction); updateErr != nil {
+			return updateErr
+		}
+		return tx.Set(docRef, transactionToDoc(transaction))
+	})
+	if updateErr != nil {
+		return model.Transaction{}, updateErr
+	}
+	if err != nil {
+		return model.Transaction{}, fmt.Errorf("failed to update transaction: %w", err)
+	}
+
+	return transaction, nil
+}
+
+func (r *FirestoreTransactionRepository) Delete(ctx context.Context, transactionID string) error {
+	if _, err := r.client.Collection("transactions").Doc(transactionID).Delete(ctx); err != nil {
+		return fmt.Errorf("failed to delete transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *FirestoreTransactionRepository) DeleteByStatement(ctx context.Context, statementID string) error {
+	docs, err := r.client.Collection("transactions").
+		Where("statement_id", "==", statementID).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		return fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	err = r.commitInBatches(ctx, len(docs), func(batch *firestore.WriteBatch, i int) {
+		batch.Delete(docs[i].Ref)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete transactions: %w", err)
+	}
+
+	return nil
+}
+
+func (r *FirestoreTransactionRepository) DeleteByIDs(ctx context.Context, ids []string) error {
+	collection := r.client.Collection("transactions")
+
+	err := r.commitInBatches(ctx, len(ids), func(batch *firestore.WriteBatch, i int) {
+		batch.Delete(collection.Doc(ids[i]))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete transactions: %w", err)
+	}
+
+	return nil
+}
+
+// minTransactionBatchSize is the smallest number of documents read per query
+// while filtering results, so sparse matches do not cost a round trip each
+const minTransactionBatchSize = 100
+
+// maxTransactionScan bounds the documents read for one page while filtering
+// results, so a filter matching almost nothing cannot read the whole range
+const maxTransactionScan = 2000
+
+// transactionCursor is the sort key of the last transaction on a page
+type transactionCursor struct {
+	TransactionDate string `json:"d"`
+	ID              string `json:"id"`
+}
+
+func encodeTransactionCursor(cursor transactionCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeTransactionCursor(s string) (transactionCursor, error) {
+	var cursor transactionCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || json.Unmarshal(data, &cursor) != nil || cursor.ID == "" {
+		return transactionCursor{}, model.ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+func transactionToDoc(t model.Transaction) map[string]any {
+	return map[string]any{
+		"card_number":      t.CardNumber,
+		"user_id":          t.UserID,
+		"statement_id":     t.StatementID,
+		"transaction_date": t.TransactionDate,
+		"posting_date":     t.PostingDate,
+		"description":      t.Description,
+		"merchant":         t.Merchant,
+		"amount":           t.Amount,
+		"is_installment":   t.IsInstallment,
+		"installment_term": t.InstallmentTerm,
+		"category":         t.Category,
+		"notes":            t.Notes,
+		"source":           string(t.Source),
+		"tags":             t.Tags,
+		"is_business":      t.IsBusiness,
+		"overrides":        transactionPatchToDoc(t.Overrides),
+		"original":         transactionPatchToDoc(t.Original),
+	}
+}
+
+func transactionFromDoc(id string, data map[string]any) model.Transaction {
+	t := model.Transaction{
+		ID:              id,
+		UserID:          stringVal(data, "user_id"),
+		StatementID:     stringVal(data, "statement_id"),
+		CardNumber:      stringVal(data, "card_number"),
+		TransactionDate: stringVal(data, "transaction_date"),
+		PostingDate:     stringVal(data, "posting_date"),
+		Description:     stringVal(data, "description"),
+		Merchant:        stringVal(data, "merchant"),
+		Amount:          floatVal(data, "amount"),
+		IsInstallment:   boolVal(data, "is_installment"),
+		InstallmentTerm: stringVal(data, "installment_term"),
+		Category:        stringVal(data, "category"),
+		Notes:           stringVal(data, "notes"),
+		Source:          model.TransactionSource(stringVal(data, "source")),
+		Tags:            stringsVal(data, "tags"),
+		IsBusiness:      boolVal(data, "is_business"),
+		Overrides:       transactionPatchFromDoc(data, "overrides"),
+		Original:        transactionPatchFromDoc(data, "original"),
+	}
+	// Transactions saved before manual entries existed have no source
+	if t.Source == "" {
+		t.Source = model.TransactionSourceStatement
+	}
+	return t
+}
+
+func transactionPatchToDoc(p model.TransactionPatch) map[string]any {
+	doc := map[string]any{}
+	for key, value := range map[string]*string{
+		"transaction_date": p.TransactionDate,
+		"posting_date":     p.PostingDate,
+		"description":      p.Description,
+		"category":         p.Category,
+		"notes":            p.Notes,
+	} {
+		if value != nil {
+			doc[key] = *value
+		}
+	}
+	if p.Amount != nil {
+		doc["amount"] = *p.Amount
+	}
+	return doc
+}
+
+func transactionPatchFromDoc(data map[string]any, key string) model.TransactionPatch {
+	var p model.TransactionPatch
+	doc, ok := data[key].(map[string]any)
+	if !ok {
+		return p
+	}
+	for key, field := range map[string]**string{
+		"transaction_date": &p.TransactionDate,
+		"posting_date":     &p.PostingDate,
+		"description":      &p.Description,
+		"category":         &p.Category,
+		"notes":            &p.Notes,
+	} {
+		if value, ok := doc[key].(string); ok {
+			*field = &value
+		}
+	}
+	if amount, ok := doc["amount"].(float64); ok {
+		p.Amount = &amount
+	}
+	return p
+}
+
 func stringVal(data map[string]any, key string) string {
 	if v, ok := data[key].(string); ok {
 		return v
@@ -86,6 +414,17 @@ func stringVal(data map[string]any, key string) string {
 	return ""
 }
 
+func stringsVal(data map[string]any, key string) []string {
+	items, _ := data[key].([]any)
+	var values []string
+	for _, item := range items {
+		if v, ok := item.(string); ok {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
 func floatVal(data map[string]any, key string) float64 {
 	if v, ok := data[key].(float64); ok {
 		return v
@@ -99,3 +438,10 @@ func boolVal(data map[string]any, key string) bool {
 	}
 	return false
 }
+
+func timeVal(data map[string]any, key string) time.Time {
+	if v, ok := data[key].(time.Time); ok {
+		return v
+	}
+	return time.Time{}
+}
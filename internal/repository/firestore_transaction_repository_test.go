@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+func TestTransactionCursor(t *testing.T) {
+	cursor := transactionCursor{TransactionDate: "2024-12-15", ID: "txn-1"}
+
+	decoded, err := decodeTransactionCursor(encodeTransactionCursor(cursor))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if decoded != cursor {
+		t.Errorf("expected %+v, got %+v", cursor, decoded)
+	}
+
+	for _, invalid := range []string{"not base64!", "bm90IGpzb24", "e30"} {
+		if _, err := decodeTransactionCursor(invalid); !errors.Is(err, model.ErrInvalidCursor) {
+			t.Errorf("%q: expected ErrInvalidCursor, got %v", invalid, err)
+		}
+	}
+}
@@ -1,31 +1,54 @@
 package repository
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/http"
-	"strconv"
-	"strings"
-	"time"
+	"net/url"
 
 	"github.com/tsongpon/helios/internal/model"
 )
 
+// DefaultGeminiModel is used when no Gemini model is configured
+const DefaultGeminiModel = "gemini-2.0-flash"
+
+const geminiBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+
 type GeminiLLMRepository struct {
-	apiKey string
+	apiKey  string
+	model   string
+	baseURL string
+	prompts *PromptLibrary
 }
 
-func NewGeminiLLMRepository(apiKey string) *GeminiLLMRepository {
+// NewGeminiLLMRepository creates a Gemini-backed LLMRepository; an empty
+// model selects DefaultGeminiModel
+func NewGeminiLLMRepository(apiKey, model string) *GeminiLLMRepository {
+	if model == "" {
+		model = DefaultGeminiModel
+	}
 	return &GeminiLLMRepository{
-		apiKey: apiKey,
+		apiKey:  apiKey,
+		model:   model,
+		baseURL: geminiBaseURL,
+		prompts: DefaultPromptLibrary(),
 	}
 }
 
+// WithPrompts replaces the built-in prompt templates
+func (r *GeminiLLMRepository) WithPrompts(prompts *PromptLibrary) *GeminiLLMRepository {
+	r.prompts = prompts
+	return r
+}
+
 // Gemini API request/response structures
 type geminiRequest struct {
-	Contents []geminiContent `json:"contents"`
+	Contents         []geminiContent         `json:"contents"`
+	GenerationConfig *geminiGenerationConfig `json:"generationConfig,omitempty"`
+}
+
+type geminiGenerationConfig struct {
+	ResponseMIMEType string      `json:"responseMimeType,omitempty"`
+	ResponseSchema   *jsonSchema `json:"responseSchema,omitempty"`
 }
 
 type geminiContent struct {
@@ -46,50 +69,25 @@ type geminiResponse struct {
 	} `json:"candidates"`
 }
 
-func (r *GeminiLLMRepository) ParseStatement(statementText string) ([]model.Transaction, error) {
-	prompt := fmt.Sprintf(`Parse the following bank statement text and extract all transactions.
-
-First, output the card number on the FIRST line in the following format:
-CARD|card_number
-
-Rules for card number:
-- card_number: The credit card number (may be partially masked, e.g., "1234-56XX-XXXX-7890")
-- If card number is not found, use empty string
-
-Then, output each transaction on a separate line in pipe-delimited format:
-transaction_date|posting_date|description|amount|is_installment|installment_term
-
-Rules:
-- transaction_date: The date the transaction occurred (format: YYYY-MM-DD)
-- posting_date: The date the transaction was posted (format: YYYY-MM-DD), use transaction_date if not available
-- description: The transaction description (remove extra whitespace)
-- amount: The transaction amount as a number. Use NEGATIVE values for credits/refunds/payments (marked with "CR" suffix or with "-" suffix). Use POSITIVE values for purchases/charges.
-  - Example: "31,751.00 CR" should be -31751.00 (credit/payment)
-  - Example: "14.20-" should be -14.20 (payment/credit)
-  - Example: "1,070.00" should be 1070.00 (purchase/charge)
-- is_installment: "true" if this is an installment transaction, "false" otherwise
-- installment_term: For installment transactions, the term indicator (e.g., "009/010" means 9th payment of 10 total). Empty string for non-installment transactions.
-
-For installment transactions:
-- They may appear in a separate "Installment" section OR inline with the description
-- The installment term format is like "009/010" or "04/06" or "10/10" indicating current term / total terms
-- Inline format: The term may appear right after the merchant name, e.g., "ZOOM CAMERA-WEST GATE 10/10" or "2C2P *LAZADA 04/06"
-  - In this case, extract the term (e.g., "10/10", "04/06") as installment_term
-  - Use the rightmost amount as the transaction amount
-- Separate section format: if a line shows "13,281.00  009/010  6,640.50", use 6,640.50 as the amount
-- ANY transaction with a term pattern like "NN/NN" (digits/digits) should be marked as is_installment=true
-
-Determining the year for transactions:
-- If transaction dates only show day/month (e.g., "17/12" or "17/12/"), look for the PAYMENT DATE in the statement header to determine the year
-- The PAYMENT DATE is usually in format "DD/MM/YY" (e.g., "06/02/25" means 2025)
-- If transaction month is greater than payment month, the transaction year is the previous year
-- Example: Payment date is 06/02/25, transaction date 17/12 means 2024-12-17; transaction date 05/01 means 2025-01-05
-
-Output ONLY the CARD line followed by the pipe-delimited transaction lines, no other headers or extra text.
-
-Bank Statement Text:
-%s`, statementText)
+func (r *GeminiLLMRepository) ParseStatement(ctx context.Context, statementText string) (model.Statement, error) {
+	prompt, err := r.prompts.Render(statementText)
+	if err != nil {
+		return model.Statement{}, err
+	}
+
+	responseText, err := r.generate(ctx, prompt.Text, "statement", statementResponseSchema)
+	if err != nil {
+		return model.Statement{}, err
+	}
+	return parseTracedStatementResponse(responseText, prompt, r.model)
+}
+
+func (r *GeminiLLMRepository) ClassifyTransactions(ctx context.Context, transactions []model.Transaction, categories []string) ([]string, error) {
+	return classifyTransactions(ctx, r.generate, r.prompts, transactions, categories)
+}
 
+// generate sends prompt to Gemini and returns the JSON text of its response
+func (r *GeminiLLMRepository) generate(ctx context.Context, prompt, _ string, schema *jsonSchema) (string, error) {
 	req := geminiRequest{
 		Contents: []geminiContent{
 			{
@@ -98,91 +96,25 @@ Bank Statement Text:
 				},
 			},
 		},
+		GenerationConfig: &geminiGenerationConfig{
+			ResponseMIMEType: "application/json",
+			ResponseSchema:   schema,
+		},
 	}
 
-	reqBody, err := json.Marshal(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/gemini-2.0-flash:generateContent?key=%s", r.apiKey)
-
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
-
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(reqBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request to Gemini API: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Gemini API returned status %d", resp.StatusCode)
-	}
+	// The key goes in a header: transport errors quote the URL, and they end
+	// up in logs, job records and responses
+	endpoint := fmt.Sprintf("%s/models/%s:generateContent", r.baseURL, url.PathEscape(r.model))
+	headers := map[string]string{"x-goog-api-key": r.apiKey}
 
 	var geminiResp geminiResponse
-	if err := json.NewDecoder(resp.Body).Decode(&geminiResp); err != nil {
-		return nil, fmt.Errorf("failed to decode Gemini response: %w", err)
+	if err := postJSON(ctx, endpoint, headers, req, &geminiResp, "Gemini"); err != nil {
+		return "", err
 	}
 
 	if len(geminiResp.Candidates) == 0 || len(geminiResp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("no response from Gemini API")
-	}
-
-	responseText := geminiResp.Candidates[0].Content.Parts[0].Text
-	return parsePipeDelimitedResponse(responseText)
-}
-
-func parsePipeDelimitedResponse(text string) ([]model.Transaction, error) {
-	var transactions []model.Transaction
-	var cardNumber string
-	lines := strings.Split(strings.TrimSpace(text), "\n")
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		parts := strings.Split(line, "|")
-
-		// Parse card number line
-		if len(parts) == 2 && strings.TrimSpace(parts[0]) == "CARD" {
-			cardNumber = strings.TrimSpace(parts[1])
-			continue
-		}
-
-		// Parse transaction line
-		if len(parts) != 6 {
-			continue // Skip malformed lines
-		}
-
-		amount, err := strconv.ParseFloat(strings.TrimSpace(parts[3]), 64)
-		if err != nil {
-			continue // Skip lines with invalid amounts
-		}
-
-		isInstallment := strings.TrimSpace(parts[4]) == "true"
-		installmentTerm := strings.TrimSpace(parts[5])
-
-		transaction := model.Transaction{
-			CardNumber:      cardNumber,
-			TransactionDate: strings.TrimSpace(parts[0]),
-			PostingDate:     strings.TrimSpace(parts[1]),
-			Description:     strings.TrimSpace(parts[2]),
-			Amount:          amount,
-			IsInstallment:   isInstallment,
-			InstallmentTerm: installmentTerm,
-		}
-		transactions = append(transactions, transaction)
+		return "", fmt.Errorf("no response from Gemini API")
 	}
 
-	return transactions, nil
+	return geminiResp.Candidates[0].Content.Parts[0].Text, nil
 }
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+// ErrCircuitOpen is returned without calling the provider while its circuit
+// breaker is open
+var ErrCircuitOpen = errors.New("LLM provider circuit breaker is open")
+
+// CircuitBreakerLLMRepository stops calling a provider after a run of
+// consecutive availability failures. Once the cooldown has passed a single
+// trial call is let through; its success closes the circuit again.
+//
+// Statement parsing and transaction classification have separate circuits,
+// so failing classification calls do not block statement uploads.
+type CircuitBreakerLLMRepository struct {
+	next     LLMProvider
+	parse    *circuit
+	classify *circuit
+	now      func() time.Time
+}
+
+// circuit is the state of one circuit breaker
+type circuit struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+// NewCircuitBreakerLLMRepository wraps next with a circuit breaker that opens
+// for cooldown after threshold consecutive failures
+func NewCircuitBreakerLLMRepository(next LLMProvider, threshold int, cooldown time.Duration) *CircuitBreakerLLMRepository {
+	return &CircuitBreakerLLMRepository{
+		next:     next,
+		parse:    &circuit{threshold: threshold, cooldown: cooldown},
+		classify: &circuit{threshold: threshold, cooldown: cooldown},
+		now:      time.Now,
+	}
+}
+
+func (r *CircuitBreakerLLMRepository) ParseStatement(ctx context.Context, statementText string) (model.Statement, error) {
+	allowed, trial := r.parse.allow(r.now())
+	if !allowed {
+		return model.Statement{}, ErrCircuitOpen
+	}
+
+	statement, err := r.next.ParseStatement(ctx, statementText)
+	r.parse.record(ctx, err, r.now(), trial)
+	return statement, err
+}
+
+func (r *CircuitBreakerLLMRepository) ClassifyTransactions(ctx context.Context, transactions []model.Transaction, categories []string) ([]string, error) {
+	allowed, trial := r.classify.allow(r.now())
+	if !allowed {
+		return nil, ErrCircuitOpen
+	}
+
+	result, err := r.next.ClassifyTransactions(ctx, transactions, categories)
+	r.classify.record(ctx, err, r.now(), trial)
+	return result, err
+}
+
+// allow reports whether a call may go through, and whether it is the single
+// trial call let through after the cooldown
+func (c *circuit) allow(now time.Time) (allowed, trial bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.failures < c.threshold {
+		return true, false
+	}
+	if c.trial || now.Before(c.openUntil) {
+		return false, false
+	}
+	c.trial = true
+	return true, true
+}
+
+// record counts only availability failures; a provider that answers with an
+// unparseable statement is still up, and a call the caller cancelled says
+// nothing about the provider. Only the trial call itself ends the trial;
+// calls that started before the circuit opened may finish while it runs
+func (c *circuit) record(ctx context.Context, err error, now time.Time, trial bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if trial {
+		c.trial = false
+	}
+	var apiErr *LLMAPIError
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil && errors.As(err, &apiErr) && apiErr.Temporary() {
+		c.failures++
+		if c.failures >= c.threshold {
+			c.openUntil = now.Add(c.cooldown)
+		}
+		return
+	}
+	c.failures = 0
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+// TransactionClassifier assigns each transaction one of the given
+// categories; it matches service.TransactionClassifier
+type TransactionClassifier interface {
+	ClassifyTransactions(ctx context.Context, transactions []model.Transaction, categories []string) ([]string, error)
+}
+
+// LLMProvider is implemented by every LLM provider and wrapper in this package
+type LLMProvider interface {
+	StatementParser
+	TransactionClassifier
+}
+
+// generateFunc sends a prompt to a provider and returns the text of its
+// response, constrained to schema
+type generateFunc func(ctx context.Context, prompt, schemaName string, schema *jsonSchema) (string, error)
+
+type classificationResponse struct {
+	Categories []struct {
+		Index    int    `json:"index"`
+		Category string `json:"category"`
+	} `json:"categories"`
+}
+
+// classifyTransactions asks the LLM for a category per transaction. Entries
+// the model skipped or answered with an unknown category are left empty.
+func classifyTransactions(ctx context.Context, generate generateFunc, prompts *PromptLibrary, transactions []model.Transaction, categories []string) ([]string, error) {
+	if len(transactions) == 0 {
+		return nil, nil
+	}
+
+	var lines strings.Builder
+	for i, t := range transactions {
+		fmt.Fprintf(&lines, "%d. %s (%s)\n", i, t.Description, strconv.FormatFloat(t.Amount, 'f', 2, 64))
+	}
+	prompt, err := prompts.RenderClassification(categories, lines.String())
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &jsonSchema{
+		Type: "OBJECT",
+		Properties: map[string]*jsonSchema{
+			"categories": {
+				Type: "ARRAY",
+				Items: &jsonSchema{
+					Type: "OBJECT",
+					Properties: map[string]*jsonSchema{
+						"index":    {Type: "INTEGER"},
+						"category": {Type: "STRING", Enum: categories},
+					},
+					Required: []string{"index", "category"},
+				},
+			},
+		},
+		Required: []string{"categories"},
+	}
+
+	text, err := generate(ctx, prompt.Text, "categories", schema)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp classificationResponse
+	if err := json.Unmarshal([]byte(trimCodeFence(text)), &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode categories JSON: %w", err)
+	}
+
+	result := make([]string, len(transactions))
+	for _, c := range resp.Categories {
+		category := strings.ToLower(strings.TrimSpace(c.Category))
+		if c.Index >= 0 && c.Index < len(result) && slices.Contains(categories, category) {
+			result[c.Index] = category
+		}
+	}
+	return result, nil
+}
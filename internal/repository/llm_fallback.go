@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+// FallbackLLMRepository tries each provider in order and returns the first
+// successful result
+type FallbackLLMRepository struct {
+	providers []LLMProvider
+}
+
+// NewFallbackLLMRepository creates a provider chain; the first provider is
+// the primary one
+func NewFallbackLLMRepository(providers ...LLMProvider) *FallbackLLMRepository {
+	return &FallbackLLMRepository{
+		providers: providers,
+	}
+}
+
+func (r *FallbackLLMRepository) ParseStatement(ctx context.Context, statementText string) (model.Statement, error) {
+	return fallback(ctx, r.providers, func(provider LLMProvider) (model.Statement, error) {
+		return provider.ParseStatement(ctx, statementText)
+	})
+}
+
+func (r *FallbackLLMRepository) ClassifyTransactions(ctx context.Context, transactions []model.Transaction, categories []string) ([]string, error) {
+	return fallback(ctx, r.providers, func(provider LLMProvider) ([]string, error) {
+		return provider.ClassifyTransactions(ctx, transactions, categories)
+	})
+}
+
+func fallback[T any](ctx context.Context, providers []LLMProvider, call func(LLMProvider) (T, error)) (T, error) {
+	var zero T
+	var errs []error
+	for i, provider := range providers {
+		result, err := call(provider)
+		if err == nil {
+			return result, nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+		if i < len(providers)-1 {
+			log.Printf("LLM provider %d failed, falling back to the next provider: %v", i+1, err)
+		}
+	}
+	return zero, errors.Join(errs...)
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// LLMAPIError is returned when an LLM API cannot be reached or responds with
+// a non-200 status. StatusCode is 0 when the request was never answered.
+type LLMAPIError struct {
+	Provider   string
+	StatusCode int
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *LLMAPIError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("failed to send request to %s API: %v", e.Provider, e.Err)
+	}
+	return fmt.Sprintf("%s API returned status %d", e.Provider, e.StatusCode)
+}
+
+func (e *LLMAPIError) Unwrap() error {
+	return e.Err
+}
+
+// Temporary reports whether the call may succeed if retried: network errors,
+// timeouts, rate limiting and server errors
+func (e *LLMAPIError) Temporary() bool {
+	return e.StatusCode == 0 || e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as an
+// HTTP date, returning 0 when it is absent or invalid
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// postJSON sends body as JSON to url and decodes a 200 response into out.
+// provider names the API in error messages.
+func postJSON(ctx context.Context, url string, headers map[string]string, body, out any, provider string) error {
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(reqBody))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return &LLMAPIError{Provider: provider, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &LLMAPIError{
+			Provider:   provider,
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", provider, err)
+	}
+	return nil
+}
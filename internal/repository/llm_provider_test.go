@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+const providerTestStatementJSON = `{"card_number":"1234-56XX-XXXX-7890","statement":{"bank":"KTC"},"transactions":[{"transaction_date":"2025-01-05","posting_date":"2025-01-06","description":"SHOP","amount":1070,"is_installment":false,"installment_term":""}]}`
+
+func TestOpenAILLMRepository_ParseStatement(t *testing.T) {
+	var received openAIRequest
+	var authHeader, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		authHeader = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&received)
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]string{"role": "assistant", "content": providerTestStatementJSON}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	repo := NewOpenAILLMRepository(server.URL+"/v1/", "secret", "test-model")
+	statement, err := repo.ParseStatement(context.Background(), "statement text")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if path != "/v1/chat/completions" {
+		t.Errorf("expected path /v1/chat/completions, got %s", path)
+	}
+	if authHeader != "Bearer secret" {
+		t.Errorf("expected bearer authorization, got %q", authHeader)
+	}
+	if received.Model != "test-model" {
+		t.Errorf("expected model test-model, got %s", received.Model)
+	}
+	if received.ResponseFormat.JSONSchema == nil || received.ResponseFormat.JSONSchema.Schema.Type != "object" {
+		t.Errorf("expected lowercase JSON schema response format, got %+v", received.ResponseFormat)
+	}
+	if !strings.Contains(received.Messages[0].Content, "statement text") {
+		t.Errorf("expected prompt to contain statement text")
+	}
+	if statement.Bank != "KTC" || len(statement.Transactions) != 1 {
+		t.Errorf("unexpected statement: %+v", statement)
+	}
+	if statement.PromptVersion != "default.v1" || statement.Model != "test-model" {
+		t.Errorf("expected prompt version and model to be recorded, got %s and %s", statement.PromptVersion, statement.Model)
+	}
+}
+
+func TestOpenAILLMRepository_ParseStatement_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	repo := NewOpenAILLMRepository(server.URL, "", "")
+	_, err := repo.ParseStatement(context.Background(), "statement text")
+	if err == nil || !strings.Contains(err.Error(), "status 401") {
+		t.Errorf("expected status 401 error, got %v", err)
+	}
+}
+
+func TestOpenAILLMRepository_ClassifyTransactions(t *testing.T) {
+	var received openAIRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		content := `{"categories":[{"index":1,"category":"Transport"},{"index":0,"category":"crypto"},{"index":7,"category":"food"}]}`
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]string{"role": "assistant", "content": content}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	repo := NewOpenAILLMRepository(server.URL, "", "test-model")
+	transactions := []model.Transaction{
+		{Description: "STARBUCKS SILOM", Amount: 145},
+		{Description: "BTS RABBIT TOPUP", Amount: 500},
+	}
+	categories, err := repo.ClassifyTransactions(context.Background(), transactions, []string{"food", "transport", "other"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(categories) != 2 || categories[0] != "" || categories[1] != "transport" {
+		t.Errorf("expected unknown category dropped and index mapped, got %v", categories)
+	}
+	if received.ResponseFormat.JSONSchema == nil || received.ResponseFormat.JSONSchema.Name != "categories" {
+		t.Fatalf("expected categories response format, got %+v", received.ResponseFormat)
+	}
+	item := received.ResponseFormat.JSONSchema.Schema.Properties["categories"].Items
+	if got := item.Properties["category"].Enum; len(got) != 3 {
+		t.Errorf("expected category enum in schema, got %v", got)
+	}
+	prompt := received.Messages[0].Content
+	if !strings.Contains(prompt, "0. STARBUCKS SILOM (145.00)") || !strings.Contains(prompt, "food, transport, other") {
+		t.Errorf("expected transactions and categories in prompt, got %q", prompt)
+	}
+}
+
+func TestOllamaLLMRepository_ParseStatement(t *testing.T) {
+	var received ollamaRequest
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&received)
+		json.NewEncoder(w).Encode(map[string]any{
+			"message": map[string]string{"role": "assistant", "content": providerTestStatementJSON},
+		})
+	}))
+	defer server.Close()
+
+	repo := NewOllamaLLMRepository(server.URL, "")
+	statement, err := repo.ParseStatement(context.Background(), "statement text")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if path != "/api/chat" {
+		t.Errorf("expected path /api/chat, got %s", path)
+	}
+	if received.Model != DefaultOllamaModel {
+		t.Errorf("expected default model %s, got %s", DefaultOllamaModel, received.Model)
+	}
+	if received.Stream {
+		t.Error("expected streaming to be disabled")
+	}
+	if received.Format == nil || received.Format.Properties["transactions"].Type != "array" {
+		t.Errorf("expected lowercase JSON schema format, got %+v", received.Format)
+	}
+	if statement.CardNumber != "1234-56XX-XXXX-7890" || len(statement.Transactions) != 1 {
+		t.Errorf("unexpected statement: %+v", statement)
+	}
+}
+
+func TestGeminiLLMRepository_ParseStatement(t *testing.T) {
+	var path, query, key string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		query = r.URL.RawQuery
+		key = r.Header.Get("x-goog-api-key")
+		json.NewEncoder(w).Encode(map[string]any{
+			"candidates": []map[string]any{
+				{"content": map[string]any{"parts": []map[string]string{{"text": providerTestStatementJSON}}}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	repo := NewGeminiLLMRepository("secret", "")
+	repo.baseURL = server.URL
+	statement, err := repo.ParseStatement(context.Background(), "statement text")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if path != "/models/"+DefaultGeminiModel+":generateContent" {
+		t.Errorf("unexpected path %s", path)
+	}
+	if key != "secret" || query != "" {
+		t.Errorf("expected API key in header only, got header %q and query %q", key, query)
+	}
+	if statement.Bank != "KTC" {
+		t.Errorf("expected bank KTC, got %s", statement.Bank)
+	}
+}
+
+func TestGeminiLLMRepository_TransportErrorOmitsAPIKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	repo := NewGeminiLLMRepository("secret", "")
+	repo.baseURL = server.URL
+	_, err := repo.ParseStatement(context.Background(), "statement text")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("expected error without the API key, got %v", err)
+	}
+}
@@ -0,0 +1,351 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+// mockLLMProvider returns errs in order, then succeeds
+type mockLLMProvider struct {
+	errs  []error
+	calls int
+}
+
+func (m *mockLLMProvider) ParseStatement(ctx context.Context, statementText string) (model.Statement, error) {
+	m.calls++
+	if m.calls <= len(m.errs) {
+		return model.Statement{}, m.errs[m.calls-1]
+	}
+	return model.Statement{Bank: "KTC"}, nil
+}
+
+func (m *mockLLMProvider) ClassifyTransactions(ctx context.Context, transactions []model.Transaction, categories []string) ([]string, error) {
+	m.calls++
+	if m.calls <= len(m.errs) {
+		return nil, m.errs[m.calls-1]
+	}
+	return []string{"food"}, nil
+}
+
+func TestRetryingLLMRepository(t *testing.T) {
+	config := RetryConfig{MaxRetries: 2, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	t.Run("retries temporary errors with backoff", func(t *testing.T) {
+		next := &mockLLMProvider{errs: []error{
+			&LLMAPIError{Provider: "Gemini", StatusCode: http.StatusServiceUnavailable},
+			&LLMAPIError{Provider: "Gemini", Err: errors.New("connection reset")},
+		}}
+		var delays []time.Duration
+		repo := NewRetryingLLMRepository(next, config)
+		repo.sleep = func(_ context.Context, d time.Duration) error {
+			delays = append(delays, d)
+			return nil
+		}
+
+		statement, err := repo.ParseStatement(context.Background(), "text")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if statement.Bank != "KTC" || next.calls != 3 {
+			t.Errorf("expected success on third call, got %d calls", next.calls)
+		}
+		if len(delays) != 2 {
+			t.Fatalf("expected 2 delays, got %v", delays)
+		}
+		if delays[0] < 500*time.Millisecond || delays[0] > time.Second {
+			t.Errorf("expected first delay between 0.5s and 1s, got %s", delays[0])
+		}
+		if delays[1] < time.Second || delays[1] > 2*time.Second {
+			t.Errorf("expected second delay between 1s and 2s, got %s", delays[1])
+		}
+	})
+
+	t.Run("honors Retry-After", func(t *testing.T) {
+		next := &mockLLMProvider{errs: []error{
+			&LLMAPIError{Provider: "Gemini", StatusCode: http.StatusTooManyRequests, RetryAfter: 7 * time.Second},
+		}}
+		var delays []time.Duration
+		repo := NewRetryingLLMRepository(next, config)
+		repo.sleep = func(_ context.Context, d time.Duration) error {
+			delays = append(delays, d)
+			return nil
+		}
+
+		if _, err := repo.ParseStatement(context.Background(), "text"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(delays) != 1 || delays[0] != 7*time.Second {
+			t.Errorf("expected a single 7s delay, got %v", delays)
+		}
+	})
+
+	t.Run("gives up when Retry-After exceeds the max delay", func(t *testing.T) {
+		next := &mockLLMProvider{errs: []error{
+			&LLMAPIError{Provider: "Gemini", StatusCode: http.StatusTooManyRequests, RetryAfter: time.Minute},
+		}}
+		repo := NewRetryingLLMRepository(next, config)
+		repo.sleep = func(context.Context, time.Duration) error {
+			t.Error("expected no sleep")
+			return nil
+		}
+
+		if _, err := repo.ParseStatement(context.Background(), "text"); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("does not retry permanent errors", func(t *testing.T) {
+		next := &mockLLMProvider{errs: []error{
+			&LLMAPIError{Provider: "Gemini", StatusCode: http.StatusBadRequest},
+		}}
+		repo := NewRetryingLLMRepository(next, config)
+		repo.sleep = func(context.Context, time.Duration) error { return nil }
+
+		if _, err := repo.ParseStatement(context.Background(), "text"); err == nil {
+			t.Error("expected error")
+		}
+		if next.calls != 1 {
+			t.Errorf("expected 1 call, got %d", next.calls)
+		}
+	})
+
+	t.Run("stops after max retries", func(t *testing.T) {
+		unavailable := &LLMAPIError{Provider: "Gemini", StatusCode: http.StatusServiceUnavailable}
+		next := &mockLLMProvider{errs: []error{unavailable, unavailable, unavailable, unavailable}}
+		repo := NewRetryingLLMRepository(next, config)
+		repo.sleep = func(context.Context, time.Duration) error { return nil }
+
+		if _, err := repo.ParseStatement(context.Background(), "text"); !errors.Is(err, unavailable) {
+			t.Errorf("expected last error, got %v", err)
+		}
+		if next.calls != 3 {
+			t.Errorf("expected 3 calls, got %d", next.calls)
+		}
+	})
+}
+
+func TestRetryingLLMRepository_ContextCancelled(t *testing.T) {
+	unavailable := &LLMAPIError{Provider: "Gemini", StatusCode: http.StatusServiceUnavailable}
+	next := &mockLLMProvider{errs: []error{unavailable, unavailable}}
+	repo := NewRetryingLLMRepository(next, DefaultRetryConfig)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := repo.ParseStatement(ctx, "text"); !errors.Is(err, unavailable) {
+		t.Errorf("expected provider error, got %v", err)
+	}
+	if next.calls != 1 {
+		t.Errorf("expected no retry after cancellation, got %d calls", next.calls)
+	}
+}
+
+func TestCircuitBreakerLLMRepository(t *testing.T) {
+	unavailable := &LLMAPIError{Provider: "Gemini", StatusCode: http.StatusServiceUnavailable}
+	next := &mockLLMProvider{errs: []error{unavailable, unavailable, unavailable}}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := NewCircuitBreakerLLMRepository(next, 2, time.Minute)
+	repo.now = func() time.Time { return now }
+
+	repo.ParseStatement(context.Background(), "text")
+	repo.ParseStatement(context.Background(), "text")
+	if _, err := repo.ParseStatement(context.Background(), "text"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected circuit to be open, got %v", err)
+	}
+	if next.calls != 2 {
+		t.Errorf("expected provider not to be called while open, got %d calls", next.calls)
+	}
+
+	// The trial call after the cooldown fails and reopens the circuit
+	now = now.Add(time.Minute)
+	if _, err := repo.ParseStatement(context.Background(), "text"); !errors.Is(err, unavailable) {
+		t.Fatalf("expected trial call to reach provider, got %v", err)
+	}
+	if _, err := repo.ParseStatement(context.Background(), "text"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected circuit to reopen, got %v", err)
+	}
+
+	// The next trial succeeds and closes the circuit
+	now = now.Add(time.Minute)
+	if _, err := repo.ParseStatement(context.Background(), "text"); err != nil {
+		t.Fatalf("expected trial call to succeed, got %v", err)
+	}
+	if _, err := repo.ParseStatement(context.Background(), "text"); err != nil {
+		t.Errorf("expected circuit to be closed, got %v", err)
+	}
+}
+
+func TestCircuitBreakerLLMRepository_IgnoresCancelledCalls(t *testing.T) {
+	next := &mockLLMProvider{errs: []error{
+		&LLMAPIError{Provider: "Gemini", Err: context.Canceled},
+	}}
+	repo := NewCircuitBreakerLLMRepository(next, 1, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	repo.ParseStatement(ctx, "text")
+	if _, err := repo.ParseStatement(context.Background(), "text"); err != nil {
+		t.Errorf("expected circuit to stay closed, got %v", err)
+	}
+}
+
+func TestCircuitBreakerLLMRepository_SingleTrialWithConcurrentCalls(t *testing.T) {
+	unavailable := &LLMAPIError{Provider: "Gemini", StatusCode: http.StatusServiceUnavailable}
+	slowStarted, trialStarted := make(chan struct{}), make(chan struct{})
+	releaseTrial := make(chan struct{})
+	var mu sync.Mutex
+	calls := 0
+	next := parserFunc(func(ctx context.Context, statementText string) (model.Statement, error) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		switch statementText {
+		case "slow":
+			close(slowStarted)
+			<-ctx.Done()
+			return model.Statement{}, &LLMAPIError{Provider: "Gemini", Err: ctx.Err()}
+		case "trial":
+			close(trialStarted)
+			<-releaseTrial
+			return model.Statement{}, nil
+		}
+		return model.Statement{}, unavailable
+	})
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := NewCircuitBreakerLLMRepository(next, 1, time.Minute)
+	repo.now = func() time.Time { return now }
+
+	// A call starts while the circuit is closed and is still running when
+	// another call opens it
+	slowCtx, cancelSlow := context.WithCancel(context.Background())
+	slowDone := make(chan struct{})
+	go func() {
+		defer close(slowDone)
+		repo.ParseStatement(slowCtx, "slow")
+	}()
+	<-slowStarted
+	repo.ParseStatement(context.Background(), "fail")
+
+	now = now.Add(time.Minute)
+	trialDone := make(chan error, 1)
+	go func() {
+		_, err := repo.ParseStatement(context.Background(), "trial")
+		trialDone <- err
+	}()
+	<-trialStarted
+
+	// The slow call finishing must not end the trial
+	cancelSlow()
+	<-slowDone
+	if _, err := repo.ParseStatement(context.Background(), "other"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected a second call during the trial to be rejected, got %v", err)
+	}
+
+	close(releaseTrial)
+	if err := <-trialDone; err != nil {
+		t.Fatalf("expected trial call to succeed, got %v", err)
+	}
+	if _, err := repo.ParseStatement(context.Background(), "trial-closed"); !errors.Is(err, unavailable) {
+		t.Errorf("expected circuit to be closed after the trial, got %v", err)
+	}
+	if calls != 4 {
+		t.Errorf("expected 4 provider calls, got %d", calls)
+	}
+}
+
+func TestCircuitBreakerLLMRepository_SeparatesClassification(t *testing.T) {
+	unavailable := &LLMAPIError{Provider: "Gemini", StatusCode: http.StatusServiceUnavailable}
+	next := &mockLLMProvider{errs: []error{unavailable}}
+	repo := NewCircuitBreakerLLMRepository(next, 1, time.Minute)
+
+	repo.ClassifyTransactions(context.Background(), []model.Transaction{{}}, []string{"food"})
+	if _, err := repo.ClassifyTransactions(context.Background(), []model.Transaction{{}}, []string{"food"}); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected classification circuit to be open, got %v", err)
+	}
+	if _, err := repo.ParseStatement(context.Background(), "text"); err != nil {
+		t.Errorf("expected statement parsing to stay available, got %v", err)
+	}
+}
+
+func TestTimeoutLLMRepository(t *testing.T) {
+	var deadline time.Time
+	next := parserFunc(func(ctx context.Context, statementText string) (model.Statement, error) {
+		deadline, _ = ctx.Deadline()
+		return model.Statement{}, nil
+	})
+	repo := NewTimeoutLLMRepository(next, 5*time.Second)
+
+	repo.ParseStatement(context.Background(), "text")
+	if deadline.IsZero() || deadline.After(time.Now().Add(5*time.Second)) {
+		t.Errorf("expected deadline within 5s, got %v", deadline)
+	}
+}
+
+type parserFunc func(ctx context.Context, statementText string) (model.Statement, error)
+
+func (f parserFunc) ParseStatement(ctx context.Context, statementText string) (model.Statement, error) {
+	return f(ctx, statementText)
+}
+
+func (f parserFunc) ClassifyTransactions(ctx context.Context, transactions []model.Transaction, categories []string) ([]string, error) {
+	return nil, nil
+}
+
+func TestFallbackLLMRepository(t *testing.T) {
+	primary := &mockLLMProvider{errs: []error{ErrCircuitOpen}}
+	secondary := &mockLLMProvider{}
+	repo := NewFallbackLLMRepository(primary, secondary)
+
+	statement, err := repo.ParseStatement(context.Background(), "text")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if statement.Bank != "KTC" || secondary.calls != 1 {
+		t.Errorf("expected result from secondary provider")
+	}
+
+	failing := &mockLLMProvider{errs: []error{errors.New("bad response")}}
+	repo = NewFallbackLLMRepository(&mockLLMProvider{errs: []error{ErrCircuitOpen}}, failing)
+	if _, err := repo.ParseStatement(context.Background(), "text"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected joined provider errors, got %v", err)
+	}
+}
+
+func TestLLMWrappers_ClassifyTransactions(t *testing.T) {
+	primary := &mockLLMProvider{errs: []error{&LLMAPIError{Provider: "Gemini", StatusCode: http.StatusServiceUnavailable}}}
+	retrying := NewRetryingLLMRepository(primary, RetryConfig{MaxRetries: 1, BaseDelay: time.Millisecond, MaxDelay: time.Second})
+	retrying.sleep = func(context.Context, time.Duration) error { return nil }
+	repo := NewFallbackLLMRepository(NewCircuitBreakerLLMRepository(NewTimeoutLLMRepository(retrying, time.Second), 3, time.Minute))
+
+	categories, err := repo.ClassifyTransactions(context.Background(), []model.Transaction{{Description: "GRAB FOOD"}}, []string{"food"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(categories) != 1 || categories[0] != "food" || primary.calls != 2 {
+		t.Errorf("expected retried classification, got %v after %d calls", categories, primary.calls)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"5", 5 * time.Second},
+		{"-1", 0},
+		{now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second},
+		{"soon", 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"time"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+// RetryConfig controls how RetryingLLMRepository retries temporary failures
+type RetryConfig struct {
+	// MaxRetries is the number of retries after the first attempt
+	MaxRetries int
+	// BaseDelay is the delay before the first retry; it doubles on every retry
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff delay. A Retry-After longer than this ends
+	// retrying instead of blocking the caller.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryConfig retries three times with delays of roughly 1s, 2s and 4s
+var DefaultRetryConfig = RetryConfig{
+	MaxRetries: 3,
+	BaseDelay:  time.Second,
+	MaxDelay:   30 * time.Second,
+}
+
+// RetryingLLMRepository retries temporary LLM API failures using exponential
+// backoff with jitter, honoring the provider's Retry-After header
+type RetryingLLMRepository struct {
+	next   LLMProvider
+	config RetryConfig
+	sleep  func(context.Context, time.Duration) error
+}
+
+// NewRetryingLLMRepository wraps next with retries
+func NewRetryingLLMRepository(next LLMProvider, config RetryConfig) *RetryingLLMRepository {
+	return &RetryingLLMRepository{
+		next:   next,
+		config: config,
+		sleep:  sleepContext,
+	}
+}
+
+func (r *RetryingLLMRepository) ParseStatement(ctx context.Context, statementText string) (model.Statement, error) {
+	return retry(ctx, r, func() (model.Statement, error) {
+		return r.next.ParseStatement(ctx, statementText)
+	})
+}
+
+func (r *RetryingLLMRepository) ClassifyTransactions(ctx context.Context, transactions []model.Transaction, categories []string) ([]string, error) {
+	return retry(ctx, r, func() ([]string, error) {
+		return r.next.ClassifyTransactions(ctx, transactions, categories)
+	})
+}
+
+// retry runs call until it succeeds, fails permanently or runs out of retries
+func retry[T any](ctx context.Context, r *RetryingLLMRepository, call func() (T, error)) (T, error) {
+	var zero T
+	for attempt := 0; ; attempt++ {
+		result, err := call()
+		if err == nil {
+			return result, nil
+		}
+
+		var apiErr *LLMAPIError
+		if !errors.As(err, &apiErr) || !apiErr.Temporary() || attempt >= r.config.MaxRetries {
+			return zero, err
+		}
+
+		delay := r.backoff(attempt)
+		if apiErr.RetryAfter > 0 {
+			if apiErr.RetryAfter > r.config.MaxDelay {
+				return zero, err
+			}
+			delay = apiErr.RetryAfter
+		}
+
+		log.Printf("LLM call failed (attempt %d/%d), retrying in %s: %v", attempt+1, r.config.MaxRetries+1, delay, err)
+		if r.sleep(ctx, delay) != nil {
+			return zero, err
+		}
+	}
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// backoff returns the exponential delay for the given attempt with equal
+// jitter: half of it fixed, the other half random
+func (r *RetryingLLMRepository) backoff(attempt int) time.Duration {
+	delay := r.config.BaseDelay << attempt
+	if delay <= 0 || delay > r.config.MaxDelay {
+		delay = r.config.MaxDelay
+	}
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + rand.N(half+1)
+}
@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+// StatementParser parses statement text into a statement; it matches
+// service.LLMRepository
+type StatementParser interface {
+	ParseStatement(ctx context.Context, statementText string) (model.Statement, error)
+}
+
+// jsonSchema is the OpenAPI schema subset accepted by LLM structured output modes
+type jsonSchema struct {
+	Type        string                 `json:"type"`
+	Description string                 `json:"description,omitempty"`
+	Properties  map[string]*jsonSchema `json:"properties,omitempty"`
+	Items       *jsonSchema            `json:"items,omitempty"`
+	Required    []string               `json:"required,omitempty"`
+	Enum        []string               `json:"enum,omitempty"`
+}
+
+// statementResponseSchema describes the JSON document the LLM must return
+var statementResponseSchema = &jsonSchema{
+	Type: "OBJECT",
+	Properties: map[string]*jsonSchema{
+		"card_number": {Type: "STRING", Description: "Credit card number, possibly masked"},
+		"statement": {
+			Type: "OBJECT",
+			Properties: map[string]*jsonSchema{
+				"bank":             {Type: "STRING"},
+				"statement_date":   {Type: "STRING", Description: "YYYY-MM-DD"},
+				"period_start":     {Type: "STRING", Description: "YYYY-MM-DD"},
+				"period_end":       {Type: "STRING", Description: "YYYY-MM-DD"},
+				"payment_due_date": {Type: "STRING", Description: "YYYY-MM-DD"},
+				"previous_balance": {Type: "NUMBER"},
+				"total_payment":    {Type: "NUMBER"},
+				"minimum_payment":  {Type: "NUMBER"},
+				"credit_line":      {Type: "NUMBER"},
+			},
+			Required: []string{"bank", "statement_date", "period_start", "period_end", "payment_due_date", "previous_balance", "total_payment", "minimum_payment", "credit_line"},
+		},
+		"transactions": {
+			Type: "ARRAY",
+			Items: &jsonSchema{
+				Type: "OBJECT",
+				Properties: map[string]*jsonSchema{
+					"transaction_date": {Type: "STRING", Description: "YYYY-MM-DD"},
+					"posting_date":     {Type: "STRING", Description: "YYYY-MM-DD"},
+					"description":      {Type: "STRING"},
+					"amount":           {Type: "NUMBER", Description: "Negative for credits, refunds and payments"},
+					"is_installment":   {Type: "BOOLEAN"},
+					"installment_term": {Type: "STRING", Description: "Current term / total terms, e.g. 04/06"},
+				},
+				Required: []string{"transaction_date", "posting_date", "description", "amount", "is_installment", "installment_term"},
+			},
+		},
+	},
+	Required: []string{"card_number", "statement", "transactions"},
+}
+
+// lowercase returns a copy of the schema with JSON Schema style lowercase
+// type names, as expected by OpenAI-compatible and Ollama structured outputs
+func (s *jsonSchema) lowercase() *jsonSchema {
+	if s == nil {
+		return nil
+	}
+	out := *s
+	out.Type = strings.ToLower(s.Type)
+	out.Items = s.Items.lowercase()
+	if s.Properties != nil {
+		out.Properties = make(map[string]*jsonSchema, len(s.Properties))
+		for name, prop := range s.Properties {
+			out.Properties[name] = prop.lowercase()
+		}
+	}
+	return &out
+}
+
+// llmStatementResponse mirrors statementResponseSchema
+type llmStatementResponse struct {
+	CardNumber string `json:"card_number"`
+	Statement  struct {
+		Bank            string  `json:"bank"`
+		StatementDate   string  `json:"statement_date"`
+		PeriodStart     string  `json:"period_start"`
+		PeriodEnd       string  `json:"period_end"`
+		PaymentDueDate  string  `json:"payment_due_date"`
+		PreviousBalance float64 `json:"previous_balance"`
+		TotalPayment    float64 `json:"total_payment"`
+		MinimumPayment  float64 `json:"minimum_payment"`
+		CreditLine      float64 `json:"credit_line"`
+	} `json:"statement"`
+	Transactions []struct {
+		TransactionDate string  `json:"transaction_date"`
+		PostingDate     string  `json:"posting_date"`
+		Description     string  `json:"description"`
+		Amount          float64 `json:"amount"`
+		IsInstallment   bool    `json:"is_installment"`
+		InstallmentTerm string  `json:"installment_term"`
+	} `json:"transactions"`
+}
+
+// parseTracedStatementResponse parses the response and records the prompt
+// version and model that produced it
+func parseTracedStatementResponse(text string, prompt Prompt, modelName string) (model.Statement, error) {
+	statement, err := parseStatementResponse(text)
+	if err != nil {
+		return model.Statement{}, err
+	}
+	statement.PromptVersion = prompt.Version
+	statement.Model = modelName
+	return statement, nil
+}
+
+// parseStatementResponse decodes the LLM's JSON response, falling back to the
+// legacy pipe-delimited format for models that ignore the response schema
+func parseStatementResponse(text string) (model.Statement, error) {
+	statement, err := parseJSONResponse(text)
+	if err == nil {
+		return statement, nil
+	}
+
+	log.Printf("LLM response is not valid JSON, falling back to pipe-delimited parser: %v", err)
+	legacy, legacyErr := parsePipeDelimitedResponse(text)
+	if legacyErr != nil {
+		return model.Statement{}, legacyErr
+	}
+	if legacy.CardNumber == "" && len(legacy.Transactions) == 0 {
+		return model.Statement{}, err
+	}
+	return legacy, nil
+}
+
+// trimCodeFence removes the markdown code fence some models wrap JSON in
+// despite JSON mode
+func trimCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	text = strings.TrimPrefix(text, "```json")
+	text = strings.TrimPrefix(text, "```")
+	return strings.TrimSuffix(text, "```")
+}
+
+func parseJSONResponse(text string) (model.Statement, error) {
+	var resp llmStatementResponse
+	if err := json.Unmarshal([]byte(trimCodeFence(text)), &resp); err != nil {
+		return model.Statement{}, fmt.Errorf("failed to decode statement JSON: %w", err)
+	}
+
+	statement := model.Statement{
+		Bank:            strings.TrimSpace(resp.Statement.Bank),
+		CardNumber:      strings.TrimSpace(resp.CardNumber),
+		StatementDate:   strings.TrimSpace(resp.Statement.StatementDate),
+		PeriodStart:     strings.TrimSpace(resp.Statement.PeriodStart),
+		PeriodEnd:       strings.TrimSpace(resp.Statement.PeriodEnd),
+		PaymentDueDate:  strings.TrimSpace(resp.Statement.PaymentDueDate),
+		PreviousBalance: resp.Statement.PreviousBalance,
+		TotalPayment:    resp.Statement.TotalPayment,
+		MinimumPayment:  resp.Statement.MinimumPayment,
+		CreditLine:      resp.Statement.CreditLine,
+	}
+
+	for _, t := range resp.Transactions {
+		postingDate := strings.TrimSpace(t.PostingDate)
+		if postingDate == "" {
+			postingDate = strings.TrimSpace(t.TransactionDate)
+		}
+		statement.Transactions = append(statement.Transactions, model.Transaction{
+			CardNumber:      statement.CardNumber,
+			TransactionDate: strings.TrimSpace(t.TransactionDate),
+			PostingDate:     postingDate,
+			Description:     strings.Join(strings.Fields(t.Description), " "),
+			Amount:          t.Amount,
+			IsInstallment:   t.IsInstallment,
+			InstallmentTerm: strings.TrimSpace(t.InstallmentTerm),
+		})
+	}
+
+	return statement, nil
+}
+
+// parsePipeDelimitedResponse parses the legacy CARD|, STATEMENT| and
+// transaction line format. It is only used as a fallback for non-JSON responses.
+func parsePipeDelimitedResponse(text string) (model.Statement, error) {
+	var statement model.Statement
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+
+		// Parse card number line
+		if len(parts) == 2 && strings.TrimSpace(parts[0]) == "CARD" {
+			statement.CardNumber = strings.TrimSpace(parts[1])
+			continue
+		}
+
+		// Parse statement summary line
+		if len(parts) == 10 && strings.TrimSpace(parts[0]) == "STATEMENT" {
+			statement.Bank = strings.TrimSpace(parts[1])
+			statement.StatementDate = strings.TrimSpace(parts[2])
+			statement.PeriodStart = strings.TrimSpace(parts[3])
+			statement.PeriodEnd = strings.TrimSpace(parts[4])
+			statement.PaymentDueDate = strings.TrimSpace(parts[5])
+			statement.PreviousBalance = parseAmount(parts[6])
+			statement.TotalPayment = parseAmount(parts[7])
+			statement.MinimumPayment = parseAmount(parts[8])
+			statement.CreditLine = parseAmount(parts[9])
+			continue
+		}
+
+		// Parse transaction line
+		if len(parts) != 6 {
+			log.Printf("skipping statement line %d: expected 6 fields, got %d", i+1, len(parts))
+			continue
+		}
+
+		amount, err := strconv.ParseFloat(strings.TrimSpace(parts[3]), 64)
+		if err != nil {
+			log.Printf("skipping statement line %d: invalid amount", i+1)
+			continue
+		}
+
+		isInstallment := strings.TrimSpace(parts[4]) == "true"
+		installmentTerm := strings.TrimSpace(parts[5])
+
+		transaction := model.Transaction{
+			CardNumber:      statement.CardNumber,
+			TransactionDate: strings.TrimSpace(parts[0]),
+			PostingDate:     strings.TrimSpace(parts[1]),
+			Description:     strings.TrimSpace(parts[2]),
+			Amount:          amount,
+			IsInstallment:   isInstallment,
+			InstallmentTerm: installmentTerm,
+		}
+		statement.Transactions = append(statement.Transactions, transaction)
+	}
+
+	return statement, nil
+}
+
+// parseAmount parses an optional summary amount, tolerating thousands
+// separators and returning 0 when the field is empty or invalid
+func parseAmount(s string) float64 {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+	amount, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return amount
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"testing"
+)
+
+func TestParseStatementResponse(t *testing.T) {
+	t.Run("parses JSON response", func(t *testing.T) {
+		text := `{
+			"card_number": "1234-56XX-XXXX-7890",
+			"statement": {
+				"bank": "KTC",
+				"statement_date": "2025-01-20",
+				"period_start": "2024-12-21",
+				"period_end": "2025-01-20",
+				"payment_due_date": "2025-02-06",
+				"previous_balance": 31751.00,
+				"total_payment": 1070.00,
+				"minimum_payment": 107.00,
+				"credit_line": 100000.00
+			},
+			"transactions": [
+				{"transaction_date": "2024-12-17", "posting_date": "2024-12-18", "description": "PAYMENT - THANK YOU", "amount": -31751.00, "is_installment": false, "installment_term": ""},
+				{"transaction_date": "2025-01-05", "posting_date": "", "description": "SHOP  A|B   BANGKOK", "amount": 1070.00, "is_installment": true, "installment_term": "04/06"}
+			]
+		}`
+
+		statement, err := parseStatementResponse(text)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if statement.CardNumber != "1234-56XX-XXXX-7890" {
+			t.Errorf("expected card number 1234-56XX-XXXX-7890, got %s", statement.CardNumber)
+		}
+		if statement.Bank != "KTC" {
+			t.Errorf("expected bank KTC, got %s", statement.Bank)
+		}
+		if statement.PaymentDueDate != "2025-02-06" {
+			t.Errorf("expected payment due date 2025-02-06, got %s", statement.PaymentDueDate)
+		}
+		if statement.PreviousBalance != 31751.00 || statement.TotalPayment != 1070.00 {
+			t.Errorf("unexpected balances %f / %f", statement.PreviousBalance, statement.TotalPayment)
+		}
+		if len(statement.Transactions) != 2 {
+			t.Fatalf("expected 2 transactions, got %d", len(statement.Transactions))
+		}
+
+		txn := statement.Transactions[1]
+		if txn.Description != "SHOP A|B BANGKOK" {
+			t.Errorf("expected description with pipe to be kept, got %q", txn.Description)
+		}
+		if txn.PostingDate != "2025-01-05" {
+			t.Errorf("expected posting date to default to transaction date, got %s", txn.PostingDate)
+		}
+		if txn.CardNumber != "1234-56XX-XXXX-7890" {
+			t.Errorf("expected card number on transaction, got %s", txn.CardNumber)
+		}
+		if !txn.IsInstallment || txn.InstallmentTerm != "04/06" {
+			t.Errorf("expected installment 04/06, got %v %s", txn.IsInstallment, txn.InstallmentTerm)
+		}
+	})
+
+	t.Run("parses JSON wrapped in a code fence", func(t *testing.T) {
+		text := "```json\n{\"card_number\": \"1234\", \"statement\": {}, \"transactions\": []}\n```"
+
+		statement, err := parseStatementResponse(text)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if statement.CardNumber != "1234" {
+			t.Errorf("expected card number 1234, got %s", statement.CardNumber)
+		}
+	})
+
+	t.Run("falls back to pipe-delimited response", func(t *testing.T) {
+		text := "CARD|1234-56XX-XXXX-7890\n" +
+			"STATEMENT|KTC|2025-01-20|2024-12-21|2025-01-20|2025-02-06|31,751.00|1070.00|107.00|100000\n" +
+			"2024-12-17|2024-12-18|PAYMENT - THANK YOU|-31751.00|false|\n" +
+			"2025-01-05|2025-01-05|2C2P *LAZADA|1070.00|true|04/06\n" +
+			"malformed line\n"
+
+		statement, err := parseStatementResponse(text)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if statement.CardNumber != "1234-56XX-XXXX-7890" {
+			t.Errorf("expected card number 1234-56XX-XXXX-7890, got %s", statement.CardNumber)
+		}
+		if statement.PreviousBalance != 31751.00 {
+			t.Errorf("expected previous balance 31751.00, got %f", statement.PreviousBalance)
+		}
+		if len(statement.Transactions) != 2 {
+			t.Fatalf("expected 2 transactions, got %d", len(statement.Transactions))
+		}
+		if statement.Transactions[1].InstallmentTerm != "04/06" {
+			t.Errorf("expected installment term 04/06, got %s", statement.Transactions[1].InstallmentTerm)
+		}
+	})
+
+	t.Run("returns error for unparseable response", func(t *testing.T) {
+		_, err := parseStatementResponse("Sorry, I cannot help with that.")
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+// DefaultLLMTimeout bounds a single LLM API call when no timeout is configured
+const DefaultLLMTimeout = 60 * time.Second
+
+// TimeoutLLMRepository bounds each call to the wrapped provider; the
+// caller's context can still cancel the call earlier
+type TimeoutLLMRepository struct {
+	next    LLMProvider
+	timeout time.Duration
+}
+
+// NewTimeoutLLMRepository wraps next so that each call is cancelled after timeout
+func NewTimeoutLLMRepository(next LLMProvider, timeout time.Duration) *TimeoutLLMRepository {
+	return &TimeoutLLMRepository{
+		next:    next,
+		timeout: timeout,
+	}
+}
+
+func (r *TimeoutLLMRepository) ParseStatement(ctx context.Context, statementText string) (model.Statement, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	return r.next.ParseStatement(ctx, statementText)
+}
+
+func (r *TimeoutLLMRepository) ClassifyTransactions(ctx context.Context, transactions []model.Transaction, categories []string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	return r.next.ClassifyTransactions(ctx, transactions, categories)
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+const (
+	// DefaultOllamaBaseURL is used when no Ollama server URL is configured
+	DefaultOllamaBaseURL = "http://localhost:11434"
+	// DefaultOllamaModel is used when no Ollama model is configured
+	DefaultOllamaModel = "llama3.1"
+)
+
+// OllamaLLMRepository parses statements with a local Ollama server so that
+// statement text never leaves the deployment
+type OllamaLLMRepository struct {
+	model   string
+	baseURL string
+	prompts *PromptLibrary
+}
+
+// NewOllamaLLMRepository creates an Ollama-backed LLMRepository; empty
+// baseURL and model select DefaultOllamaBaseURL and DefaultOllamaModel
+func NewOllamaLLMRepository(baseURL, model string) *OllamaLLMRepository {
+	if baseURL == "" {
+		baseURL = DefaultOllamaBaseURL
+	}
+	if model == "" {
+		model = DefaultOllamaModel
+	}
+	return &OllamaLLMRepository{
+		model:   model,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		prompts: DefaultPromptLibrary(),
+	}
+}
+
+// WithPrompts replaces the built-in prompt templates
+func (r *OllamaLLMRepository) WithPrompts(prompts *PromptLibrary) *OllamaLLMRepository {
+	r.prompts = prompts
+	return r
+}
+
+// Ollama chat API request/response structures
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Format   *jsonSchema     `json:"format,omitempty"`
+	Stream   bool            `json:"stream"`
+}
+
+type ollamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ollamaResponse struct {
+	Message ollamaMessage `json:"message"`
+}
+
+func (r *OllamaLLMRepository) ParseStatement(ctx context.Context, statementText string) (model.Statement, error) {
+	prompt, err := r.prompts.Render(statementText)
+	if err != nil {
+		return model.Statement{}, err
+	}
+
+	responseText, err := r.generate(ctx, prompt.Text, "statement", statementResponseSchema)
+	if err != nil {
+		return model.Statement{}, err
+	}
+	return parseTracedStatementResponse(responseText, prompt, r.model)
+}
+
+func (r *OllamaLLMRepository) ClassifyTransactions(ctx context.Context, transactions []model.Transaction, categories []string) ([]string, error) {
+	return classifyTransactions(ctx, r.generate, r.prompts, transactions, categories)
+}
+
+// generate sends prompt to the Ollama chat API and returns the JSON text of
+// the response
+func (r *OllamaLLMRepository) generate(ctx context.Context, prompt, _ string, schema *jsonSchema) (string, error) {
+	req := ollamaRequest{
+		Model: r.model,
+		Messages: []ollamaMessage{
+			{Role: "user", Content: prompt},
+		},
+		Format: schema.lowercase(),
+	}
+
+	var ollamaResp ollamaResponse
+	if err := postJSON(ctx, r.baseURL+"/api/chat", nil, req, &ollamaResp, "Ollama"); err != nil {
+		return "", err
+	}
+
+	if strings.TrimSpace(ollamaResp.Message.Content) == "" {
+		return "", fmt.Errorf("no response from Ollama API")
+	}
+
+	return ollamaResp.Message.Content, nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+const (
+	// DefaultOpenAIBaseURL is used when no OpenAI-compatible base URL is configured
+	DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+	// DefaultOpenAIModel is used when no OpenAI-compatible model is configured
+	DefaultOpenAIModel = "gpt-4o-mini"
+)
+
+// OpenAILLMRepository parses statements with any API implementing the OpenAI
+// chat completions endpoint, such as OpenAI, vLLM or LM Studio
+type OpenAILLMRepository struct {
+	apiKey  string
+	model   string
+	baseURL string
+	prompts *PromptLibrary
+}
+
+// NewOpenAILLMRepository creates an OpenAI-compatible LLMRepository. Empty
+// baseURL and model select DefaultOpenAIBaseURL and DefaultOpenAIModel; apiKey
+// may be empty for local servers that do not require authentication.
+func NewOpenAILLMRepository(baseURL, apiKey, model string) *OpenAILLMRepository {
+	if baseURL == "" {
+		baseURL = DefaultOpenAIBaseURL
+	}
+	if model == "" {
+		model = DefaultOpenAIModel
+	}
+	return &OpenAILLMRepository{
+		apiKey:  apiKey,
+		model:   model,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		prompts: DefaultPromptLibrary(),
+	}
+}
+
+// WithPrompts replaces the built-in prompt templates
+func (r *OpenAILLMRepository) WithPrompts(prompts *PromptLibrary) *OpenAILLMRepository {
+	r.prompts = prompts
+	return r
+}
+
+// OpenAI chat completions request/response structures
+type openAIRequest struct {
+	Model          string               `json:"model"`
+	Messages       []openAIMessage      `json:"messages"`
+	ResponseFormat openAIResponseFormat `json:"response_format"`
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *openAIJSONSchema `json:"json_schema,omitempty"`
+}
+
+type openAIJSONSchema struct {
+	Name   string      `json:"name"`
+	Schema *jsonSchema `json:"schema"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+}
+
+func (r *OpenAILLMRepository) ParseStatement(ctx context.Context, statementText string) (model.Statement, error) {
+	prompt, err := r.prompts.Render(statementText)
+	if err != nil {
+		return model.Statement{}, err
+	}
+
+	responseText, err := r.generate(ctx, prompt.Text, "statement", statementResponseSchema)
+	if err != nil {
+		return model.Statement{}, err
+	}
+	return parseTracedStatementResponse(responseText, prompt, r.model)
+}
+
+func (r *OpenAILLMRepository) ClassifyTransactions(ctx context.Context, transactions []model.Transaction, categories []string) ([]string, error) {
+	return classifyTransactions(ctx, r.generate, r.prompts, transactions, categories)
+}
+
+// generate sends prompt to the chat completions endpoint and returns the
+// JSON text of the response
+func (r *OpenAILLMRepository) generate(ctx context.Context, prompt, schemaName string, schema *jsonSchema) (string, error) {
+	req := openAIRequest{
+		Model: r.model,
+		Messages: []openAIMessage{
+			{Role: "user", Content: prompt},
+		},
+		ResponseFormat: openAIResponseFormat{
+			Type: "json_schema",
+			JSONSchema: &openAIJSONSchema{
+				Name:   schemaName,
+				Schema: schema.lowercase(),
+			},
+		},
+	}
+
+	var headers map[string]string
+	if r.apiKey != "" {
+		headers = map[string]string{"Authorization": "Bearer " + r.apiKey}
+	}
+
+	var openAIResp openAIResponse
+	if err := postJSON(ctx, r.baseURL+"/chat/completions", headers, req, &openAIResp, "OpenAI"); err != nil {
+		return "", err
+	}
+
+	if len(openAIResp.Choices) == 0 {
+		return "", fmt.Errorf("no response from OpenAI API")
+	}
+
+	return openAIResp.Choices[0].Message.Content, nil
+}
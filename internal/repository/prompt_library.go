@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+//go:embed prompts/*.tmpl
+var embeddedPrompts embed.FS
+
+// defaultPromptName is the template used when no bank is detected
+const defaultPromptName = "default"
+
+// classificationPromptName is the template used to categorize transactions;
+// it is never picked for a statement
+const classificationPromptName = "classification"
+
+// sharedPromptFile holds the named partials available to every template
+const sharedPromptFile = "shared.tmpl"
+
+var promptFilePattern = regexp.MustCompile(`^([a-z0-9_-]+)\.v(\d+)\.tmpl$`)
+
+// Prompt is a rendered statement prompt
+type Prompt struct {
+	Text string
+	// Version identifies the template, e.g. "ktc.v2"
+	Version string
+}
+
+type promptTemplate struct {
+	name    string
+	version int
+	detect  []string
+	tmpl    *template.Template
+}
+
+func (t *promptTemplate) id() string {
+	return fmt.Sprintf("%s.v%d", t.name, t.version)
+}
+
+// PromptLibrary holds the latest version of each bank's prompt template and
+// picks one for a statement by detecting its bank.
+//
+// Templates are files named <bank>.v<N>.tmpl. An optional header ending in a
+// "---" line lists the keywords that identify the bank:
+//
+//	detect: KTC, KRUNGTHAI CARD
+//	---
+//	{{template "intro" .}}
+//	...
+//
+// A default.v<N>.tmpl template is required; shared.tmpl may define partials.
+// classification.v<N>.tmpl holds the transaction categorization prompt and
+// falls back to the built-in one when a directory does not override it.
+type PromptLibrary struct {
+	templates map[string]*promptTemplate
+}
+
+var defaultPromptLibrary = mustLoadEmbeddedPrompts()
+
+func mustLoadEmbeddedPrompts() *PromptLibrary {
+	sub, err := fs.Sub(embeddedPrompts, "prompts")
+	if err != nil {
+		panic(err)
+	}
+	library, err := LoadPromptLibrary(sub)
+	if err != nil {
+		panic(err)
+	}
+	return library
+}
+
+// DefaultPromptLibrary returns the prompt templates built into the binary
+func DefaultPromptLibrary() *PromptLibrary {
+	return defaultPromptLibrary
+}
+
+// LoadPromptLibrary loads the templates at the root of fsys, keeping the
+// highest version of each
+func LoadPromptLibrary(fsys fs.FS) (*PromptLibrary, error) {
+	shared := template.New(sharedPromptFile)
+	if content, err := fs.ReadFile(fsys, sharedPromptFile); err == nil {
+		if _, err := shared.Parse(string(content)); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", sharedPromptFile, err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", sharedPromptFile, err)
+	}
+
+	files, err := fs.Glob(fsys, "*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+
+	library := &PromptLibrary{templates: map[string]*promptTemplate{}}
+	for _, file := range files {
+		match := promptFilePattern.FindStringSubmatch(path.Base(file))
+		if match == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(match[2])
+		if existing, ok := library.templates[match[1]]; ok && existing.version > version {
+			continue
+		}
+
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		detect, body := splitPromptHeader(string(content))
+
+		tmpl, err := shared.Clone()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tmpl.New(file).Parse(body); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+
+		library.templates[match[1]] = &promptTemplate{
+			name:    match[1],
+			version: version,
+			detect:  detect,
+			tmpl:    tmpl.Lookup(file),
+		}
+	}
+
+	if _, ok := library.templates[defaultPromptName]; !ok {
+		return nil, fmt.Errorf("prompt library has no %s template", defaultPromptName)
+	}
+	return library, nil
+}
+
+// splitPromptHeader separates the optional detect header from the template body
+func splitPromptHeader(content string) ([]string, string) {
+	header, body, found := strings.Cut(content, "\n---\n")
+	if !found || !strings.HasPrefix(header, "detect:") {
+		return nil, content
+	}
+
+	var detect []string
+	for _, keyword := range strings.Split(strings.TrimPrefix(header, "detect:"), ",") {
+		if keyword = strings.ToUpper(strings.TrimSpace(keyword)); keyword != "" {
+			detect = append(detect, keyword)
+		}
+	}
+	return detect, body
+}
+
+// DetectBank returns the name of the template whose keywords occur most
+// often in the statement text, or "default" when none occur
+func (l *PromptLibrary) DetectBank(statementText string) string {
+	text := strings.ToUpper(statementText)
+
+	names := make([]string, 0, len(l.templates))
+	for name := range l.templates {
+		if name != classificationPromptName {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	best, bestScore := defaultPromptName, 0
+	for _, name := range names {
+		score := 0
+		for _, keyword := range l.templates[name].detect {
+			score += strings.Count(text, keyword)
+		}
+		if score > bestScore {
+			best, bestScore = name, score
+		}
+	}
+	return best
+}
+
+// Render builds the prompt for the statement text using the template of its
+// detected bank
+func (l *PromptLibrary) Render(statementText string) (Prompt, error) {
+	t := l.templates[l.DetectBank(statementText)]
+
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, struct{ Text string }{statementText}); err != nil {
+		return Prompt{}, fmt.Errorf("failed to render prompt %s: %w", t.id(), err)
+	}
+	return Prompt{Text: strings.TrimSpace(buf.String()), Version: t.id()}, nil
+}
+
+// RenderClassification builds the prompt asking for one of categories for
+// each of the numbered transaction lines
+func (l *PromptLibrary) RenderClassification(categories []string, transactions string) (Prompt, error) {
+	t, ok := l.templates[classificationPromptName]
+	if !ok {
+		t = defaultPromptLibrary.templates[classificationPromptName]
+	}
+
+	var buf bytes.Buffer
+	data := struct{ Categories, Transactions string }{strings.Join(categories, ", "), transactions}
+	if err := t.tmpl.Execute(&buf, data); err != nil {
+		return Prompt{}, fmt.Errorf("failed to render prompt %s: %w", t.id(), err)
+	}
+	return Prompt{Text: strings.TrimSpace(buf.String()), Version: t.id()}, nil
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestDefaultPromptLibrary_DetectBank(t *testing.T) {
+	library := DefaultPromptLibrary()
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"KTC", "KTC CREDIT CARD STATEMENT\nPAYMENT VIA SCB EASY", "ktc"},
+		{"SCB", "SIAM COMMERCIAL BANK\nSCB CREDIT CARD", "scb"},
+		{"KBank Thai", "บัตรเครดิตกสิกรไทย", "kbank"},
+		{"unknown", "SOME OTHER BANK", "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := library.DetectBank(tt.text); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDefaultPromptLibrary_Render(t *testing.T) {
+	prompt, err := DefaultPromptLibrary().Render("KTC STATEMENT {{.Text}}")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if prompt.Version != "ktc.v1" {
+		t.Errorf("expected version ktc.v1, got %s", prompt.Version)
+	}
+	if !strings.HasSuffix(prompt.Text, "Bank Statement Text:\nKTC STATEMENT {{.Text}}") {
+		t.Errorf("expected prompt to end with the literal statement text, got:\n%s", prompt.Text)
+	}
+	if !strings.Contains(prompt.Text, "Rules for each transaction:") {
+		t.Error("expected shared rules to be included")
+	}
+}
+
+func TestLoadPromptLibrary(t *testing.T) {
+	fsys := fstest.MapFS{
+		"shared.tmpl":     {Data: []byte(`{{define "text"}}TEXT: {{.Text}}{{end}}`)},
+		"default.v1.tmpl": {Data: []byte(`default {{template "text" .}}`)},
+		"ktc.v2.tmpl":     {Data: []byte("detect: KTC\n---\nktc v2 {{template \"text\" .}}")},
+		"ktc.v10.tmpl":    {Data: []byte("detect: KTC\n---\nktc v10 {{template \"text\" .}}")},
+		"notes.txt":       {Data: []byte("ignored")},
+	}
+
+	library, err := LoadPromptLibrary(fsys)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	prompt, err := library.Render("KTC")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if prompt.Version != "ktc.v10" || prompt.Text != "ktc v10 TEXT: KTC" {
+		t.Errorf("expected latest KTC template, got %s: %q", prompt.Version, prompt.Text)
+	}
+
+	prompt, _ = library.Render("OTHER")
+	if prompt.Version != "default.v1" || prompt.Text != "default TEXT: OTHER" {
+		t.Errorf("expected default template, got %s: %q", prompt.Version, prompt.Text)
+	}
+}
+
+func TestPromptLibrary_RenderClassification(t *testing.T) {
+	prompt, err := DefaultPromptLibrary().RenderClassification([]string{"food", "other"}, "0. GRAB (120.00)")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if prompt.Version != "classification.v1" || !strings.Contains(prompt.Text, "categories: food, other.") || !strings.HasSuffix(prompt.Text, "0. GRAB (120.00)") {
+		t.Errorf("unexpected built-in prompt %s:\n%s", prompt.Version, prompt.Text)
+	}
+
+	library, err := LoadPromptLibrary(fstest.MapFS{
+		"default.v1.tmpl":        {Data: []byte("default")},
+		"classification.v2.tmpl": {Data: []byte("pick {{.Categories}} for {{.Transactions}}")},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	prompt, err = library.RenderClassification([]string{"food"}, "0. GRAB")
+	if err != nil || prompt.Version != "classification.v2" || prompt.Text != "pick food for 0. GRAB" {
+		t.Errorf("expected the overriding template, got %+v, %v", prompt, err)
+	}
+	if got := library.DetectBank("CLASSIFICATION"); got != "default" {
+		t.Errorf("expected the classification template never to be detected, got %s", got)
+	}
+}
+
+func TestLoadPromptLibrary_Errors(t *testing.T) {
+	if _, err := LoadPromptLibrary(fstest.MapFS{"ktc.v1.tmpl": {Data: []byte("ktc")}}); err == nil {
+		t.Error("expected error without a default template")
+	}
+	if _, err := LoadPromptLibrary(fstest.MapFS{"default.v1.tmpl": {Data: []byte("{{.Text")}}); err == nil {
+		t.Error("expected error for invalid template")
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tsongpon/helios/internal/model"
+)
+
+// apiKeyDisplayLength is how much of a key is kept in plain text so users
+// can recognise their keys
+const apiKeyDisplayLength = len(model.APIKeyPrefix) + 6
+
+// lastUsedInterval limits how often LastUsedAt is written for a busy key
+const lastUsedInterval = time.Minute
+
+// APIKeyService issues, lists, revokes and authenticates API keys
+type APIKeyService struct {
+	apiKeyRepository APIKeyRepository
+}
+
+func NewAPIKeyService(apiKeyRepository APIKeyRepository) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepository: apiKeyRepository,
+	}
+}
+
+// CreateAPIKey issues a new key for userID limited to scopes. The returned
+// secret is the only copy of the key; just its hash is stored.
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, userID, name string, scopes []model.Scope) (model.APIKey, string, error) {
+	if len(scopes) == 0 {
+		return model.APIKey{}, "", fmt.Errorf("%w: at least one scope is required", model.ErrInvalidScope)
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(model.Scopes, scope) {
+			return model.APIKey{}, "", fmt.Errorf("%w: %q", model.ErrInvalidScope, scope)
+		}
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return model.APIKey{}, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	secret := model.APIKeyPrefix + base64.RawURLEncoding.EncodeToString(random)
+
+	key := model.APIKey{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Name:      strings.TrimSpace(name),
+		Prefix:    secret[:apiKeyDisplayLength],
+		Hash:      hashAPIKey(secret),
+		Scopes:    slices.Compact(slices.Sorted(slices.Values(scopes))),
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.apiKeyRepository.Save(ctx, key); err != nil {
+		return model.APIKey{}, "", fmt.Errorf("failed to save API key: %w", err)
+	}
+
+	return key, secret, nil
+}
+
+func (s *APIKeyService) GetAPIKeys(ctx context.Context, userID string) ([]model.APIKey, error) {
+	return s.apiKeyRepository.GetAPIKeys(ctx, userID)
+}
+
+// RevokeAPIKey permanently disables the key if it belongs to userID
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, userID, keyID string) error {
+	key, err := s.apiKeyRepository.GetAPIKey(ctx, keyID)
+	if err != nil {
+		return err
+	}
+	if key.UserID != userID {
+		return model.ErrNotFound
+	}
+	if key.Revoked() {
+		return nil
+	}
+
+	if err := s.apiKeyRepository.Revoke(ctx, key.ID, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	return nil
+}
+
+// Authenticate resolves an API key to its owner, limited to the key's scopes
+func (s *APIKeyService) Authenticate(ctx context.Context, secret string) (model.Principal, error) {
+	if !strings.HasPrefix(secret, model.APIKeyPrefix) {
+		return model.Principal{}, fmt.Errorf("%w: not an API key", model.ErrUnauthorized)
+	}
+
+	key, err := s.apiKeyRepository.GetAPIKeyByHash(ctx, hashAPIKey(secret))
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return model.Principal{}, fmt.Errorf("%w: unknown API key", model.ErrUnauthorized)
+		}
+		return model.Principal{}, err
+	}
+	if key.Revoked() {
+		return model.Principal{}, fmt.Errorf("%w: API key %s has been revoked", model.ErrUnauthorized, key.ID)
+	}
+
+	// Only last_used_at is written, so a revocation saved since the key was
+	// read is never overwritten
+	now := time.Now().UTC()
+	if now.Sub(key.LastUsedAt) >= lastUsedInterval {
+		if err := s.apiKeyRepository.UpdateLastUsedAt(ctx, key.ID, now); err != nil {
+			log.Printf("failed to update last use of API key %s: %v", key.ID, err)
+		}
+	}
+
+	return model.Principal{UserID: key.UserID, APIKeyID: key.ID, Scopes: key.Scopes}, nil
+}
+
+// hashAPIKey returns the hex-encoded SHA-256 of the key. Keys carry 256 bits
+// of randomness, so a fast unsalted hash is enough to make the stored value
+// useless for authentication.
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+type mockAPIKeyRepository struct {
+	keys    map[string]model.APIKey
+	saves   int
+	updates int
+	// afterGet runs after a key is read by hash, before it is used
+	afterGet func()
+}
+
+func (m *mockAPIKeyRepository) Save(ctx context.Context, key model.APIKey) error {
+	if m.keys == nil {
+		m.keys = map[string]model.APIKey{}
+	}
+	m.keys[key.ID] = key
+	m.saves++
+	return nil
+}
+
+func (m *mockAPIKeyRepository) GetAPIKey(ctx context.Context, keyID string) (model.APIKey, error) {
+	key, ok := m.keys[keyID]
+	if !ok {
+		return model.APIKey{}, model.ErrNotFound
+	}
+	return key, nil
+}
+
+func (m *mockAPIKeyRepository) GetAPIKeyByHash(ctx context.Context, hash string) (model.APIKey, error) {
+	for _, key := range m.keys {
+		if key.Hash == hash {
+			if m.afterGet != nil {
+				m.afterGet()
+			}
+			return key, nil
+		}
+	}
+	return model.APIKey{}, model.ErrNotFound
+}
+
+func (m *mockAPIKeyRepository) UpdateLastUsedAt(ctx context.Context, keyID string, usedAt time.Time) error {
+	key, ok := m.keys[keyID]
+	if !ok {
+		return model.ErrNotFound
+	}
+	key.LastUsedAt = usedAt
+	m.keys[keyID] = key
+	m.updates++
+	return nil
+}
+
+func (m *mockAPIKeyRepository) Revoke(ctx context.Context, keyID string, revokedAt time.Time) error {
+	key, ok := m.keys[keyID]
+	if !ok {
+		return model.ErrNotFound
+	}
+	key.RevokedAt = revokedAt
+	m.keys[keyID] = key
+	return nil
+}
+
+func (m *mockAPIKeyRepository) GetAPIKeys(ctx context.Context, userID string) ([]model.APIKey, error) {
+	var keys []model.APIKey
+	for _, key := range m.keys {
+		if key.UserID == userID {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func TestAPIKeyService_CreateAPIKey(t *testing.T) {
+	t.Run("stores only the hash of the key", func(t *testing.T) {
+		repo := &mockAPIKeyRepository{}
+		svc := NewAPIKeyService(repo)
+
+		key, secret, err := svc.CreateAPIKey(context.Background(), "user123", " home assistant ", []model.Scope{model.ScopeStatementsWrite, model.ScopeStatementsWrite})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !strings.HasPrefix(secret, model.APIKeyPrefix) || !strings.HasPrefix(secret, key.Prefix) {
+			t.Errorf("expected secret %q to start with %q", secret, key.Prefix)
+		}
+		stored := repo.keys[key.ID]
+		if stored.Hash == "" || strings.Contains(stored.Hash, secret) {
+			t.Errorf("expected a hash of the secret to be stored, got %q", stored.Hash)
+		}
+		if stored.Name != "home assistant" || stored.UserID != "user123" {
+			t.Errorf("unexpected stored key %+v", stored)
+		}
+		if len(stored.Scopes) != 1 {
+			t.Errorf("expected duplicate scopes to be removed, got %v", stored.Scopes)
+		}
+	})
+
+	t.Run("rejects missing and unknown scopes", func(t *testing.T) {
+		svc := NewAPIKeyService(&mockAPIKeyRepository{})
+		for _, scopes := range [][]model.Scope{nil, {"statements:admin"}} {
+			if _, _, err := svc.CreateAPIKey(context.Background(), "user123", "script", scopes); !errors.Is(err, model.ErrInvalidScope) {
+				t.Errorf("%v: expected model.ErrInvalidScope, got %v", scopes, err)
+			}
+		}
+	})
+}
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	repo := &mockAPIKeyRepository{}
+	svc := NewAPIKeyService(repo)
+	key, secret, err := svc.CreateAPIKey(context.Background(), "user123", "script", []model.Scope{model.ScopeTransactionsRead})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	t.Run("resolves the owner and scopes", func(t *testing.T) {
+		principal, err := svc.Authenticate(context.Background(), secret)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if principal.UserID != "user123" || principal.APIKeyID != key.ID {
+			t.Errorf("unexpected principal %+v", principal)
+		}
+		if !principal.HasScope(model.ScopeTransactionsRead) || principal.HasScope(model.ScopeStatementsWrite) {
+			t.Errorf("expected only transactions:read, got %v", principal.Scopes)
+		}
+		if repo.keys[key.ID].LastUsedAt.IsZero() {
+			t.Error("expected last use to be recorded")
+		}
+	})
+
+	t.Run("records last use at most once a minute", func(t *testing.T) {
+		updates := repo.updates
+		if _, err := svc.Authenticate(context.Background(), secret); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if repo.updates != updates {
+			t.Errorf("expected no update, got %d", repo.updates-updates)
+		}
+	})
+
+	t.Run("rejects unknown keys", func(t *testing.T) {
+		for _, secret := range []string{model.APIKeyPrefix + "unknown", "eyJhbGciOiJSUzI1NiJ9"} {
+			if _, err := svc.Authenticate(context.Background(), secret); !errors.Is(err, model.ErrUnauthorized) {
+				t.Errorf("%q: expected ErrUnauthorized, got %v", secret, err)
+			}
+		}
+	})
+
+	t.Run("rejects revoked keys", func(t *testing.T) {
+		if err := svc.RevokeAPIKey(context.Background(), "user123", key.ID); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !repo.keys[key.ID].Revoked() {
+			t.Errorf("expected key to be revoked, got %+v", repo.keys[key.ID])
+		}
+		if _, err := svc.Authenticate(context.Background(), secret); !errors.Is(err, model.ErrUnauthorized) {
+			t.Errorf("expected ErrUnauthorized, got %v", err)
+		}
+	})
+}
+
+func TestAPIKeyService_RevokeAPIKey(t *testing.T) {
+	repo := &mockAPIKeyRepository{keys: map[string]model.APIKey{
+		"key-1": {ID: "key-1", UserID: "user123"},
+	}}
+	svc := NewAPIKeyService(repo)
+
+	if err := svc.RevokeAPIKey(context.Background(), "someone-else", "key-1"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if repo.keys["key-1"].Revoked() {
+		t.Error("expected another user's key not to be revoked")
+	}
+}
+
+func TestAPIKeyService_Authenticate_KeepsConcurrentRevocation(t *testing.T) {
+	repo := &mockAPIKeyRepository{}
+	svc := NewAPIKeyService(repo)
+	key, secret, err := svc.CreateAPIKey(context.Background(), "user123", "script", []model.Scope{model.ScopeTransactionsRead})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// The key is revoked after Authenticate has read it
+	repo.afterGet = func() {
+		repo.afterGet = nil
+		if err := svc.RevokeAPIKey(context.Background(), "user123", key.ID); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if _, err := svc.Authenticate(context.Background(), secret); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !repo.keys[key.ID].Revoked() || repo.keys[key.ID].LastUsedAt.IsZero() {
+		t.Errorf("expected the key to stay revoked with its last use recorded, got %+v", repo.keys[key.ID])
+	}
+	if _, err := svc.Authenticate(context.Background(), secret); !errors.Is(err, model.ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized, got %v", err)
+	}
+}
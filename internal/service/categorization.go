@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tsongpon/helios/internal/model"
+)
+
+// categorizationBatchSize is the number of transactions sent to the
+// classifier in one call
+const categorizationBatchSize = 50
+
+// CategorizationService assigns categories to transactions, first with the
+// user's rules and then with the LLM classifier
+type CategorizationService struct {
+	ruleRepository        CategoryRuleRepository
+	transactionRepository TransactionRepository
+	classifier            TransactionClassifier
+	redactor              *PIIRedactor
+	rules                 TransactionRuleApplier
+}
+
+// NewCategorizationService creates a new CategorizationService; with a nil
+// classifier only the user's rules are applied
+func NewCategorizationService(ruleRepository CategoryRuleRepository, transactionRepository TransactionRepository, classifier TransactionClassifier) *CategorizationService {
+	return &CategorizationService{
+		ruleRepository:        ruleRepository,
+		transactionRepository: transactionRepository,
+		classifier:            classifier,
+	}
+}
+
+// WithRedaction masks personal data in descriptions, such as the payee of a
+// transfer, before they are sent to the classifier
+func (s *CategorizationService) WithRedaction(redactor *PIIRedactor) *CategorizationService {
+	s.redactor = redactor
+	return s
+}
+
+// WithRules applies the user's rules before categorizing when transactions
+// are recategorized, so categories set by rules are kept rather than replaced
+// by category rules or the classifier, as on upload
+func (s *CategorizationService) WithRules(rules TransactionRuleApplier) *CategorizationService {
+	s.rules = rules
+	return s
+}
+
+func (s *CategorizationService) GetCategories() []string {
+	return slices.Clone(model.Categories)
+}
+
+// CreateCategoryRule validates and saves a rule for userID
+func (s *CategorizationService) CreateCategoryRule(ctx context.Context, userID string, rule model.CategoryRule) (model.CategoryRule, error) {
+	rule.Pattern = strings.TrimSpace(rule.Pattern)
+	rule.Category = strings.ToLower(strings.TrimSpace(rule.Category))
+	if rule.Pattern == "" {
+		return model.CategoryRule{}, fmt.Errorf("%w: pattern is required", model.ErrInvalidCategoryRule)
+	}
+	if !slices.Contains(model.Categories, rule.Category) {
+		return model.CategoryRule{}, fmt.Errorf("%w: unknown category %q", model.ErrInvalidCategoryRule, rule.Category)
+	}
+	if _, err := rule.Matcher(); err != nil {
+		return model.CategoryRule{}, fmt.Errorf("%w: %v", model.ErrInvalidCategoryRule, err)
+	}
+
+	rule.ID = uuid.NewString()
+	rule.UserID = userID
+	rule.CreatedAt = time.Now().UTC()
+	if err := s.ruleRepository.Save(ctx, rule); err != nil {
+		return model.CategoryRule{}, fmt.Errorf("failed to save category rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+func (s *CategorizationService) GetCategoryRules(ctx context.Context, userID string) ([]model.CategoryRule, error) {
+	return s.ruleRepository.GetCategoryRules(ctx, userID)
+}
+
+// DeleteCategoryRule removes a rule the user owns. Categories it already
+// assigned are kept until the transactions are recategorized.
+func (s *CategorizationService) DeleteCategoryRule(ctx context.Context, userID, ruleID string) error {
+	rule, err := s.ruleRepository.GetCategoryRule(ctx, ruleID)
+	if err != nil {
+		return err
+	}
+	if rule.UserID != userID {
+		return model.ErrNotFound
+	}
+
+	if err := s.ruleRepository.Delete(ctx, ruleID); err != nil {
+		return fmt.Errorf("failed to delete category rule: %w", err)
+	}
+	return nil
+}
+
+// Categorize assigns a category to every transaction that has none and whose
+// category the user did not set. The user's rules are tried first, oldest
+// first, and the rest go to the classifier. It returns the number of
+// transactions categorized, which are updated even when an error is returned.
+func (s *CategorizationService) Categorize(ctx context.Context, userID string, transactions []model.Transaction) (int, error) {
+	matchers, err := s.categoryMatchers(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return s.categorize(ctx, matchers, transactions)
+}
+
+// Recategorize runs the user's rules and categorization over the saved
+// transactions that match query and saves the ones that changed. With
+// overwrite, categories assigned earlier by rules or the classifier are
+// replaced too; categories set by the user never are. It returns the number
+// of transactions updated.
+func (s *CategorizationService) Recategorize(ctx context.Context, userID string, query model.TransactionQuery, overwrite bool) (int, error) {
+	matchers, err := s.categoryMatchers(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	query.Limit = model.MaxTransactionPageSize
+	query.Cursor = ""
+	updated := 0
+	for {
+		page, err := s.transactionRepository.GetTransactions(ctx, userID, query)
+		if err != nil {
+			return updated, fmt.Errorf("failed to get transactions: %w", err)
+		}
+
+		previous := make([]model.Transaction, len(page.Transactions))
+		for i := range page.Transactions {
+			previous[i] = page.Transactions[i]
+			previous[i].Tags = slices.Clone(page.Transactions[i].Tags)
+			if overwrite && !categorySetByUser(page.Transactions[i]) {
+				page.Transactions[i].Category = ""
+			}
+		}
+		if s.rules != nil {
+			if err := s.rules.ApplyRules(ctx, userID, page.Transactions); err != nil {
+				return updated, err
+			}
+		}
+		if _, err := s.categorize(ctx, matchers, page.Transactions); err != nil {
+			return updated, err
+		}
+
+		var changed []model.Transaction
+		for i, t := range page.Transactions {
+			before := previous[i]
+			if t.Category != before.Category || t.IsBusiness != before.IsBusiness || !slices.Equal(t.Tags, before.Tags) {
+				changed = append(changed, t)
+			}
+		}
+		if len(changed) > 0 {
+			if err := s.transactionRepository.Save(ctx, changed); err != nil {
+				return updated, fmt.Errorf("failed to save transactions: %w", err)
+			}
+			updated += len(changed)
+		}
+
+		if page.NextCursor == "" {
+			return updated, nil
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+type categoryMatcher struct {
+	category string
+	match    func(description string) bool
+}
+
+func (s *CategorizationService) categoryMatchers(ctx context.Context, userID string) ([]categoryMatcher, error) {
+	rules, err := s.ruleRepository.GetCategoryRules(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category rules: %w", err)
+	}
+
+	matchers := make([]categoryMatcher, 0, len(rules))
+	for _, rule := range rules {
+		match, err := rule.Matcher()
+		if err != nil {
+			log.Printf("skipping invalid category rule %s: %v", rule.ID, err)
+			continue
+		}
+		matchers = append(matchers, categoryMatcher{category: rule.Category, match: match})
+	}
+	return matchers, nil
+}
+
+// categorySetByUser reports whether the user chose the transaction's
+// category, by editing it or when entering a manual transaction; such a
+// category is never replaced automatically
+func categorySetByUser(t model.Transaction) bool {
+	return t.Overrides.Category != nil || (t.Source == model.TransactionSourceManual && t.Category != "")
+}
+
+func (s *CategorizationService) categorize(ctx context.Context, matchers []categoryMatcher, transactions []model.Transaction) (int, error) {
+	categorized := 0
+	var pending []int
+	for i := range transactions {
+		t := &transactions[i]
+		if t.Category != "" || t.Overrides.Category != nil {
+			continue
+		}
+		rule := slices.IndexFunc(matchers, func(m categoryMatcher) bool { return m.match(t.Description) })
+		if rule >= 0 {
+			t.Category = matchers[rule].category
+			categorized++
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	if s.classifier == nil {
+		return categorized, nil
+	}
+
+	for batch := range slices.Chunk(pending, categorizationBatchSize) {
+		input := make([]model.Transaction, len(batch))
+		for j, i := range batch {
+			input[j] = transactions[i]
+			if s.redactor != nil {
+				input[j].Description, _ = s.redactor.Redact(input[j].Description)
+			}
+		}
+
+		categories, err := s.classifier.ClassifyTransactions(ctx, input, model.Categories)
+		if err != nil {
+			return categorized, fmt.Errorf("failed to classify transactions: %w", err)
+		}
+		for j, category := range categories {
+			if j < len(batch) && category != "" {
+				transactions[batch[j]].Category = category
+				categorized++
+			}
+		}
+	}
+
+	return categorized, nil
+}
+
+// preserveCategories carries the category of previously saved transactions
+// over to freshly parsed ones with the same ID, so reparsing a statement does
+// not classify it again
+func preserveCategories(transactions []model.Transaction, previous []model.Transaction) {
+	categories := make(map[string]string)
+	for _, t := range previous {
+		if t.Category != "" {
+			categories[t.ID] = t.Category
+		}
+	}
+	for i := range transactions {
+		if transactions[i].Category == "" && transactions[i].Overrides.Category == nil {
+			transactions[i].Category = categories[transactions[i].ID]
+		}
+	}
+}
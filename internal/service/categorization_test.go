@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+type mockCategoryRuleRepository struct {
+	rules     []model.CategoryRule
+	deletedID string
+}
+
+func (m *mockCategoryRuleRepository) Save(ctx context.Context, rule model.CategoryRule) error {
+	m.rules = append(m.rules, rule)
+	return nil
+}
+
+func (m *mockCategoryRuleRepository) GetCategoryRule(ctx context.Context, ruleID string) (model.CategoryRule, error) {
+	for _, rule := range m.rules {
+		if rule.ID == ruleID {
+			return rule, nil
+		}
+	}
+	return model.CategoryRule{}, model.ErrNotFound
+}
+
+func (m *mockCategoryRuleRepository) GetCategoryRules(ctx context.Context, userID string) ([]model.CategoryRule, error) {
+	return m.rules, nil
+}
+
+func (m *mockCategoryRuleRepository) Delete(ctx context.Context, ruleID string) error {
+	m.deletedID = ruleID
+	return nil
+}
+
+// mockClassifier answers category for every transaction and records the
+// descriptions of each call
+type mockClassifier struct {
+	category string
+	err      error
+	calls    [][]string
+}
+
+func (m *mockClassifier) ClassifyTransactions(ctx context.Context, transactions []model.Transaction, categories []string) ([]string, error) {
+	descriptions := make([]string, len(transactions))
+	result := make([]string, len(transactions))
+	for i, t := range transactions {
+		descriptions[i] = t.Description
+		result[i] = m.category
+	}
+	m.calls = append(m.calls, descriptions)
+	if m.err != nil {
+		return nil, m.err
+	}
+	return result, nil
+}
+
+func TestCategorizationService_CreateCategoryRule(t *testing.T) {
+	tests := []struct {
+		name string
+		rule model.CategoryRule
+		ok   bool
+	}{
+		{"keyword", model.CategoryRule{Pattern: " grab ", Category: "Transport"}, true},
+		{"regex", model.CategoryRule{Pattern: `^BTS|MRT`, IsRegex: true, Category: "transport"}, true},
+		{"missing pattern", model.CategoryRule{Category: "food"}, false},
+		{"unknown category", model.CategoryRule{Pattern: "grab", Category: "rides"}, false},
+		{"invalid regex", model.CategoryRule{Pattern: "(grab", IsRegex: true, Category: "transport"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockCategoryRuleRepository{}
+			service := NewCategorizationService(repo, &mockTransactionRepository{}, nil)
+
+			rule, err := service.CreateCategoryRule(context.Background(), "user-1", tt.rule)
+			if !tt.ok {
+				if !errors.Is(err, model.ErrInvalidCategoryRule) {
+					t.Errorf("expected model.ErrInvalidCategoryRule, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if rule.ID == "" || rule.UserID != "user-1" || rule.Category != "transport" || len(repo.rules) != 1 {
+				t.Errorf("unexpected rule: %+v", rule)
+			}
+		})
+	}
+}
+
+func TestCategorizationService_DeleteCategoryRule(t *testing.T) {
+	repo := &mockCategoryRuleRepository{rules: []model.CategoryRule{{ID: "rule-1", UserID: "user-1"}}}
+	service := NewCategorizationService(repo, &mockTransactionRepository{}, nil)
+
+	if err := service.DeleteCategoryRule(context.Background(), "user-2", "rule-1"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for another user's rule, got %v", err)
+	}
+	if err := service.DeleteCategoryRule(context.Background(), "user-1", "rule-1"); err != nil || repo.deletedID != "rule-1" {
+		t.Errorf("expected rule to be deleted, got %v", err)
+	}
+}
+
+func TestCategorizationService_Categorize(t *testing.T) {
+	rules := &mockCategoryRuleRepository{rules: []model.CategoryRule{
+		{ID: "rule-1", Pattern: "grab", Category: "transport"},
+		{ID: "rule-2", Pattern: `^GRAB\s*FOOD`, IsRegex: true, Category: "food"},
+	}}
+	classifier := &mockClassifier{category: "shopping"}
+	service := NewCategorizationService(rules, &mockTransactionRepository{}, classifier).WithRedaction(NewPIIRedactor())
+
+	userCategory := ""
+	transactions := []model.Transaction{
+		{Description: "GrabFood Bangkok"},
+		{Description: "LAZADA"},
+		{Description: "STARBUCKS", Category: "food"},
+		{Description: "TRANSFER MR JOHN SMITH"},
+		{Description: "CENTRAL", Overrides: model.TransactionPatch{Category: &userCategory}},
+	}
+
+	categorized, err := service.Categorize(context.Background(), "user-1", transactions)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := []string{"transport", "shopping", "food", "shopping", ""}
+	for i, category := range want {
+		if transactions[i].Category != category {
+			t.Errorf("transaction %d: expected category %q, got %q", i, category, transactions[i].Category)
+		}
+	}
+	if categorized != 3 {
+		t.Errorf("expected 3 categorized transactions, got %d", categorized)
+	}
+	if len(classifier.calls) != 1 || len(classifier.calls[0]) != 2 {
+		t.Fatalf("expected one classifier call with 2 transactions, got %v", classifier.calls)
+	}
+	if classifier.calls[0][1] == "TRANSFER MR JOHN SMITH" {
+		t.Errorf("expected payee name to be redacted before classification")
+	}
+}
+
+func TestCategorizationService_Categorize_Batches(t *testing.T) {
+	classifier := &mockClassifier{category: "other"}
+	service := NewCategorizationService(&mockCategoryRuleRepository{}, &mockTransactionRepository{}, classifier)
+
+	transactions := make([]model.Transaction, categorizationBatchSize+10)
+	for i := range transactions {
+		transactions[i].Description = fmt.Sprintf("SHOP %d", i)
+	}
+
+	if _, err := service.Categorize(context.Background(), "user-1", transactions); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(classifier.calls) != 2 || len(classifier.calls[1]) != 10 {
+		t.Errorf("expected two batches, got %d calls", len(classifier.calls))
+	}
+
+	classifier = &mockClassifier{err: errors.New("LLM down")}
+	service = NewCategorizationService(&mockCategoryRuleRepository{}, &mockTransactionRepository{}, classifier)
+	if _, err := service.Categorize(context.Background(), "user-1", []model.Transaction{{Description: "SHOP"}}); err == nil {
+		t.Errorf("expected classifier error")
+	}
+}
+
+func TestCategorizationService_Recategorize(t *testing.T) {
+	userCategory := "travel"
+	transactionRepo := &mockTransactionRepository{transactions: []model.Transaction{
+		{ID: "t1", Description: "GRAB", Category: "other"},
+		{ID: "t2", Description: "AGODA", Category: "travel", Overrides: model.TransactionPatch{Category: &userCategory}},
+		{ID: "t3", Description: "LAZADA", Category: "shopping"},
+	}}
+	rules := &mockCategoryRuleRepository{rules: []model.CategoryRule{{Pattern: "grab", Category: "transport"}}}
+	classifier := &mockClassifier{category: "shopping"}
+	service := NewCategorizationService(rules, transactionRepo, classifier)
+
+	updated, err := service.Recategorize(context.Background(), "user-1", model.TransactionQuery{}, false)
+	if err != nil || updated != 0 || len(classifier.calls) != 0 {
+		t.Errorf("expected categorized transactions to be left alone, got %d updated, err %v", updated, err)
+	}
+
+	updated, err = service.Recategorize(context.Background(), "user-1", model.TransactionQuery{}, true)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if updated != 1 || len(transactionRepo.savedTxns) != 1 || transactionRepo.savedTxns[0].ID != "t1" || transactionRepo.savedTxns[0].Category != "transport" {
+		t.Errorf("expected only t1 to change, got %d updated: %+v", updated, transactionRepo.savedTxns)
+	}
+	if transactionRepo.query.Limit != model.MaxTransactionPageSize {
+		t.Errorf("expected pages of %d, got %d", model.MaxTransactionPageSize, transactionRepo.query.Limit)
+	}
+}
+
+func TestCategorizationService_Recategorize_AppliesRules(t *testing.T) {
+	transactionRepo := &mockTransactionRepository{transactions: []model.Transaction{
+		{ID: "t1", Merchant: "Grab", Description: "GRAB", Category: "travel"},
+		{ID: "t2", Merchant: "Lazada", Description: "LAZADA", Category: "other"},
+		{ID: "t3", Merchant: "Grab", Description: "GRAB", Category: "food", Source: model.TransactionSourceManual},
+	}}
+	categoryRules := &mockCategoryRuleRepository{rules: []model.CategoryRule{{Pattern: "grab", Category: "transport"}}}
+	rules := &mockRuleRepository{rules: []model.Rule{{
+		ID:        "rule-1",
+		Condition: `merchant == "Grab"`,
+		Actions:   model.RuleActions{SetCategory: "travel", AddTags: []string{"work"}},
+	}}}
+	service := NewCategorizationService(categoryRules, transactionRepo, &mockClassifier{category: "shopping"}).
+		WithRules(NewRuleService(rules, transactionRepo))
+
+	updated, err := service.Recategorize(context.Background(), "user-1", model.TransactionQuery{}, true)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	saved := transactionRepo.savedTxns
+	if updated != 3 || len(saved) != 3 {
+		t.Fatalf("expected 3 updated transactions, got %d: %+v", updated, saved)
+	}
+	if saved[0].Category != "travel" || !slices.Equal(saved[0].Tags, []string{"work"}) {
+		t.Errorf("expected the rule's category and tag to win over the category rule, got %+v", saved[0])
+	}
+	if saved[1].Category != "shopping" {
+		t.Errorf("expected the classifier to categorize the rest, got %+v", saved[1])
+	}
+	if saved[2].Category != "food" || !slices.Equal(saved[2].Tags, []string{"work"}) {
+		t.Errorf("expected the rule to tag the manual transaction but keep its category, got %+v", saved[2])
+	}
+}
+
+func TestCategorizationService_Recategorize_KeepsManualCategories(t *testing.T) {
+	transactionRepo := &mockTransactionRepository{transactions: []model.Transaction{
+		{ID: "t1", Description: "CASH LUNCH", Category: "food", Source: model.TransactionSourceManual},
+		{ID: "t2", Description: "LAZADA", Category: "other", Source: model.TransactionSourceStatement},
+	}}
+	service := NewCategorizationService(&mockCategoryRuleRepository{}, transactionRepo, &mockClassifier{category: "shopping"})
+
+	updated, err := service.Recategorize(context.Background(), "user-1", model.TransactionQuery{}, true)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if updated != 1 || len(transactionRepo.savedTxns) != 1 || transactionRepo.savedTxns[0].ID != "t2" {
+		t.Fatalf("expected only the parsed transaction to be recategorized, got %d: %+v", updated, transactionRepo.savedTxns)
+	}
+	if transactionRepo.transactions[0].Category != "food" {
+		t.Errorf("expected the manual category to be kept, got %q", transactionRepo.transactions[0].Category)
+	}
+}
+
+func TestPreserveCategories(t *testing.T) {
+	transactions := []model.Transaction{{ID: "t1"}, {ID: "t2"}, {ID: "t3", Category: "food"}}
+	preserveCategories(transactions, []model.Transaction{
+		{ID: "t1", Category: "travel"},
+		{ID: "t3", Category: "shopping"},
+	})
+
+	if transactions[0].Category != "travel" || transactions[1].Category != "" || transactions[2].Category != "food" {
+		t.Errorf("unexpected categories: %+v", transactions)
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Supported text extractor names for NewTextExtractor
+const (
+	ExtractorAuto      = "auto"
+	ExtractorPDFToText = "pdftotext"
+	ExtractorNative    = "native"
+)
+
+// TextExtractor extracts plain text from a PDF document
+// password is optional - pass empty string for non-protected PDFs
+type TextExtractor interface {
+	Extract(ctx context.Context, pdf []byte, password string) (string, error)
+}
+
+// NewTextExtractor returns the TextExtractor registered under name.
+// "auto" (or an empty name) uses pdftotext when it is installed and
+// falls back to the pure-Go extractor otherwise.
+func NewTextExtractor(name string) (TextExtractor, error) {
+	switch name {
+	case ExtractorPDFToText:
+		return NewPDFToTextExtractor(), nil
+	case ExtractorNative:
+		return NewNativeTextExtractor(), nil
+	case "", ExtractorAuto:
+		if _, err := exec.LookPath("pdftotext"); err == nil {
+			return NewPDFToTextExtractor(), nil
+		}
+		return NewNativeTextExtractor(), nil
+	default:
+		return nil, fmt.Errorf("unknown text extractor %q", name)
+	}
+}
+
+// runCommand runs an external command and returns its stdout,
+// using stderr as the error message when the command fails
+func runCommand(ctx context.Context, name string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if stderr.Len() > 0 {
+			return "", fmt.Errorf("%s", strings.TrimSpace(stderr.String()))
+		}
+		return "", err
+	}
+
+	return stdout.String(), nil
+}
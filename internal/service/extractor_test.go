@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNewTextExtractor(t *testing.T) {
+	t.Run("returns pdftotext extractor", func(t *testing.T) {
+		extractor, err := NewTextExtractor(ExtractorPDFToText)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if _, ok := extractor.(*PDFToTextExtractor); !ok {
+			t.Errorf("expected *PDFToTextExtractor, got %T", extractor)
+		}
+	})
+
+	t.Run("returns native extractor", func(t *testing.T) {
+		extractor, err := NewTextExtractor(ExtractorNative)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if _, ok := extractor.(*NativeTextExtractor); !ok {
+			t.Errorf("expected *NativeTextExtractor, got %T", extractor)
+		}
+	})
+
+	t.Run("auto selects an extractor", func(t *testing.T) {
+		for _, name := range []string{"", ExtractorAuto} {
+			extractor, err := NewTextExtractor(name)
+			if err != nil {
+				t.Fatalf("expected no error for %q, got %v", name, err)
+			}
+			if extractor == nil {
+				t.Fatalf("expected non-nil extractor for %q", name)
+			}
+		}
+	})
+
+	t.Run("returns error for unknown extractor", func(t *testing.T) {
+		_, err := NewTextExtractor("unknown")
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if err.Error() != `unknown text extractor "unknown"` {
+			t.Errorf("unexpected error message: %s", err.Error())
+		}
+	})
+}
+
+func TestNativeTextExtractor_Extract(t *testing.T) {
+	t.Run("returns error for non-PDF content", func(t *testing.T) {
+		extractor := NewNativeTextExtractor()
+
+		_, err := extractor.Extract(context.Background(), []byte("not a pdf"), "")
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tsongpon/helios/internal/model"
+)
+
+// ErrJobInterrupted is recorded on unfinished jobs whose upload was lost,
+// so they cannot be resumed
+var ErrJobInterrupted = errors.New("job was interrupted and its upload is no longer available, upload the statement again")
+
+// ErrJobPasswordNotStored is recorded on unfinished password-protected jobs,
+// which cannot be resumed because statement passwords are never stored
+var ErrJobPasswordNotStored = errors.New("job was interrupted and the statement password is not stored, upload the statement again")
+
+// StatementProcessor runs the statement pipeline for a single upload
+type StatementProcessor interface {
+	ProcessStatement(ctx context.Context, userID string, content []byte, password string, onStatus func(model.JobStatus)) (model.Statement, error)
+}
+
+type jobTask struct {
+	job      model.Job
+	content  []byte
+	password string
+}
+
+// JobService processes statement uploads asynchronously on a bounded worker pool
+type JobService struct {
+	processor     StatementProcessor
+	jobRepository JobRepository
+	workers       int
+	queue         chan jobTask
+	running       sync.WaitGroup
+}
+
+// NewJobService creates a new JobService with the given number of workers
+// and a queue holding up to queueSize pending uploads
+func NewJobService(processor StatementProcessor, jobRepository JobRepository, workers, queueSize int) *JobService {
+	return &JobService{
+		processor:     processor,
+		jobRepository: jobRepository,
+		workers:       workers,
+		queue:         make(chan jobTask, queueSize),
+	}
+}
+
+// Start launches the worker pool and resumes the jobs left unfinished when
+// the server last stopped; their uploads are kept until they finish, without
+// their passwords, so password-protected jobs fail instead. It
+// assumes a single server processes the jobs in the database. When ctx is
+// cancelled the job in progress is cancelled too and put back in the queue.
+func (s *JobService) Start(ctx context.Context) {
+	started := time.Now().UTC()
+	s.running.Add(s.workers + 1)
+	for i := 0; i < s.workers; i++ {
+		go func() {
+			defer s.running.Done()
+			s.work(ctx)
+		}()
+	}
+	go func() {
+		defer s.running.Done()
+		s.resume(ctx, started)
+	}()
+}
+
+// Wait blocks until the workers started by Start have stopped
+func (s *JobService) Wait() {
+	s.running.Wait()
+}
+
+// Submit stores the upload and a new queued job for it, and hands it to the
+// worker pool
+func (s *JobService) Submit(ctx context.Context, userID string, content []byte, password string) (model.Job, error) {
+	now := time.Now().UTC()
+	job := model.Job{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Status:    model.JobStatusQueued,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.jobRepository.SaveUpload(ctx, job.ID, model.JobUpload{Content: content, PasswordProtected: password != ""}); err != nil {
+		return model.Job{}, fmt.Errorf("failed to save upload: %w", err)
+	}
+	if err := s.jobRepository.Save(ctx, job); err != nil {
+		s.deleteUpload(ctx, job.ID)
+		return model.Job{}, fmt.Errorf("failed to save job: %w", err)
+	}
+
+	select {
+	case s.queue <- jobTask{job: job, content: content, password: password}:
+		return job, nil
+	default:
+		job.Status = model.JobStatusFailed
+		job.Error = model.ErrJobQueueFull.Error()
+		job.UpdatedAt = time.Now().UTC()
+		if err := s.jobRepository.Save(ctx, job); err != nil {
+			log.Printf("failed to update job %s: %v", job.ID, err)
+		}
+		s.deleteUpload(ctx, job.ID)
+		return model.Job{}, model.ErrJobQueueFull
+	}
+}
+
+// GetJob returns the job with the given ID if it belongs to userID
+func (s *JobService) GetJob(ctx context.Context, userID, jobID string) (model.Job, error) {
+	job, err := s.jobRepository.GetJob(ctx, jobID)
+	if err != nil {
+		return model.Job{}, err
+	}
+	if job.UserID != userID {
+		return model.Job{}, model.ErrNotFound
+	}
+	return job, nil
+}
+
+func (s *JobService) work(ctx context.Context) {
+	for {
+		// select picks at random when a task is also ready; queued jobs are
+		// resumed on the next start
+		if ctx.Err() != nil {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case task := <-s.queue:
+			s.process(ctx, task)
+		}
+	}
+}
+
+// resume queues the unfinished jobs created before startedAt, failing the
+// ones whose upload is missing or was password-protected
+func (s *JobService) resume(ctx context.Context, startedAt time.Time) {
+	jobs, err := s.jobRepository.GetUnfinishedJobs(ctx)
+	if err != nil {
+		log.Printf("failed to get unfinished jobs: %v", err)
+		return
+	}
+
+	fail := func(job model.Job, reason error) {
+		job.Status = model.JobStatusFailed
+		job.Error = reason.Error()
+		job.UpdatedAt = time.Now().UTC()
+		if err := s.jobRepository.Save(ctx, job); err != nil {
+			log.Printf("failed to update job %s: %v", job.ID, err)
+		}
+	}
+
+	for _, job := range jobs {
+		if !job.CreatedAt.Before(startedAt) {
+			continue
+		}
+		upload, err := s.jobRepository.GetUpload(ctx, job.ID)
+		if err != nil {
+			if !errors.Is(err, model.ErrNotFound) {
+				log.Printf("failed to get upload of job %s: %v", job.ID, err)
+				continue
+			}
+			fail(job, ErrJobInterrupted)
+			continue
+		}
+		if upload.PasswordProtected {
+			s.deleteUpload(ctx, job.ID)
+			fail(job, ErrJobPasswordNotStored)
+			continue
+		}
+
+		job.Status = model.JobStatusQueued
+		job.UpdatedAt = time.Now().UTC()
+		if err := s.jobRepository.Save(ctx, job); err != nil {
+			log.Printf("failed to update job %s: %v", job.ID, err)
+			continue
+		}
+		select {
+		case s.queue <- jobTask{job: job, content: upload.Content}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *JobService) process(ctx context.Context, task jobTask) {
+	job := task.job
+	// Status is saved even once ctx is cancelled on shutdown, so an
+	// interrupted job is not left processing
+	saveCtx := context.WithoutCancel(ctx)
+	update := func(status model.JobStatus) {
+		job.Status = status
+		job.UpdatedAt = time.Now().UTC()
+		if err := s.jobRepository.Save(saveCtx, job); err != nil {
+			log.Printf("failed to update job %s: %v", job.ID, err)
+		}
+	}
+
+	statement, err := s.processor.ProcessStatement(ctx, job.UserID, task.content, task.password, update)
+	if err != nil && ctx.Err() != nil {
+		// Shutting down; the job is resumed on the next start
+		update(model.JobStatusQueued)
+		return
+	}
+	if err != nil {
+		var duplicate *model.DuplicateStatementError
+		if errors.As(err, &duplicate) {
+			job.StatementID = duplicate.StatementID
+		}
+		job.Error = err.Error()
+		update(model.JobStatusFailed)
+	} else {
+		job.StatementID = statement.ID
+		job.Transactions = statement.Transactions
+		update(model.JobStatusDone)
+	}
+	s.deleteUpload(saveCtx, job.ID)
+}
+
+// deleteUpload removes the upload of a finished job; a failure only leaves
+// the file behind
+func (s *JobService) deleteUpload(ctx context.Context, jobID string) {
+	if err := s.jobRepository.DeleteUpload(ctx, jobID); err != nil {
+		log.Printf("failed to delete upload of job %s: %v", jobID, err)
+	}
+}
@@ -0,0 +1,364 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+type mockJobRepository struct {
+	mu      sync.Mutex
+	jobs    map[string]model.Job
+	uploads map[string]model.JobUpload
+	history []model.JobStatus
+	err     error
+}
+
+func newMockJobRepository() *mockJobRepository {
+	return &mockJobRepository{jobs: make(map[string]model.Job), uploads: make(map[string]model.JobUpload)}
+}
+
+func (m *mockJobRepository) Save(ctx context.Context, job model.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.jobs[job.ID] = job
+	m.history = append(m.history, job.Status)
+	return nil
+}
+
+func (m *mockJobRepository) GetJob(ctx context.Context, jobID string) (model.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[jobID]
+	if !ok {
+		return model.Job{}, model.ErrNotFound
+	}
+	return job, nil
+}
+
+func (m *mockJobRepository) GetUnfinishedJobs(ctx context.Context) ([]model.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var jobs []model.Job
+	for _, job := range m.jobs {
+		if job.Status != model.JobStatusDone && job.Status != model.JobStatusFailed {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func (m *mockJobRepository) SaveUpload(ctx context.Context, jobID string, upload model.JobUpload) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.uploads[jobID] = upload
+	return nil
+}
+
+func (m *mockJobRepository) GetUpload(ctx context.Context, jobID string) (model.JobUpload, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	upload, ok := m.uploads[jobID]
+	if !ok {
+		return model.JobUpload{}, model.ErrNotFound
+	}
+	return upload, nil
+}
+
+func (m *mockJobRepository) DeleteUpload(ctx context.Context, jobID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.uploads, jobID)
+	return nil
+}
+
+func (m *mockJobRepository) hasUpload(jobID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.uploads[jobID]
+	return ok
+}
+
+type mockStatementProcessor struct {
+	statement model.Statement
+	err       error
+	// started, when set, is signalled and processing blocks until ctx is done
+	started chan struct{}
+}
+
+func (m *mockStatementProcessor) ProcessStatement(ctx context.Context, userID string, content []byte, password string, onStatus func(model.JobStatus)) (model.Statement, error) {
+	onStatus(model.JobStatusExtracting)
+	if m.started != nil {
+		select {
+		case m.started <- struct{}{}:
+		default:
+		}
+		<-ctx.Done()
+		return model.Statement{}, ctx.Err()
+	}
+	if m.err != nil {
+		return model.Statement{}, m.err
+	}
+	onStatus(model.JobStatusParsing)
+	onStatus(model.JobStatusSaving)
+	return m.statement, nil
+}
+
+func waitForJob(t *testing.T, svc *JobService, userID, jobID string) model.Job {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := svc.GetJob(context.Background(), userID, jobID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if job.Status == model.JobStatusDone || job.Status == model.JobStatusFailed {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", jobID)
+	return model.Job{}
+}
+
+func TestJobService_Submit(t *testing.T) {
+	t.Run("processes job to completion", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		processor := &mockStatementProcessor{
+			statement: model.Statement{
+				ID:           "stmt-1",
+				Transactions: []model.Transaction{{Description: "AMAZON", Amount: 100.50}},
+			},
+		}
+		repo := newMockJobRepository()
+		svc := NewJobService(processor, repo, 1, 10)
+		svc.Start(ctx)
+
+		job, err := svc.Submit(ctx, "user123", []byte("fake pdf content"), "")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if job.ID == "" {
+			t.Fatal("expected job ID to be set")
+		}
+		if job.Status != model.JobStatusQueued {
+			t.Errorf("expected status queued, got %s", job.Status)
+		}
+
+		finished := waitForJob(t, svc, "user123", job.ID)
+		if finished.Status != model.JobStatusDone {
+			t.Fatalf("expected status done, got %s", finished.Status)
+		}
+		if finished.StatementID != "stmt-1" {
+			t.Errorf("expected statement ID stmt-1, got %s", finished.StatementID)
+		}
+		if repo.hasUpload(job.ID) {
+			t.Error("expected the upload to be deleted once the job finished")
+		}
+		if len(finished.Transactions) != 1 {
+			t.Fatalf("expected 1 transaction, got %d", len(finished.Transactions))
+		}
+
+		expected := []model.JobStatus{
+			model.JobStatusQueued,
+			model.JobStatusExtracting,
+			model.JobStatusParsing,
+			model.JobStatusSaving,
+			model.JobStatusDone,
+		}
+		repo.mu.Lock()
+		history := repo.history
+		repo.mu.Unlock()
+		if len(history) != len(expected) {
+			t.Fatalf("expected status history %v, got %v", expected, history)
+		}
+		for i := range expected {
+			if history[i] != expected[i] {
+				t.Errorf("status %d: expected %s, got %s", i, expected[i], history[i])
+			}
+		}
+	})
+
+	t.Run("records processing error on job", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		processor := &mockStatementProcessor{err: errors.New("failed to parse statement: boom")}
+		svc := NewJobService(processor, newMockJobRepository(), 1, 10)
+		svc.Start(ctx)
+
+		job, err := svc.Submit(ctx, "user123", []byte("fake pdf content"), "")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		finished := waitForJob(t, svc, "user123", job.ID)
+		if finished.Status != model.JobStatusFailed {
+			t.Fatalf("expected status failed, got %s", finished.Status)
+		}
+		if finished.Error != "failed to parse statement: boom" {
+			t.Errorf("unexpected error: %s", finished.Error)
+		}
+	})
+
+	t.Run("points failed job at existing statement for duplicate upload", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		processor := &mockStatementProcessor{err: &model.DuplicateStatementError{StatementID: "stmt-1"}}
+		svc := NewJobService(processor, newMockJobRepository(), 1, 10)
+		svc.Start(ctx)
+
+		job, err := svc.Submit(ctx, "user123", []byte("fake pdf content"), "")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		finished := waitForJob(t, svc, "user123", job.ID)
+		if finished.Status != model.JobStatusFailed {
+			t.Fatalf("expected status failed, got %s", finished.Status)
+		}
+		if finished.StatementID != "stmt-1" {
+			t.Errorf("expected statement ID stmt-1, got %s", finished.StatementID)
+		}
+	})
+
+	t.Run("returns error when queue is full", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// No workers are started, so the single queue slot fills up
+		svc := NewJobService(&mockStatementProcessor{}, newMockJobRepository(), 1, 1)
+
+		if _, err := svc.Submit(ctx, "user123", []byte("first"), ""); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		_, err := svc.Submit(ctx, "user123", []byte("second"), "")
+		if !errors.Is(err, model.ErrJobQueueFull) {
+			t.Fatalf("expected ErrJobQueueFull, got %v", err)
+		}
+	})
+
+	t.Run("returns error when job cannot be saved", func(t *testing.T) {
+		repo := newMockJobRepository()
+		repo.err = errors.New("database error")
+		svc := NewJobService(&mockStatementProcessor{}, repo, 1, 1)
+
+		_, err := svc.Submit(context.Background(), "user123", []byte("fake pdf content"), "")
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if err.Error() != "failed to save job: database error" {
+			t.Errorf("unexpected error message: %s", err.Error())
+		}
+	})
+}
+
+func TestJobService_ResumesJobsAfterShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	processor := &mockStatementProcessor{started: make(chan struct{}, 1)}
+	repo := newMockJobRepository()
+	svc := NewJobService(processor, repo, 1, 10)
+	svc.Start(ctx)
+
+	running, err := svc.Submit(ctx, "user123", []byte("first"), "secret")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	<-processor.started
+	queued, err := svc.Submit(ctx, "user123", []byte("second"), "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	cancel()
+	svc.Wait()
+
+	for _, id := range []string{running.ID, queued.ID} {
+		job, err := repo.GetJob(context.Background(), id)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if job.Status != model.JobStatusQueued || !repo.hasUpload(id) {
+			t.Errorf("expected job %s to stay queued with its upload, got %s", id, job.Status)
+		}
+	}
+	if !repo.uploads[running.ID].PasswordProtected {
+		t.Error("expected the upload to record that it is password-protected")
+	}
+
+	// A restarted server picks the jobs up again, except the password-protected
+	// one since its password was not stored
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	svc = NewJobService(&mockStatementProcessor{statement: model.Statement{ID: "stmt-1"}}, repo, 1, 10)
+	svc.Start(ctx)
+
+	if job := waitForJob(t, svc, "user123", queued.ID); job.Status != model.JobStatusDone {
+		t.Errorf("expected job %s to be done, got %s %q", queued.ID, job.Status, job.Error)
+	}
+	if job := waitForJob(t, svc, "user123", running.ID); job.Status != model.JobStatusFailed || job.Error != ErrJobPasswordNotStored.Error() {
+		t.Errorf("expected the password-protected job to fail, got %s %q", job.Status, job.Error)
+	}
+	if repo.hasUpload(running.ID) {
+		t.Error("expected the upload of the failed job to be deleted")
+	}
+}
+
+func TestJobService_FailsResumedJobWithoutUpload(t *testing.T) {
+	repo := newMockJobRepository()
+	repo.jobs["job-1"] = model.Job{ID: "job-1", UserID: "user123", Status: model.JobStatusParsing, CreatedAt: time.Now().Add(-time.Minute)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	svc := NewJobService(&mockStatementProcessor{}, repo, 1, 10)
+	svc.Start(ctx)
+
+	job := waitForJob(t, svc, "user123", "job-1")
+	if job.Status != model.JobStatusFailed || job.Error != ErrJobInterrupted.Error() {
+		t.Errorf("expected job to fail as interrupted, got %s %q", job.Status, job.Error)
+	}
+}
+
+func TestJobService_GetJob(t *testing.T) {
+	repo := newMockJobRepository()
+	repo.jobs["job-1"] = model.Job{ID: "job-1", UserID: "user123", Status: model.JobStatusQueued}
+	svc := NewJobService(&mockStatementProcessor{}, repo, 1, 1)
+
+	t.Run("returns job owned by user", func(t *testing.T) {
+		job, err := svc.GetJob(context.Background(), "user123", "job-1")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if job.ID != "job-1" {
+			t.Errorf("expected job-1, got %s", job.ID)
+		}
+	})
+
+	t.Run("hides job owned by another user", func(t *testing.T) {
+		_, err := svc.GetJob(context.Background(), "someone-else", "job-1")
+		if !errors.Is(err, model.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("returns not found for unknown job", func(t *testing.T) {
+		_, err := svc.GetJob(context.Background(), "user123", "missing")
+		if !errors.Is(err, model.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
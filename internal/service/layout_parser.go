@@ -0,0 +1,257 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+// LayoutParser parses one known statement layout directly from
+// `pdftotext -layout` output, without an LLM
+type LayoutParser interface {
+	// Name identifies the parser and its version, e.g. "ktc.v1"
+	Name() string
+	// Match reports whether the text has this parser's layout
+	Match(text string) bool
+	Parse(text string) (model.Statement, error)
+}
+
+// LayoutRegistry picks the LayoutParser for a statement's layout
+type LayoutRegistry struct {
+	parsers []LayoutParser
+}
+
+// NewLayoutRegistry creates a registry trying parsers in order
+func NewLayoutRegistry(parsers ...LayoutParser) *LayoutRegistry {
+	return &LayoutRegistry{
+		parsers: parsers,
+	}
+}
+
+// DefaultLayoutRegistry returns a registry with the built-in bank layouts
+func DefaultLayoutRegistry() *LayoutRegistry {
+	return NewLayoutRegistry(ktcLayout, scbLayout, kbankLayout)
+}
+
+// Parse parses text with the first matching parser. ok is false when no
+// layout matches, or when the result fails validation and the statement
+// should go to the LLM instead.
+func (r *LayoutRegistry) Parse(text string) (statement model.Statement, ok bool) {
+	for _, parser := range r.parsers {
+		if !parser.Match(text) {
+			continue
+		}
+
+		statement, err := parser.Parse(text)
+		if err == nil {
+			err = validateLayoutResult(statement)
+		}
+		if err != nil {
+			log.Printf("layout parser %s rejected statement, falling back to LLM: %v", parser.Name(), err)
+			return model.Statement{}, false
+		}
+
+		statement.Model = "layout:" + parser.Name()
+		return statement, true
+	}
+	return model.Statement{}, false
+}
+
+// validateLayoutResult guards against a layout change the fingerprint did not
+// catch: a rule-based result is only trusted when it reconciles
+func validateLayoutResult(statement model.Statement) error {
+	if len(statement.Transactions) == 0 {
+		return errors.New("no transactions found")
+	}
+	reconcile(&statement)
+	if statement.ReconciliationStatus != model.ReconciliationMatched {
+		return fmt.Errorf("transactions do not reconcile with the statement total (delta %.2f)", statement.ReconciliationDelta)
+	}
+	return nil
+}
+
+// regexLayout is a LayoutParser driven by regular expressions. Header field
+// patterns capture the value in their first group.
+type regexLayout struct {
+	name        string
+	bank        string
+	fingerprint []*regexp.Regexp
+
+	cardNumber      *regexp.Regexp
+	statementDate   *regexp.Regexp
+	paymentDueDate  *regexp.Regexp
+	previousBalance *regexp.Regexp
+	totalPayment    *regexp.Regexp
+	minimumPayment  *regexp.Regexp
+	creditLine      *regexp.Regexp
+
+	// transaction matches one transaction line with named groups txdate,
+	// postdate, description, amount and the optional credit marker credit
+	transaction *regexp.Regexp
+}
+
+func (l *regexLayout) Name() string {
+	return l.name
+}
+
+func (l *regexLayout) Match(text string) bool {
+	for _, pattern := range l.fingerprint {
+		if !pattern.MatchString(text) {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *regexLayout) Parse(text string) (model.Statement, error) {
+	statement := model.Statement{
+		Bank:            l.bank,
+		CardNumber:      protectCardNumber(capture(l.cardNumber, text)),
+		PreviousBalance: parseLayoutAmount(capture(l.previousBalance, text)),
+		TotalPayment:    parseLayoutAmount(capture(l.totalPayment, text)),
+		MinimumPayment:  parseLayoutAmount(capture(l.minimumPayment, text)),
+		CreditLine:      parseLayoutAmount(capture(l.creditLine, text)),
+	}
+
+	var err error
+	if statement.StatementDate, err = parseLayoutDate(capture(l.statementDate, text), ""); err != nil {
+		return model.Statement{}, fmt.Errorf("invalid statement date: %w", err)
+	}
+	if statement.PaymentDueDate, err = parseLayoutDate(capture(l.paymentDueDate, text), ""); err != nil {
+		return model.Statement{}, fmt.Errorf("invalid payment due date: %w", err)
+	}
+	// Transaction dates often omit the year; infer it from the statement date
+	reference := statement.StatementDate
+	if reference == "" {
+		reference = statement.PaymentDueDate
+	}
+
+	names := l.transaction.SubexpNames()
+	for i, line := range strings.Split(text, "\n") {
+		match := l.transaction.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		groups := map[string]string{}
+		for j, name := range names {
+			if name != "" {
+				groups[name] = strings.TrimSpace(match[j])
+			}
+		}
+
+		transactionDate, err := parseLayoutDate(groups["txdate"], reference)
+		if err != nil {
+			return model.Statement{}, fmt.Errorf("invalid transaction date on line %d", i+1)
+		}
+		postingDate, err := parseLayoutDate(groups["postdate"], reference)
+		if err != nil {
+			return model.Statement{}, fmt.Errorf("invalid posting date on line %d", i+1)
+		}
+		if postingDate == "" {
+			postingDate = transactionDate
+		}
+
+		amount := parseLayoutAmount(groups["amount"])
+		if groups["credit"] != "" {
+			amount = -amount
+		}
+
+		description, term := splitInstallmentTerm(strings.Join(strings.Fields(groups["description"]), " "))
+		statement.Transactions = append(statement.Transactions, model.Transaction{
+			CardNumber:      statement.CardNumber,
+			TransactionDate: transactionDate,
+			PostingDate:     postingDate,
+			Description:     description,
+			Amount:          amount,
+			IsInstallment:   term != "",
+			InstallmentTerm: term,
+		})
+	}
+
+	return statement, nil
+}
+
+func capture(pattern *regexp.Regexp, text string) string {
+	if pattern == nil {
+		return ""
+	}
+	match := pattern.FindStringSubmatch(text)
+	if len(match) < 2 {
+		return ""
+	}
+	return strings.TrimSpace(match[1])
+}
+
+// protectCardNumber masks a full card number the same way redaction does,
+// leaving numbers the bank already masked unchanged
+func protectCardNumber(card string) string {
+	if strings.ContainsAny(card, "Xx*") || len(digitsOf(card)) < 13 {
+		return card
+	}
+	return maskCardNumber(card)
+}
+
+func parseLayoutAmount(s string) float64 {
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+	if err != nil {
+		return 0
+	}
+	return amount
+}
+
+var layoutDatePattern = regexp.MustCompile(`^(\d{1,2})/(\d{1,2})(?:/(\d{2}|\d{4}))?$`)
+
+// parseLayoutDate converts DD/MM, DD/MM/YY or DD/MM/YYYY to YYYY-MM-DD. A
+// missing year is taken from reference (YYYY-MM-DD), using the previous year
+// when the month is later than the reference month.
+func parseLayoutDate(s, reference string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	match := layoutDatePattern.FindStringSubmatch(s)
+	if match == nil {
+		return "", fmt.Errorf("unrecognized date %q", s)
+	}
+	day, _ := strconv.Atoi(match[1])
+	month, _ := strconv.Atoi(match[2])
+	if day < 1 || day > 31 || month < 1 || month > 12 {
+		return "", fmt.Errorf("unrecognized date %q", s)
+	}
+
+	var year int
+	switch len(match[3]) {
+	case 4:
+		year, _ = strconv.Atoi(match[3])
+	case 2:
+		year, _ = strconv.Atoi(match[3])
+		year += 2000
+	default:
+		if len(reference) < 7 {
+			return "", fmt.Errorf("cannot infer year of %q", s)
+		}
+		year, _ = strconv.Atoi(reference[:4])
+		refMonth, _ := strconv.Atoi(reference[5:7])
+		if month > refMonth {
+			year--
+		}
+	}
+
+	return fmt.Sprintf("%04d-%02d-%02d", year, month, day), nil
+}
+
+var installmentTermPattern = regexp.MustCompile(`^(.*\S)\s+(\d{1,3}/\d{1,3})$`)
+
+// splitInstallmentTerm separates an inline installment term such as
+// "2C2P *LAZADA 04/06" from the merchant description
+func splitInstallmentTerm(description string) (string, string) {
+	match := installmentTermPattern.FindStringSubmatch(description)
+	if match == nil {
+		return description, ""
+	}
+	return match[1], match[2]
+}
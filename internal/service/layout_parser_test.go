@@ -0,0 +1,149 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+const ktcLayoutText = `KTC CREDIT CARD STATEMENT
+CARD NO. 4111-11XX-XXXX-1111                 STATEMENT DATE 20/01/25
+PAYMENT DATE 06/02/25                        CREDIT LINE 100,000.00
+PREVIOUS BALANCE 31,751.00                   TOTAL PAYMENT 1,070.00
+MINIMUM PAYMENT 107.00
+
+17/12  18/12  PAYMENT - THANK YOU                    31,751.00 CR
+05/01  06/01  2C2P *LAZADA 04/06                      1,070.00
+`
+
+const scbLayoutText = `SCB CREDIT CARD
+CARD NUMBER 5500 0000 0000 0004
+STATEMENT DATE 15/02/25   DUE DATE 05/03/25
+PREVIOUS BALANCE 100.00   NEW BALANCE 1,480.50   MINIMUM 148.05   CREDIT LIMIT 50,000.00
+
+03/02 04/02 GRAB *FOOD BANGKOK          230.50
+08/02 08/02 PAYMENT RECEIVED            100.00-
+10/02 11/02 STARBUCKS CENTRAL WORLD     1,250.00
+`
+
+const kbankLayoutText = `KASIKORNBANK CREDIT CARD STATEMENT
+CARD NUMBER 4242-42XX-XXXX-4242
+STATEMENT DATE 25/01/2025   PAYMENT DUE DATE 10/02/2025
+PREVIOUS BALANCE 500.00   TOTAL AMOUNT DUE 2,000.00   MINIMUM PAYMENT 200.00   CREDIT LIMIT 80,000.00
+
+28/12/2024 29/12/2024 TOPS SUPERMARKET        1,500.00
+`
+
+func TestLayoutRegistry_Parse(t *testing.T) {
+	registry := DefaultLayoutRegistry()
+
+	t.Run("KTC", func(t *testing.T) {
+		statement, ok := registry.Parse(ktcLayoutText)
+		if !ok {
+			t.Fatal("expected KTC layout to be parsed")
+		}
+
+		if statement.Bank != "KTC" || statement.CardNumber != "4111-11XX-XXXX-1111" {
+			t.Errorf("unexpected bank or card: %s %s", statement.Bank, statement.CardNumber)
+		}
+		if statement.StatementDate != "2025-01-20" || statement.PaymentDueDate != "2025-02-06" {
+			t.Errorf("unexpected dates: %s %s", statement.StatementDate, statement.PaymentDueDate)
+		}
+		if statement.PreviousBalance != 31751 || statement.TotalPayment != 1070 || statement.MinimumPayment != 107 || statement.CreditLine != 100000 {
+			t.Errorf("unexpected amounts: %+v", statement)
+		}
+		if statement.Model != "layout:ktc.v1" {
+			t.Errorf("expected model layout:ktc.v1, got %s", statement.Model)
+		}
+
+		want := []model.Transaction{
+			{CardNumber: "4111-11XX-XXXX-1111", TransactionDate: "2024-12-17", PostingDate: "2024-12-18", Description: "PAYMENT - THANK YOU", Amount: -31751},
+			{CardNumber: "4111-11XX-XXXX-1111", TransactionDate: "2025-01-05", PostingDate: "2025-01-06", Description: "2C2P *LAZADA", Amount: 1070, IsInstallment: true, InstallmentTerm: "04/06"},
+		}
+		if len(statement.Transactions) != len(want) {
+			t.Fatalf("expected %d transactions, got %d", len(want), len(statement.Transactions))
+		}
+		for i := range want {
+			if !reflect.DeepEqual(statement.Transactions[i], want[i]) {
+				t.Errorf("transaction %d: expected %+v, got %+v", i, want[i], statement.Transactions[i])
+			}
+		}
+	})
+
+	t.Run("SCB masks full card number", func(t *testing.T) {
+		statement, ok := registry.Parse(scbLayoutText)
+		if !ok {
+			t.Fatal("expected SCB layout to be parsed")
+		}
+		if statement.Bank != "SCB" || statement.CardNumber != "5500 00XX XXXX 0004" {
+			t.Errorf("unexpected bank or card: %s %s", statement.Bank, statement.CardNumber)
+		}
+		if len(statement.Transactions) != 3 || statement.Transactions[1].Amount != -100 {
+			t.Errorf("expected trailing minus to mark a credit, got %+v", statement.Transactions)
+		}
+	})
+
+	t.Run("KBank", func(t *testing.T) {
+		statement, ok := registry.Parse(kbankLayoutText)
+		if !ok {
+			t.Fatal("expected KBank layout to be parsed")
+		}
+		if statement.Bank != "KBANK" || statement.Transactions[0].TransactionDate != "2024-12-28" {
+			t.Errorf("unexpected result: %+v", statement)
+		}
+	})
+
+	t.Run("unknown layout", func(t *testing.T) {
+		if _, ok := registry.Parse("SOME OTHER BANK\n01/01 01/01 SHOP   10.00"); ok {
+			t.Error("expected unknown layout not to be parsed")
+		}
+	})
+
+	t.Run("rejects results that do not reconcile", func(t *testing.T) {
+		text := strings.Replace(ktcLayoutText, "TOTAL PAYMENT 1,070.00", "TOTAL PAYMENT 2,070.00", 1)
+		if _, ok := registry.Parse(text); ok {
+			t.Error("expected mismatched statement to fall back")
+		}
+	})
+
+	t.Run("rejects statements without transactions", func(t *testing.T) {
+		text := ktcLayoutText[:strings.Index(ktcLayoutText, "17/12")]
+		if _, ok := registry.Parse(text); ok {
+			t.Error("expected statement without transactions to fall back")
+		}
+	})
+}
+
+func TestRegexLayout_ParseErrorOmitsLineContent(t *testing.T) {
+	text := strings.Replace(ktcLayoutText, "05/01  06/01", "35/01  06/01", 1)
+	_, err := ktcLayout.Parse(text)
+	if err == nil {
+		t.Fatal("expected an invalid transaction date error")
+	}
+	if !strings.Contains(err.Error(), "line 8") || strings.Contains(err.Error(), "LAZADA") {
+		t.Errorf("expected the error to name the line number only, got %q", err)
+	}
+}
+
+func TestParseLayoutDate(t *testing.T) {
+	tests := []struct {
+		in, reference, want string
+		wantErr             bool
+	}{
+		{"06/02/25", "", "2025-02-06", false},
+		{"10/02/2025", "", "2025-02-10", false},
+		{"17/12", "2025-01-20", "2024-12-17", false},
+		{"05/01", "2025-01-20", "2025-01-05", false},
+		{"", "", "", false},
+		{"17/12", "", "", true},
+		{"32/01/25", "", "", true},
+	}
+	for _, tt := range tests {
+		got, err := parseLayoutDate(tt.in, tt.reference)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseLayoutDate(%q, %q) = %q, %v; want %q", tt.in, tt.reference, got, err, tt.want)
+		}
+	}
+}
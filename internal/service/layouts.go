@@ -0,0 +1,67 @@
+package service
+
+import "regexp"
+
+// Built-in bank layouts. Each fingerprint requires the bank's name and the
+// header labels specific to its statement, so a layout change makes the
+// statement fall back to the LLM instead of being misread.
+
+// layoutTransactionPattern matches "DD/MM[/YY] DD/MM[/YY] DESCRIPTION  1,234.56[ CR|-]"
+var layoutTransactionPattern = regexp.MustCompile(`^\s*(?P<txdate>\d{2}/\d{2}(?:/\d{2,4})?)\s+(?P<postdate>\d{2}/\d{2}(?:/\d{2,4})?)\s+(?P<description>\S.*?)\s{2,}(?P<amount>\d{1,3}(?:,\d{3})*\.\d{2})\s*(?P<credit>CR|-)?\s*$`)
+
+var ktcLayout = &regexLayout{
+	name: "ktc.v1",
+	bank: "KTC",
+	fingerprint: []*regexp.Regexp{
+		regexp.MustCompile(`(?m)^\s*KTC\b`),
+		regexp.MustCompile(`CARD NO\.`),
+		regexp.MustCompile(`PAYMENT DATE`),
+		regexp.MustCompile(`TOTAL PAYMENT`),
+	},
+	cardNumber:      regexp.MustCompile(`CARD NO\.\s+([0-9X][0-9X -]{11,22}[0-9X])`),
+	statementDate:   regexp.MustCompile(`STATEMENT DATE\s+(\d{2}/\d{2}/\d{2,4})`),
+	paymentDueDate:  regexp.MustCompile(`PAYMENT DATE\s+(\d{2}/\d{2}/\d{2,4})`),
+	previousBalance: regexp.MustCompile(`PREVIOUS BALANCE\s+([\d,]+\.\d{2})`),
+	totalPayment:    regexp.MustCompile(`TOTAL PAYMENT\s+([\d,]+\.\d{2})`),
+	minimumPayment:  regexp.MustCompile(`MINIMUM PAYMENT\s+([\d,]+\.\d{2})`),
+	creditLine:      regexp.MustCompile(`CREDIT LINE\s+([\d,]+\.\d{2})`),
+	transaction:     layoutTransactionPattern,
+}
+
+var scbLayout = &regexLayout{
+	name: "scb.v1",
+	bank: "SCB",
+	fingerprint: []*regexp.Regexp{
+		regexp.MustCompile(`(?m)^\s*SCB\b`),
+		regexp.MustCompile(`CARD NUMBER`),
+		regexp.MustCompile(`DUE DATE`),
+		regexp.MustCompile(`NEW BALANCE`),
+	},
+	cardNumber:      regexp.MustCompile(`CARD NUMBER\s+([0-9X][0-9X -]{11,22}[0-9X])`),
+	statementDate:   regexp.MustCompile(`STATEMENT DATE\s+(\d{2}/\d{2}/\d{2,4})`),
+	paymentDueDate:  regexp.MustCompile(`DUE DATE\s+(\d{2}/\d{2}/\d{2,4})`),
+	previousBalance: regexp.MustCompile(`PREVIOUS BALANCE\s+([\d,]+\.\d{2})`),
+	totalPayment:    regexp.MustCompile(`NEW BALANCE\s+([\d,]+\.\d{2})`),
+	minimumPayment:  regexp.MustCompile(`MINIMUM\s+([\d,]+\.\d{2})`),
+	creditLine:      regexp.MustCompile(`CREDIT LIMIT\s+([\d,]+\.\d{2})`),
+	transaction:     layoutTransactionPattern,
+}
+
+var kbankLayout = &regexLayout{
+	name: "kbank.v1",
+	bank: "KBANK",
+	fingerprint: []*regexp.Regexp{
+		regexp.MustCompile(`(?m)^\s*(?:KASIKORNBANK|KBANK)\b`),
+		regexp.MustCompile(`CARD NUMBER`),
+		regexp.MustCompile(`PAYMENT DUE DATE`),
+		regexp.MustCompile(`TOTAL AMOUNT DUE`),
+	},
+	cardNumber:      regexp.MustCompile(`CARD NUMBER\s+([0-9X][0-9X -]{11,22}[0-9X])`),
+	statementDate:   regexp.MustCompile(`STATEMENT DATE\s+(\d{2}/\d{2}/\d{2,4})`),
+	paymentDueDate:  regexp.MustCompile(`PAYMENT DUE DATE\s+(\d{2}/\d{2}/\d{2,4})`),
+	previousBalance: regexp.MustCompile(`PREVIOUS BALANCE\s+([\d,]+\.\d{2})`),
+	totalPayment:    regexp.MustCompile(`TOTAL AMOUNT DUE\s+([\d,]+\.\d{2})`),
+	minimumPayment:  regexp.MustCompile(`MINIMUM PAYMENT\s+([\d,]+\.\d{2})`),
+	creditLine:      regexp.MustCompile(`CREDIT LIMIT\s+([\d,]+\.\d{2})`),
+	transaction:     layoutTransactionPattern,
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+//go:embed merchants.json
+var embeddedMerchantAliases []byte
+
+var (
+	// Installment terms such as "04/06", "(04/06)" or "IPP 3/10" at the end
+	installmentSuffixPattern = regexp.MustCompile(`\s*\(?\b(?:INST(?:ALLMENT)?\.?|IPP|PLAN)?\s*\d{1,2}\s*/\s*\d{1,2}\)?$`)
+	// Branch names follow a BRANCH or สาขา marker
+	branchSuffixPattern = regexp.MustCompile(`(?:\s|-)+(?:BRANCH|BR\.|สาขา)(?:\s.*)?$`)
+	// Store and terminal numbers such as "#0123" or "12345"
+	storeNumberPattern = regexp.MustCompile(`(?:\s|-)+(?:NO\.?\s*|#)?\d{3,}$`)
+)
+
+// MerchantAliases is the maintained table that merchant normalization is
+// driven by. Merchants maps each canonical name to the cleaned descriptions
+// it is known by.
+type MerchantAliases struct {
+	Processors []string            `json:"processors"`
+	Locations  []string            `json:"locations"`
+	Merchants  map[string][]string `json:"merchants"`
+}
+
+// LoadMerchantAliases decodes an alias table in the format of the built-in
+// merchants.json
+func LoadMerchantAliases(data []byte) (MerchantAliases, error) {
+	var aliases MerchantAliases
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return MerchantAliases{}, fmt.Errorf("failed to decode merchant aliases: %w", err)
+	}
+	return aliases, nil
+}
+
+type merchantAlias struct {
+	alias     string
+	canonical string
+}
+
+// MerchantNormalizer derives a merchant name from a transaction description,
+// so that e.g. "2C2P *LAZADA 04/06", "LAZADA-BANGKOK" and "SHOPEEPAY*LAZADA"
+// all become "Lazada"
+type MerchantNormalizer struct {
+	processors []string
+	locations  []string
+	aliases    []merchantAlias
+}
+
+// DefaultMerchantNormalizer returns a normalizer using the alias table built
+// into the binary
+func DefaultMerchantNormalizer() *MerchantNormalizer {
+	aliases, err := LoadMerchantAliases(embeddedMerchantAliases)
+	if err != nil {
+		panic(err)
+	}
+	return (&MerchantNormalizer{}).WithAliases(aliases)
+}
+
+// WithAliases adds processors, locations and merchant aliases to the table;
+// an alias that is already known is mapped to the new canonical name
+func (n *MerchantNormalizer) WithAliases(aliases MerchantAliases) *MerchantNormalizer {
+	for _, processor := range aliases.Processors {
+		n.processors = appendUnique(n.processors, normalizeMerchantText(processor))
+	}
+	for _, location := range aliases.Locations {
+		n.locations = appendUnique(n.locations, normalizeMerchantText(location))
+	}
+	for canonical, names := range aliases.Merchants {
+		for _, name := range names {
+			alias := normalizeMerchantText(name)
+			n.aliases = slices.DeleteFunc(n.aliases, func(a merchantAlias) bool { return a.alias == alias })
+			n.aliases = append(n.aliases, merchantAlias{alias: alias, canonical: canonical})
+		}
+	}
+
+	// Longer entries are tried first so "GOOGLE YOUTUBE" wins over "GOOGLE"
+	byLength := func(a, b string) int { return len(b) - len(a) }
+	slices.SortStableFunc(n.processors, byLength)
+	slices.SortStableFunc(n.locations, byLength)
+	slices.SortStableFunc(n.aliases, func(a, b merchantAlias) int { return byLength(a.alias, b.alias) })
+	return n
+}
+
+// Normalize strips payment processor prefixes, installment terms, branch and
+// location suffixes and store numbers from description and returns the
+// canonical merchant name, or the cleaned description when no alias matches
+func (n *MerchantNormalizer) Normalize(description string) string {
+	cleaned := normalizeMerchantText(description)
+	merchant := cleaned
+
+	// Processors can be nested, e.g. "PAYPAL *2C2P *SHOP"
+	for stripped := true; stripped; {
+		stripped = false
+		for _, processor := range n.processors {
+			if rest, ok := strings.CutPrefix(merchant, processor); ok {
+				if rest, ok = strings.CutPrefix(strings.TrimLeft(rest, " "), "*"); ok && strings.TrimSpace(rest) != "" {
+					merchant = strings.TrimSpace(rest)
+					stripped = true
+					break
+				}
+			}
+		}
+	}
+
+	// Suffixes come in any order, e.g. "SHOP 04/06 BANGKOK TH"
+	for previous := ""; previous != merchant; {
+		previous = merchant
+		merchant = installmentSuffixPattern.ReplaceAllString(merchant, "")
+		merchant = branchSuffixPattern.ReplaceAllString(merchant, "")
+		merchant = storeNumberPattern.ReplaceAllString(merchant, "")
+		merchant = n.trimLocation(merchant)
+		merchant = strings.TrimRight(merchant, " -,./*#")
+	}
+	if merchant == "" {
+		merchant = cleaned
+	}
+
+	for _, a := range n.aliases {
+		if rest, ok := strings.CutPrefix(merchant, a.alias); ok && (rest == "" || !isMerchantWordChar(rest)) {
+			return a.canonical
+		}
+	}
+	return merchant
+}
+
+// NormalizeTransactions sets Merchant on each transaction from its description
+func (n *MerchantNormalizer) NormalizeTransactions(transactions []model.Transaction) {
+	for i := range transactions {
+		transactions[i].Merchant = n.Normalize(transactions[i].Description)
+	}
+}
+
+// trimLocation removes one trailing location that is a separate word
+func (n *MerchantNormalizer) trimLocation(merchant string) string {
+	for _, location := range n.locations {
+		rest, ok := strings.CutSuffix(merchant, location)
+		if !ok || rest == "" {
+			continue
+		}
+		if last := rest[len(rest)-1]; last == ' ' || last == '-' || last == ',' || last == '/' {
+			return rest
+		}
+	}
+	return merchant
+}
+
+// normalizeMerchantText upper-cases text and collapses whitespace
+func normalizeMerchantText(text string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(text), " "))
+}
+
+// isMerchantWordChar reports whether rest continues the word before it, in
+// which case an alias prefix is only part of a longer name
+func isMerchantWordChar(rest string) bool {
+	r := []rune(rest)[0]
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func appendUnique(items []string, item string) []string {
+	if item == "" || slices.Contains(items, item) {
+		return items
+	}
+	return append(items, item)
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+func TestMerchantNormalizer_Normalize(t *testing.T) {
+	normalizer := DefaultMerchantNormalizer()
+
+	tests := []struct {
+		description string
+		want        string
+	}{
+		{"2C2P *LAZADA 04/06", "Lazada"},
+		{"LAZADA-BANGKOK", "Lazada"},
+		{"SHOPEEPAY*LAZADA", "Lazada"},
+		{"PAYPAL *NETFLIX.COM", "Netflix"},
+		{"GRABFOOD BANGKOK TH", "Grab"},
+		{"7-ELEVEN 12345 BKK", "7-Eleven"},
+		{"TOPS MARKET สาขา สีลม", "Tops"},
+		{"Google YouTube Premium", "YouTube"},
+		{"LAZY BONES CAFE", "LAZY BONES CAFE"},
+		{"somtum  der silom-Bangkok TH", "SOMTUM DER SILOM"},
+		{"PAYPAL *2C2P *SOME SHOP (03/10)", "SOME SHOP"},
+		{"IKEA BANGNA BRANCH", "IKEA"},
+		{"CAFE AMAZON BR. 0123", "CAFE AMAZON"},
+		{"HOMEMADE CAKES IPP 3/10", "HOMEMADE CAKES"},
+		{"BANGKOK", "BANGKOK"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			if got := normalizer.Normalize(tt.description); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.description, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerchantNormalizer_WithAliases(t *testing.T) {
+	aliases, err := LoadMerchantAliases([]byte(`{"processors":["MYPAY"],"locations":["NAKHON PATHOM"],"merchants":{"Cafe Amazon":["CAFE AMAZON"],"Lazada Mall":["LAZADA"]}}`))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	normalizer := DefaultMerchantNormalizer().WithAliases(aliases)
+
+	transactions := []model.Transaction{
+		{Description: "MYPAY*CAFE AMAZON NAKHON PATHOM"},
+		{Description: "2C2P *LAZADA"},
+		{Description: "AMAZON MARKETPLACE"},
+	}
+	normalizer.NormalizeTransactions(transactions)
+
+	want := []string{"Cafe Amazon", "Lazada Mall", "Amazon"}
+	for i, merchant := range want {
+		if transactions[i].Merchant != merchant {
+			t.Errorf("transaction %d: expected merchant %q, got %q", i, merchant, transactions[i].Merchant)
+		}
+	}
+
+	if _, err := LoadMerchantAliases([]byte(`{"merchants":[]}`)); err == nil {
+		t.Errorf("expected error for malformed alias table")
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// NativeTextExtractor extracts text in pure Go, without any external binaries.
+// Output approximates pdftotext -layout by emitting one line per text row
+// and separating pages with a form feed.
+type NativeTextExtractor struct {
+}
+
+func NewNativeTextExtractor() *NativeTextExtractor {
+	return &NativeTextExtractor{}
+}
+
+func (e *NativeTextExtractor) Extract(ctx context.Context, data []byte, password string) (string, error) {
+	// The reader keeps asking for passwords until it gets an empty one
+	tried := false
+	reader, err := pdf.NewReaderEncrypted(bytes.NewReader(data), int64(len(data)), func() string {
+		if tried {
+			return ""
+		}
+		tried = true
+		return password
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to open PDF: %w", err)
+	}
+
+	var sb strings.Builder
+	for i := 1; i <= reader.NumPage(); i++ {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		if i > 1 {
+			sb.WriteString("\f")
+		}
+
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+
+		rows, err := page.GetTextByRow()
+		if err != nil {
+			return "", fmt.Errorf("failed to read page %d: %w", i, err)
+		}
+		for _, row := range rows {
+			sb.WriteString(joinRow(row.Content))
+			sb.WriteString("\n")
+		}
+	}
+
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// joinRow concatenates the text fragments of a row, inserting a space
+// wherever the horizontal gap between fragments looks like a word break
+func joinRow(texts []pdf.Text) string {
+	var sb strings.Builder
+	var end float64
+	for i, t := range texts {
+		if i > 0 && t.X-end > t.FontSize*0.2 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(t.S)
+		end = t.X + t.W
+	}
+	return sb.String()
+}
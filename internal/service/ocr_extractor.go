@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultOCRLanguages = "tha+eng"
+	defaultOCRDPI       = 300
+)
+
+// OCRTextExtractor extracts text from scanned or image-only PDFs by
+// rasterizing each page with pdftoppm and running Tesseract over the images
+type OCRTextExtractor struct {
+	languages string
+	dpi       int
+}
+
+// NewOCRTextExtractor creates a new OCRTextExtractor instance
+// languages is a Tesseract language spec such as "tha+eng"; empty uses the default
+func NewOCRTextExtractor(languages string) *OCRTextExtractor {
+	if languages == "" {
+		languages = defaultOCRLanguages
+	}
+	return &OCRTextExtractor{
+		languages: languages,
+		dpi:       defaultOCRDPI,
+	}
+}
+
+func (e *OCRTextExtractor) Extract(ctx context.Context, pdf []byte, password string) (string, error) {
+	tmpDir, err := os.MkdirTemp("", "ocr-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	pdfPath := filepath.Join(tmpDir, "statement.pdf")
+	if err := os.WriteFile(pdfPath, pdf, 0o600); err != nil {
+		return "", err
+	}
+
+	// Rasterize every page to a grayscale PNG
+	args := []string{"-r", strconv.Itoa(e.dpi), "-gray", "-png"}
+	if password != "" {
+		args = append(args, "-upw", password)
+	}
+	args = append(args, pdfPath, filepath.Join(tmpDir, "page"))
+	if _, err := runCommand(ctx, "pdftoppm", args...); err != nil {
+		return "", fmt.Errorf("failed to rasterize PDF: %w", err)
+	}
+
+	images, err := filepath.Glob(filepath.Join(tmpDir, "page-*.png"))
+	if err != nil {
+		return "", err
+	}
+	// pdftoppm zero-pads page numbers, so lexical order is page order
+	sort.Strings(images)
+
+	pages := make([]string, 0, len(images))
+	for _, image := range images {
+		text, err := runCommand(ctx, "tesseract", image, "stdout", "-l", e.languages, "--psm", "6")
+		if err != nil {
+			return "", fmt.Errorf("failed to OCR %s: %w", filepath.Base(image), err)
+		}
+		pages = append(pages, strings.TrimSpace(text))
+	}
+
+	return strings.TrimSpace(strings.Join(pages, "\f")), nil
+}
@@ -1,82 +1,356 @@
 package service
 
 import (
-	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
-	"os"
-	"os/exec"
+	"log"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/tsongpon/helios/internal/model"
 )
 
+// DefaultMinTextDensity is the number of non-whitespace characters per page
+// below which extracted text is considered too sparse and OCR is attempted
+const DefaultMinTextDensity = 100
+
+// ErrNoTextExtracted is returned when neither text extraction nor OCR
+// produced any text from the PDF
+var ErrNoTextExtracted = errors.New("no text could be extracted from PDF")
+
+// TransactionCategorizer assigns categories to uncategorized transactions
+type TransactionCategorizer interface {
+	Categorize(ctx context.Context, userID string, transactions []model.Transaction) (int, error)
+}
+
+// TransactionRuleApplier applies the user's rules to transactions
+type TransactionRuleApplier interface {
+	ApplyRules(ctx context.Context, userID string, transactions []model.Transaction) error
+}
+
 // PDFService handles PDF text extraction and parsing
 type PDFService struct {
+	textExtractor         TextExtractor
+	ocrExtractor          TextExtractor
+	minTextDensity        int
+	redactor              *PIIRedactor
+	layouts               *LayoutRegistry
 	llmRepository         LLMRepository
+	statementRepository   StatementRepository
 	transactionRepository TransactionRepository
+	merchants             *MerchantNormalizer
+	rules                 TransactionRuleApplier
+	categorizer           TransactionCategorizer
 }
 
 // NewPDFService creates a new PDFService instance
-func NewPDFService(llmRepository LLMRepository, transactionRepository TransactionRepository) *PDFService {
+func NewPDFService(textExtractor TextExtractor, llmRepository LLMRepository, statementRepository StatementRepository, transactionRepository TransactionRepository) *PDFService {
 	return &PDFService{
+		textExtractor:         textExtractor,
 		llmRepository:         llmRepository,
+		statementRepository:   statementRepository,
 		transactionRepository: transactionRepository,
 	}
 }
 
-// ExtractText extracts text content from a PDF file using pdftotext
+// WithOCR enables OCR fallback for PDFs whose extracted text has fewer than
+// minTextDensity non-whitespace characters per page, such as scanned statements
+func (s *PDFService) WithOCR(ocrExtractor TextExtractor, minTextDensity int) *PDFService {
+	s.ocrExtractor = ocrExtractor
+	s.minTextDensity = minTextDensity
+	return s
+}
+
+// WithRedaction masks personal data in the statement text before it is sent
+// to the LLM and restores the storable parts in the parsed result
+func (s *PDFService) WithRedaction(redactor *PIIRedactor) *PDFService {
+	s.redactor = redactor
+	return s
+}
+
+// WithLayoutParsers parses statements with a known layout using rules
+// instead of the LLM, which is only called for unknown layouts
+func (s *PDFService) WithLayoutParsers(layouts *LayoutRegistry) *PDFService {
+	s.layouts = layouts
+	return s
+}
+
+// WithMerchantNormalizer sets the merchant of parsed transactions from their
+// descriptions
+func (s *PDFService) WithMerchantNormalizer(merchants *MerchantNormalizer) *PDFService {
+	s.merchants = merchants
+	return s
+}
+
+// WithRules applies the user's rules to parsed transactions before they are
+// categorized, so a category set by a rule is not sent to the classifier. A
+// failure is logged rather than failing the upload.
+func (s *PDFService) WithRules(rules TransactionRuleApplier) *PDFService {
+	s.rules = rules
+	return s
+}
+
+// WithCategorizer categorizes parsed transactions before they are saved. A
+// failure is logged and leaves the rest uncategorized rather than failing the upload.
+func (s *PDFService) WithCategorizer(categorizer TransactionCategorizer) *PDFService {
+	s.categorizer = categorizer
+	return s
+}
+
+// ExtractText extracts text content from a PDF file using the configured TextExtractor
 // password is optional - pass empty string for non-protected PDFs
-func (s *PDFService) ExtractText(ctx context.Context, userID string, file io.Reader, password string) ([]model.Transaction, error) {
-	// Create a temporary file to store the PDF
-	tmpFile, err := os.CreateTemp("", "pdf-*.pdf")
+func (s *PDFService) ExtractText(ctx context.Context, userID string, file io.Reader, password string) (model.Statement, error) {
+	content, err := io.ReadAll(file)
 	if err != nil {
-		return nil, err
+		return model.Statement{}, err
 	}
-	defer os.Remove(tmpFile.Name())
-	defer tmpFile.Close()
 
-	// Write the uploaded content to temp file
-	if _, err := io.Copy(tmpFile, file); err != nil {
-		return nil, err
+	return s.ProcessStatement(ctx, userID, content, password, nil)
+}
+
+// ProcessStatement runs the extract, parse and save pipeline over PDF content,
+// reporting each stage to onStatus when it is non-nil
+func (s *PDFService) ProcessStatement(ctx context.Context, userID string, content []byte, password string, onStatus func(model.JobStatus)) (model.Statement, error) {
+	if onStatus == nil {
+		onStatus = func(model.JobStatus) {}
 	}
-	tmpFile.Close()
 
-	// Build pdftotext command arguments
-	args := []string{"-layout"}
-	if password != "" {
-		args = append(args, "-upw", password)
+	// Skip the whole pipeline when the same file was uploaded before
+	hash := fileHash(content)
+	existing, err := s.statementRepository.GetStatementByFileHash(ctx, userID, hash)
+	if err == nil {
+		return model.Statement{}, &model.DuplicateStatementError{StatementID: existing.ID}
+	}
+	if !errors.Is(err, model.ErrNotFound) {
+		return model.Statement{}, fmt.Errorf("failed to check for duplicate statement: %w", err)
 	}
-	args = append(args, tmpFile.Name(), "-")
 
-	// Use pdftotext to extract text (supports Thai and other Unicode)
-	var stdout, stderr bytes.Buffer
-	cmd := exec.Command("pdftotext", args...)
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	onStatus(model.JobStatusExtracting)
+	extractedText, err := s.extractText(ctx, content, password)
+	if err != nil {
+		return model.Statement{}, err
+	}
+
+	// Send extracted text to LLM repository for parsing
+	onStatus(model.JobStatusParsing)
+	statement, err := s.parseStatement(ctx, extractedText)
+	if err != nil {
+		return model.Statement{}, fmt.Errorf("failed to parse statement: %w", err)
+	}
+	statement.ID = statementID(userID, hash)
+	statement.UserID = userID
+	statement.FileHash = hash
+	// Personal data is masked even when redaction for the LLM is disabled
+	statement.SourceText = NewPIIRedactor().RedactForStorage(extractedText)
+	statement.CreatedAt = time.Now().UTC()
 
-	if err := cmd.Run(); err != nil {
-		if stderr.Len() > 0 {
-			return nil, fmt.Errorf("%s", strings.TrimSpace(stderr.String()))
+	onStatus(model.JobStatusSaving)
+	if err := s.prepareStatement(ctx, &statement, nil); err != nil {
+		return model.Statement{}, err
+	}
+	// Transactions are saved first, so a failed save leaves no statement
+	// that blocks uploading the file again. The statement ID is derived from
+	// the file, so of two concurrent uploads only one can create it.
+	if err := s.transactionRepository.Save(ctx, statement.Transactions); err != nil {
+		return model.Statement{}, fmt.Errorf("failed to save transactions: %w", err)
+	}
+	if err := s.statementRepository.Create(ctx, statement); err != nil {
+		if errors.Is(err, model.ErrAlreadyExists) {
+			return model.Statement{}, &model.DuplicateStatementError{StatementID: statement.ID}
 		}
-		return nil, err
+		return model.Statement{}, fmt.Errorf("failed to save statement: %w", err)
 	}
 
-	extractedText := strings.TrimSpace(stdout.String())
+	return statement, nil
+}
 
-	// Send extracted text to LLM repository for parsing
-	transactions, err := s.llmRepository.ParseStatement(extractedText)
+// ReparseStatement runs the stored text of an existing statement through the
+// LLM again, replacing its header fields and transactions with the new result
+func (s *PDFService) ReparseStatement(ctx context.Context, userID, statementID string) (model.Statement, error) {
+	existing, err := getOwnedStatement(ctx, s.statementRepository, userID, statementID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse statement: %w", err)
+		return model.Statement{}, err
 	}
-	for i := range transactions {
-		transactions[i].UserID = userID
+	if strings.TrimSpace(existing.SourceText) == "" {
+		return model.Statement{}, ErrNoTextExtracted
 	}
 
-	if err := s.transactionRepository.Save(ctx, transactions); err != nil {
-		return nil, fmt.Errorf("failed to save transactions: %w", err)
+	statement, err := s.parseStatement(ctx, existing.SourceText)
+	if err != nil {
+		return model.Statement{}, fmt.Errorf("failed to parse statement: %w", err)
 	}
+	statement.ID = existing.ID
+	statement.UserID = existing.UserID
+	statement.FileHash = existing.FileHash
+	// Statements saved before their source text was redacted are masked now
+	statement.SourceText = NewPIIRedactor().RedactForStorage(existing.SourceText)
+	statement.CreatedAt = existing.CreatedAt
+
+	previous, err := s.transactionRepository.GetTransactionsByStatement(ctx, statement.ID)
+	if err != nil {
+		return model.Statement{}, fmt.Errorf("failed to get transactions: %w", err)
+	}
+	if err := s.prepareStatement(ctx, &statement, previous); err != nil {
+		return model.Statement{}, err
+	}
+
+	// The new transactions are upserted before the ones the parse no longer
+	// produces are deleted, so a failed save leaves the previous set in place
+	if err := s.transactionRepository.Save(ctx, statement.Transactions); err != nil {
+		return model.Statement{}, fmt.Errorf("failed to save transactions: %w", err)
+	}
+	if err := s.statementRepository.Save(ctx, statement); err != nil {
+		return model.Statement{}, fmt.Errorf("failed to save statement: %w", err)
+	}
+	if stale := staleTransactionIDs(previous, statement.Transactions); len(stale) > 0 {
+		if err := s.transactionRepository.DeleteByIDs(ctx, stale); err != nil {
+			return model.Statement{}, fmt.Errorf("failed to delete transactions: %w", err)
+		}
+	}
+
+	return statement, nil
+}
+
+// staleTransactionIDs returns the IDs of previous transactions that are not
+// in current
+func staleTransactionIDs(previous, current []model.Transaction) []string {
+	keep := make(map[string]bool, len(current))
+	for _, t := range current {
+		keep[t.ID] = true
+	}
+	var stale []string
+	for _, t := range previous {
+		if !keep[t.ID] {
+			stale = append(stale, t.ID)
+		}
+	}
+	return stale
+}
+
+// parseStatement parses the statement text with a layout parser when one
+// matches, and otherwise sends it to the LLM, redacting it first when
+// redaction is enabled
+func (s *PDFService) parseStatement(ctx context.Context, text string) (model.Statement, error) {
+	if s.layouts != nil {
+		if statement, ok := s.layouts.Parse(text); ok {
+			return statement, nil
+		}
+	}
+
+	if s.redactor == nil {
+		return s.llmRepository.ParseStatement(ctx, text)
+	}
+
+	redacted, redaction := s.redactor.Redact(text)
+	statement, err := s.llmRepository.ParseStatement(ctx, redacted)
+	if err != nil {
+		return model.Statement{}, err
+	}
+	redaction.Rehydrate(&statement)
+	return statement, nil
+}
+
+// prepareStatement links the parsed transactions to the statement, carries
+// over user edits and categories from previous and already saved transactions
+// with the same ID, normalizes merchants, applies the user's rules,
+// categorizes the rest and reconciles them against the statement total
+func (s *PDFService) prepareStatement(ctx context.Context, statement *model.Statement, previous []model.Transaction) error {
+	// Card numbers are masked on every path, as redaction and the layout
+	// parsers return them, so transaction IDs stay the same when a statement
+	// parsed without redaction is reparsed from its masked source text
+	statement.CardNumber = maskCardNumber(statement.CardNumber)
+	ids := make([]string, len(statement.Transactions))
+	for i := range statement.Transactions {
+		statement.Transactions[i].CardNumber = maskCardNumber(statement.Transactions[i].CardNumber)
+		statement.Transactions[i].UserID = statement.UserID
+		statement.Transactions[i].StatementID = statement.ID
+		statement.Transactions[i].Source = model.TransactionSourceStatement
+	}
+	assignTransactionIDs(statement.Transactions)
+	for i, t := range statement.Transactions {
+		ids[i] = t.ID
+	}
+
+	// Overlapping statements share transaction IDs, so an upload can upsert
+	// transactions the user already edited
+	saved, err := s.transactionRepository.GetTransactionsByIDs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to get transactions: %w", err)
+	}
+	keepStatementOwners(statement.Transactions, saved)
+	known := append(previous, saved...)
+	preserveOverrides(statement.Transactions, known)
+	preserveCategories(statement.Transactions, known)
+	if s.merchants != nil {
+		s.merchants.NormalizeTransactions(statement.Transactions)
+	}
+	if s.rules != nil {
+		if err := s.rules.ApplyRules(ctx, statement.UserID, statement.Transactions); err != nil {
+			log.Printf("failed to apply rules to transactions of statement %s: %v", statement.ID, err)
+		}
+	}
+	if s.categorizer != nil {
+		if _, err := s.categorizer.Categorize(ctx, statement.UserID, statement.Transactions); err != nil {
+			log.Printf("failed to categorize transactions of statement %s: %v", statement.ID, err)
+		}
+	}
+	reconcile(statement)
+	return nil
+}
+
+// extractText runs the configured TextExtractor and falls back to OCR when
+// the result is empty or too sparse to be a text-based statement
+func (s *PDFService) extractText(ctx context.Context, content []byte, password string) (string, error) {
+	text, err := s.textExtractor.Extract(ctx, content, password)
+	if err != nil {
+		return "", err
+	}
+
+	if s.ocrExtractor != nil && textDensity(text) < s.minTextDensity {
+		ocrText, ocrErr := s.ocrExtractor.Extract(ctx, content, password)
+		if ocrErr != nil {
+			if strings.TrimSpace(text) == "" {
+				return "", fmt.Errorf("failed to OCR PDF: %w", ocrErr)
+			}
+		} else if textDensity(ocrText) > textDensity(text) {
+			text = ocrText
+		}
+	}
+
+	if strings.TrimSpace(text) == "" {
+		return "", ErrNoTextExtracted
+	}
+
+	return text, nil
+}
+
+// textDensity returns the average number of non-whitespace characters per
+// page, where pages are separated by form feeds as pdftotext emits them
+func textDensity(text string) int {
+	chars := 0
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			chars++
+		}
+	}
+	pages := strings.Count(strings.TrimSpace(text), "\f") + 1
+	return chars / pages
+}
+
+// statementID derives the ID of a statement from its owner and file, so an
+// upload of the same file can be rejected atomically
+func statementID(userID, fileHash string) string {
+	sum := sha256.Sum256([]byte(userID + "|" + fileHash))
+	return hex.EncodeToString(sum[:16])
+}
 
-	return transactions, nil
+// fileHash returns the hex-encoded SHA-256 of the uploaded PDF
+func fileHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
 }
@@ -3,69 +3,213 @@ package service
 import (
 	"context"
 	"errors"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/tsongpon/helios/internal/model"
 )
 
 type mockLLMRepository struct {
-	transactions []model.Transaction
+	statement    model.Statement
 	err          error
 	receivedText string
 }
 
-func (m *mockLLMRepository) ParseStatement(statementText string) ([]model.Transaction, error) {
+func (m *mockLLMRepository) ParseStatement(ctx context.Context, statementText string) (model.Statement, error) {
 	m.receivedText = statementText
+	if m.err != nil {
+		return model.Statement{}, m.err
+	}
+	return m.statement, nil
+}
+
+type mockStatementRepository struct {
+	statements     []model.Statement
+	err            error
+	savedStatement *model.Statement
+	deletedID      string
+
+	reconciliationStatus model.ReconciliationStatus
+	reconciliationDelta  float64
+}
+
+func (m *mockStatementRepository) UpdateReconciliation(ctx context.Context, statementID string, status model.ReconciliationStatus, delta float64) error {
+	m.reconciliationStatus = status
+	m.reconciliationDelta = delta
+	return m.err
+}
+
+func (m *mockStatementRepository) Create(ctx context.Context, statement model.Statement) error {
+	for _, existing := range m.statements {
+		if existing.ID == statement.ID {
+			return model.ErrAlreadyExists
+		}
+	}
+	m.savedStatement = &statement
+	return m.err
+}
+
+func (m *mockStatementRepository) Save(ctx context.Context, statement model.Statement) error {
+	m.savedStatement = &statement
+	return m.err
+}
+
+func (m *mockStatementRepository) GetStatement(ctx context.Context, statementID string) (model.Statement, error) {
+	for _, statement := range m.statements {
+		if statement.ID == statementID {
+			return statement, nil
+		}
+	}
+	return model.Statement{}, model.ErrNotFound
+}
+
+func (m *mockStatementRepository) GetStatements(ctx context.Context, userID string) ([]model.Statement, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
-	return m.transactions, nil
+	var statements []model.Statement
+	for _, statement := range m.statements {
+		if statement.UserID == userID {
+			statements = append(statements, statement)
+		}
+	}
+	return statements, nil
+}
+
+func (m *mockStatementRepository) GetStatementByFileHash(ctx context.Context, userID, fileHash string) (model.Statement, error) {
+	for _, statement := range m.statements {
+		if statement.UserID == userID && statement.FileHash == fileHash {
+			return statement, nil
+		}
+	}
+	return model.Statement{}, model.ErrNotFound
+}
+
+func (m *mockStatementRepository) Delete(ctx context.Context, statementID string) error {
+	m.deletedID = statementID
+	return m.err
+}
+
+type mockTextExtractor struct {
+	text             string
+	err              error
+	receivedPDF      []byte
+	receivedPassword string
+}
+
+func (m *mockTextExtractor) Extract(ctx context.Context, pdf []byte, password string) (string, error) {
+	m.receivedPDF = pdf
+	m.receivedPassword = password
+	if m.err != nil {
+		return "", m.err
+	}
+	return m.text, nil
 }
 
 func TestPDFService_NewPDFService(t *testing.T) {
+	mockExtractor := &mockTextExtractor{}
 	mockLLM := &mockLLMRepository{}
+	mockStmtRepo := &mockStatementRepository{}
 	mockTxnRepo := &mockTransactionRepository{}
 
-	svc := NewPDFService(mockLLM, mockTxnRepo)
+	svc := NewPDFService(mockExtractor, mockLLM, mockStmtRepo, mockTxnRepo)
 
 	if svc == nil {
 		t.Fatal("expected non-nil service")
 	}
 
+	if svc.textExtractor != mockExtractor {
+		t.Error("textExtractor not set correctly")
+	}
+
 	if svc.llmRepository != mockLLM {
 		t.Error("llmRepository not set correctly")
 	}
 
+	if svc.statementRepository != mockStmtRepo {
+		t.Error("statementRepository not set correctly")
+	}
+
 	if svc.transactionRepository != mockTxnRepo {
 		t.Error("transactionRepository not set correctly")
 	}
 }
 
+func TestPDFService_ExtractText_PassesContentToExtractor(t *testing.T) {
+	mockExtractor := &mockTextExtractor{text: "statement text"}
+	mockLLM := &mockLLMRepository{}
+	mockTxnRepo := &mockTransactionRepository{}
+
+	svc := NewPDFService(mockExtractor, mockLLM, &mockStatementRepository{}, mockTxnRepo)
+
+	_, err := svc.ExtractText(context.Background(), "user123", strings.NewReader("fake pdf content"), "secret")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if string(mockExtractor.receivedPDF) != "fake pdf content" {
+		t.Errorf("expected extractor to receive PDF content, got %q", mockExtractor.receivedPDF)
+	}
+	if mockExtractor.receivedPassword != "secret" {
+		t.Errorf("expected extractor to receive password, got %q", mockExtractor.receivedPassword)
+	}
+	if mockLLM.receivedText != "statement text" {
+		t.Errorf("expected LLM to receive extracted text, got %q", mockLLM.receivedText)
+	}
+}
+
+func TestPDFService_ExtractText_ExtractorError(t *testing.T) {
+	mockExtractor := &mockTextExtractor{err: errors.New("Incorrect password")}
+	mockLLM := &mockLLMRepository{}
+	mockTxnRepo := &mockTransactionRepository{}
+
+	svc := NewPDFService(mockExtractor, mockLLM, &mockStatementRepository{}, mockTxnRepo)
+
+	_, err := svc.ExtractText(context.Background(), "user123", strings.NewReader("fake pdf content"), "")
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if err.Error() != "Incorrect password" {
+		t.Errorf("unexpected error message: %s", err.Error())
+	}
+	if mockLLM.receivedText != "" {
+		t.Error("LLM should not be called when extraction fails")
+	}
+}
+
 func TestPDFService_ExtractText_LLMParseError(t *testing.T) {
+	mockExtractor := &mockTextExtractor{text: "statement text"}
 	mockLLM := &mockLLMRepository{
 		err: errors.New("LLM parsing failed"),
 	}
 	mockTxnRepo := &mockTransactionRepository{}
 
-	svc := NewPDFService(mockLLM, mockTxnRepo)
+	svc := NewPDFService(mockExtractor, mockLLM, &mockStatementRepository{}, mockTxnRepo)
 
-	// Note: This test would require pdftotext to be installed and a valid PDF file.
-	// For unit testing purposes, we focus on testing the error handling paths
-	// that don't require external dependencies.
-
-	// Test that the service is properly constructed
-	if svc.llmRepository == nil {
-		t.Error("llmRepository should not be nil")
+	_, err := svc.ExtractText(context.Background(), "user123", strings.NewReader("fake pdf content"), "")
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if err.Error() != "failed to parse statement: LLM parsing failed" {
+		t.Errorf("unexpected error message: %s", err.Error())
+	}
+	if mockTxnRepo.savedTxns != nil {
+		t.Error("transactions should not be saved when parsing fails")
 	}
 }
 
 func TestPDFService_ExtractText_SaveError(t *testing.T) {
+	mockExtractor := &mockTextExtractor{text: "statement text"}
 	mockLLM := &mockLLMRepository{
-		transactions: []model.Transaction{
-			{
-				TransactionDate: "2024-12-15",
-				Description:     "TEST",
-				Amount:          100.00,
+		statement: model.Statement{
+			Transactions: []model.Transaction{
+				{
+					TransactionDate: "2024-12-15",
+					Description:     "TEST",
+					Amount:          100.00,
+				},
 			},
 		},
 	}
@@ -73,19 +217,18 @@ func TestPDFService_ExtractText_SaveError(t *testing.T) {
 		err: errors.New("failed to save"),
 	}
 
-	svc := NewPDFService(mockLLM, mockTxnRepo)
+	svc := NewPDFService(mockExtractor, mockLLM, &mockStatementRepository{}, mockTxnRepo)
 
-	// Note: Full integration test would require pdftotext binary
-	// This validates the service construction with error-returning mocks
-	if svc.transactionRepository == nil {
-		t.Error("transactionRepository should not be nil")
+	_, err := svc.ExtractText(context.Background(), "user123", strings.NewReader("fake pdf content"), "")
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if err.Error() != "failed to save transactions: failed to save" {
+		t.Errorf("unexpected error message: %s", err.Error())
 	}
 }
 
 func TestPDFService_SetsUserIDOnTransactions(t *testing.T) {
-	// This test validates the logic that sets UserID on parsed transactions
-	// In a real scenario, we'd need to mock the pdftotext command
-
 	ctx := context.Background()
 	userID := "test-user-123"
 
@@ -94,16 +237,655 @@ func TestPDFService_SetsUserIDOnTransactions(t *testing.T) {
 		{TransactionDate: "2024-12-16", Description: "TEST2", Amount: 200.00},
 	}
 
+	mockExtractor := &mockTextExtractor{text: "statement text"}
+	mockLLM := &mockLLMRepository{
+		statement: model.Statement{Transactions: transactions},
+	}
+	mockTxnRepo := &mockTransactionRepository{}
+
+	svc := NewPDFService(mockExtractor, mockLLM, &mockStatementRepository{}, mockTxnRepo)
+
+	result, err := svc.ExtractText(ctx, userID, strings.NewReader("fake pdf content"), "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(result.Transactions) != 2 {
+		t.Fatalf("expected 2 transactions, got %d", len(result.Transactions))
+	}
+	for i, txn := range result.Transactions {
+		if txn.UserID != userID {
+			t.Errorf("transaction %d: expected UserID %q, got %q", i, userID, txn.UserID)
+		}
+	}
+	if len(mockTxnRepo.savedTxns) != 2 {
+		t.Fatalf("expected 2 saved transactions, got %d", len(mockTxnRepo.savedTxns))
+	}
+	for i, txn := range mockTxnRepo.savedTxns {
+		if txn.UserID != userID {
+			t.Errorf("saved transaction %d: expected UserID %q, got %q", i, userID, txn.UserID)
+		}
+	}
+}
+
+func TestPDFService_ExtractText_OCRFallback(t *testing.T) {
+	denseText := strings.Repeat("KTC CREDIT CARD STATEMENT ", 10)
+
+	t.Run("uses OCR when extracted text is empty", func(t *testing.T) {
+		mockExtractor := &mockTextExtractor{text: ""}
+		mockOCR := &mockTextExtractor{text: denseText}
+		mockLLM := &mockLLMRepository{}
+		mockTxnRepo := &mockTransactionRepository{}
+
+		svc := NewPDFService(mockExtractor, mockLLM, &mockStatementRepository{}, mockTxnRepo).WithOCR(mockOCR, DefaultMinTextDensity)
+
+		_, err := svc.ExtractText(context.Background(), "user123", strings.NewReader("fake pdf content"), "secret")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if mockOCR.receivedPassword != "secret" {
+			t.Errorf("expected OCR to receive password, got %q", mockOCR.receivedPassword)
+		}
+		if mockLLM.receivedText != denseText {
+			t.Errorf("expected LLM to receive OCR text, got %q", mockLLM.receivedText)
+		}
+	})
+
+	t.Run("skips OCR when extracted text is dense enough", func(t *testing.T) {
+		mockExtractor := &mockTextExtractor{text: denseText}
+		mockOCR := &mockTextExtractor{text: "ocr text"}
+		mockLLM := &mockLLMRepository{}
+		mockTxnRepo := &mockTransactionRepository{}
+
+		svc := NewPDFService(mockExtractor, mockLLM, &mockStatementRepository{}, mockTxnRepo).WithOCR(mockOCR, DefaultMinTextDensity)
+
+		_, err := svc.ExtractText(context.Background(), "user123", strings.NewReader("fake pdf content"), "")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if mockOCR.receivedPDF != nil {
+			t.Error("OCR should not run for dense text")
+		}
+		if mockLLM.receivedText != denseText {
+			t.Errorf("expected LLM to receive extracted text, got %q", mockLLM.receivedText)
+		}
+	})
+
+	t.Run("keeps sparse text when OCR fails", func(t *testing.T) {
+		mockExtractor := &mockTextExtractor{text: "sparse"}
+		mockOCR := &mockTextExtractor{err: errors.New("tesseract not found")}
+		mockLLM := &mockLLMRepository{}
+		mockTxnRepo := &mockTransactionRepository{}
+
+		svc := NewPDFService(mockExtractor, mockLLM, &mockStatementRepository{}, mockTxnRepo).WithOCR(mockOCR, DefaultMinTextDensity)
+
+		_, err := svc.ExtractText(context.Background(), "user123", strings.NewReader("fake pdf content"), "")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if mockLLM.receivedText != "sparse" {
+			t.Errorf("expected LLM to receive extracted text, got %q", mockLLM.receivedText)
+		}
+	})
+
+	t.Run("returns error when OCR fails on empty text", func(t *testing.T) {
+		mockExtractor := &mockTextExtractor{text: ""}
+		mockOCR := &mockTextExtractor{err: errors.New("tesseract not found")}
+		mockLLM := &mockLLMRepository{}
+		mockTxnRepo := &mockTransactionRepository{}
+
+		svc := NewPDFService(mockExtractor, mockLLM, &mockStatementRepository{}, mockTxnRepo).WithOCR(mockOCR, DefaultMinTextDensity)
+
+		_, err := svc.ExtractText(context.Background(), "user123", strings.NewReader("fake pdf content"), "")
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if err.Error() != "failed to OCR PDF: tesseract not found" {
+			t.Errorf("unexpected error message: %s", err.Error())
+		}
+	})
+
+	t.Run("returns error when no text is found", func(t *testing.T) {
+		mockExtractor := &mockTextExtractor{text: "  \n\f "}
+		mockOCR := &mockTextExtractor{text: ""}
+		mockLLM := &mockLLMRepository{}
+		mockTxnRepo := &mockTransactionRepository{}
+
+		svc := NewPDFService(mockExtractor, mockLLM, &mockStatementRepository{}, mockTxnRepo).WithOCR(mockOCR, DefaultMinTextDensity)
+
+		_, err := svc.ExtractText(context.Background(), "user123", strings.NewReader("fake pdf content"), "")
+		if !errors.Is(err, ErrNoTextExtracted) {
+			t.Fatalf("expected ErrNoTextExtracted, got %v", err)
+		}
+		if mockLLM.receivedText != "" {
+			t.Error("LLM should not be called when no text is extracted")
+		}
+	})
+}
+
+func TestTextDensity(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{name: "empty", text: "", want: 0},
+		{name: "whitespace only", text: " \n\t ", want: 0},
+		{name: "single page", text: "AB CD\nEF", want: 6},
+		{name: "averages across pages", text: "ABCD\fEF", want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := textDensity(tt.text); got != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPDFService_ExtractText_SavesStatement(t *testing.T) {
+	mockExtractor := &mockTextExtractor{text: "statement text"}
 	mockLLM := &mockLLMRepository{
-		transactions: transactions,
+		statement: model.Statement{
+			Bank:           "KTC",
+			CardNumber:     "1234-XXXX-XXXX-5678",
+			PaymentDueDate: "2025-02-06",
+			TotalPayment:   300.00,
+			Transactions: []model.Transaction{
+				{TransactionDate: "2024-12-15", Description: "TEST1", Amount: 100.00},
+				{TransactionDate: "2024-12-16", Description: "TEST2", Amount: 200.00},
+			},
+		},
 	}
+	mockStmtRepo := &mockStatementRepository{}
 	mockTxnRepo := &mockTransactionRepository{}
 
-	svc := NewPDFService(mockLLM, mockTxnRepo)
+	svc := NewPDFService(mockExtractor, mockLLM, mockStmtRepo, mockTxnRepo)
+
+	statement, err := svc.ExtractText(context.Background(), "user123", strings.NewReader("fake pdf content"), "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if statement.ID == "" {
+		t.Fatal("expected statement ID to be set")
+	}
+	if statement.UserID != "user123" {
+		t.Errorf("expected UserID user123, got %s", statement.UserID)
+	}
+	if statement.FileHash != fileHash([]byte("fake pdf content")) {
+		t.Errorf("unexpected file hash %s", statement.FileHash)
+	}
+	if statement.CreatedAt.IsZero() {
+		t.Error("expected CreatedAt to be set")
+	}
 
-	// We can't easily test ExtractText without pdftotext installed,
-	// but we can verify the service is constructed correctly
-	_ = ctx
-	_ = userID
-	_ = svc
+	if mockStmtRepo.savedStatement == nil {
+		t.Fatal("expected statement to be saved")
+	}
+	if mockStmtRepo.savedStatement.ID != statement.ID {
+		t.Errorf("expected saved statement ID %s, got %s", statement.ID, mockStmtRepo.savedStatement.ID)
+	}
+	if mockStmtRepo.savedStatement.Bank != "KTC" {
+		t.Errorf("expected saved bank KTC, got %s", mockStmtRepo.savedStatement.Bank)
+	}
+	if mockStmtRepo.savedStatement.ReconciliationStatus != model.ReconciliationMatched {
+		t.Errorf("expected saved reconciliation status matched, got %s", mockStmtRepo.savedStatement.ReconciliationStatus)
+	}
+
+	for i, txn := range mockTxnRepo.savedTxns {
+		if txn.StatementID != statement.ID {
+			t.Errorf("saved transaction %d: expected StatementID %s, got %s", i, statement.ID, txn.StatementID)
+		}
+		if txn.ID == "" {
+			t.Errorf("saved transaction %d: expected ID to be set", i)
+		}
+	}
+}
+
+func TestPDFService_ExtractText_StatementSaveError(t *testing.T) {
+	mockExtractor := &mockTextExtractor{text: "statement text"}
+	mockLLM := &mockLLMRepository{}
+	mockStmtRepo := &mockStatementRepository{err: errors.New("failed to save")}
+	mockTxnRepo := &mockTransactionRepository{}
+
+	svc := NewPDFService(mockExtractor, mockLLM, mockStmtRepo, mockTxnRepo)
+
+	_, err := svc.ExtractText(context.Background(), "user123", strings.NewReader("fake pdf content"), "")
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if err.Error() != "failed to save statement: failed to save" {
+		t.Errorf("unexpected error message: %s", err.Error())
+	}
+	if mockTxnRepo.savedTxns != nil {
+		t.Error("transactions should not be saved when the statement fails to save")
+	}
+}
+
+func TestFileHash(t *testing.T) {
+	// SHA-256 of "abc"
+	expected := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	if got := fileHash([]byte("abc")); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestPDFService_ReparseStatement(t *testing.T) {
+	createdAt := time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)
+	existing := model.Statement{
+		ID:         "stmt-1",
+		UserID:     "user123",
+		FileHash:   "abc123",
+		SourceText: "stored statement text",
+		CreatedAt:  createdAt,
+	}
+
+	t.Run("replaces transactions with new parse result", func(t *testing.T) {
+		mockLLM := &mockLLMRepository{
+			statement: model.Statement{
+				Bank: "KTC",
+				Transactions: []model.Transaction{
+					{TransactionDate: "2024-12-15", Description: "TEST1", Amount: 100.00},
+				},
+			},
+		}
+		mockStmtRepo := &mockStatementRepository{statements: []model.Statement{existing}}
+		mockTxnRepo := &mockTransactionRepository{transactions: []model.Transaction{
+			{ID: "old-1", UserID: "user123", StatementID: "stmt-1", Description: "TEST0"},
+		}}
+
+		svc := NewPDFService(&mockTextExtractor{}, mockLLM, mockStmtRepo, mockTxnRepo)
+
+		statement, err := svc.ReparseStatement(context.Background(), "user123", "stmt-1")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if mockLLM.receivedText != "stored statement text" {
+			t.Errorf("expected LLM to receive stored text, got %q", mockLLM.receivedText)
+		}
+		if statement.ID != "stmt-1" || statement.FileHash != "abc123" || !statement.CreatedAt.Equal(createdAt) {
+			t.Errorf("expected identity fields to be preserved, got %+v", statement)
+		}
+		if statement.Bank != "KTC" {
+			t.Errorf("expected Bank KTC, got %s", statement.Bank)
+		}
+		if !slices.Equal(mockTxnRepo.deletedIDs, []string{"old-1"}) {
+			t.Errorf("expected the stale transaction of stmt-1 to be deleted, got %v", mockTxnRepo.deletedIDs)
+		}
+		if len(mockTxnRepo.savedTxns) != 1 {
+			t.Fatalf("expected 1 saved transaction, got %d", len(mockTxnRepo.savedTxns))
+		}
+		if mockTxnRepo.savedTxns[0].StatementID != "stmt-1" || mockTxnRepo.savedTxns[0].UserID != "user123" {
+			t.Errorf("expected saved transaction to be linked, got %+v", mockTxnRepo.savedTxns[0])
+		}
+	})
+
+	t.Run("preserves user edits of transactions", func(t *testing.T) {
+		parsed := model.Transaction{TransactionDate: "2024-12-15", Description: "TEST1", Amount: 100.00}
+		mockLLM := &mockLLMRepository{
+			statement: model.Statement{Transactions: []model.Transaction{parsed}},
+		}
+
+		// The previously saved transaction has the ID the new parse will get
+		edited := parsed
+		edited.UserID = "user123"
+		edited.StatementID = "stmt-1"
+		ids := []model.Transaction{edited}
+		assignTransactionIDs(ids)
+		edited.ID = ids[0].ID
+		notes := "shared with Ann"
+		edited.ApplyOverrides(model.TransactionPatch{Notes: &notes})
+
+		mockStmtRepo := &mockStatementRepository{statements: []model.Statement{existing}}
+		mockTxnRepo := &mockTransactionRepository{transactions: []model.Transaction{edited}}
+
+		svc := NewPDFService(&mockTextExtractor{}, mockLLM, mockStmtRepo, mockTxnRepo)
+
+		if _, err := svc.ReparseStatement(context.Background(), "user123", "stmt-1"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(mockTxnRepo.savedTxns) != 1 || mockTxnRepo.savedTxns[0].Notes != notes {
+			t.Errorf("expected notes to survive the reparse, got %+v", mockTxnRepo.savedTxns)
+		}
+		if mockTxnRepo.deletedIDs != nil {
+			t.Errorf("expected the reparsed transaction to be kept, got deleted %v", mockTxnRepo.deletedIDs)
+		}
+	})
+
+	t.Run("returns not found for another user's statement", func(t *testing.T) {
+		mockLLM := &mockLLMRepository{}
+		mockStmtRepo := &mockStatementRepository{statements: []model.Statement{existing}}
+		mockTxnRepo := &mockTransactionRepository{}
+
+		svc := NewPDFService(&mockTextExtractor{}, mockLLM, mockStmtRepo, mockTxnRepo)
+
+		_, err := svc.ReparseStatement(context.Background(), "someone-else", "stmt-1")
+		if !errors.Is(err, model.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+		if mockTxnRepo.deletedIDs != nil {
+			t.Error("transactions should not be deleted")
+		}
+	})
+
+	t.Run("keeps transactions when parsing fails", func(t *testing.T) {
+		mockLLM := &mockLLMRepository{err: errors.New("LLM parsing failed")}
+		mockStmtRepo := &mockStatementRepository{statements: []model.Statement{existing}}
+		mockTxnRepo := &mockTransactionRepository{}
+
+		svc := NewPDFService(&mockTextExtractor{}, mockLLM, mockStmtRepo, mockTxnRepo)
+
+		_, err := svc.ReparseStatement(context.Background(), "user123", "stmt-1")
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if mockTxnRepo.deletedIDs != nil {
+			t.Error("transactions should not be deleted when parsing fails")
+		}
+	})
+
+	t.Run("keeps transaction IDs of statements parsed without redaction", func(t *testing.T) {
+		parsed := model.Transaction{CardNumber: "4111 1111 1111 1111", TransactionDate: "2024-12-15", Description: "TEST1", Amount: 100.00}
+		uploaded := model.Statement{ID: "stmt-1", UserID: "user123", Transactions: []model.Transaction{parsed}}
+		if err := NewPDFService(&mockTextExtractor{}, &mockLLMRepository{}, &mockStatementRepository{}, &mockTransactionRepository{}).
+			prepareStatement(context.Background(), &uploaded, nil); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		// The source text is stored with the card number masked
+		reparsed := parsed
+		reparsed.CardNumber = "4111 11XX XXXX 1111"
+		mockLLM := &mockLLMRepository{statement: model.Statement{Transactions: []model.Transaction{reparsed}}}
+		mockStmtRepo := &mockStatementRepository{statements: []model.Statement{existing}}
+		mockTxnRepo := &mockTransactionRepository{transactions: uploaded.Transactions}
+
+		svc := NewPDFService(&mockTextExtractor{}, mockLLM, mockStmtRepo, mockTxnRepo)
+
+		if _, err := svc.ReparseStatement(context.Background(), "user123", "stmt-1"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(mockTxnRepo.savedTxns) != 1 || mockTxnRepo.savedTxns[0].ID != uploaded.Transactions[0].ID {
+			t.Errorf("expected the transaction ID %s to be kept, got %+v", uploaded.Transactions[0].ID, mockTxnRepo.savedTxns)
+		}
+		if mockTxnRepo.deletedIDs != nil {
+			t.Errorf("expected no transactions to be deleted, got %v", mockTxnRepo.deletedIDs)
+		}
+	})
+
+	t.Run("keeps transactions when saving fails", func(t *testing.T) {
+		mockLLM := &mockLLMRepository{
+			statement: model.Statement{Transactions: []model.Transaction{
+				{TransactionDate: "2024-12-15", Description: "TEST1", Amount: 100.00},
+			}},
+		}
+		mockStmtRepo := &mockStatementRepository{statements: []model.Statement{existing}}
+		mockTxnRepo := &mockTransactionRepository{
+			transactions: []model.Transaction{{ID: "old-1", UserID: "user123", StatementID: "stmt-1"}},
+			saveErr:      errors.New("too many writes"),
+		}
+
+		svc := NewPDFService(&mockTextExtractor{}, mockLLM, mockStmtRepo, mockTxnRepo)
+
+		if _, err := svc.ReparseStatement(context.Background(), "user123", "stmt-1"); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if mockTxnRepo.deletedIDs != nil || mockTxnRepo.deletedStatementID != "" {
+			t.Error("transactions should not be deleted when saving fails")
+		}
+	})
+}
+
+func TestPDFService_ExtractText_DuplicateUpload(t *testing.T) {
+	content := "fake pdf content"
+
+	t.Run("returns existing statement for repeat upload", func(t *testing.T) {
+		mockExtractor := &mockTextExtractor{text: "statement text"}
+		mockLLM := &mockLLMRepository{}
+		mockStmtRepo := &mockStatementRepository{
+			statements: []model.Statement{
+				{ID: "stmt-1", UserID: "user123", FileHash: fileHash([]byte(content))},
+			},
+		}
+		mockTxnRepo := &mockTransactionRepository{}
+
+		svc := NewPDFService(mockExtractor, mockLLM, mockStmtRepo, mockTxnRepo)
+
+		_, err := svc.ExtractText(context.Background(), "user123", strings.NewReader(content), "")
+
+		var duplicate *model.DuplicateStatementError
+		if !errors.As(err, &duplicate) {
+			t.Fatalf("expected DuplicateStatementError, got %v", err)
+		}
+		if duplicate.StatementID != "stmt-1" {
+			t.Errorf("expected statement ID stmt-1, got %s", duplicate.StatementID)
+		}
+		if mockExtractor.receivedPDF != nil {
+			t.Error("text should not be extracted for a duplicate upload")
+		}
+		if mockLLM.receivedText != "" {
+			t.Error("LLM should not be called for a duplicate upload")
+		}
+		if mockStmtRepo.savedStatement != nil || mockTxnRepo.savedTxns != nil {
+			t.Error("nothing should be saved for a duplicate upload")
+		}
+	})
+
+	t.Run("rejects a concurrent upload of the same file", func(t *testing.T) {
+		mockStmtRepo := &mockStatementRepository{}
+		mockTxnRepo := &mockTransactionRepository{}
+		svc := NewPDFService(&mockTextExtractor{text: "statement text"}, &mockLLMRepository{}, mockStmtRepo, mockTxnRepo)
+
+		first, err := svc.ExtractText(context.Background(), "user123", strings.NewReader(content), "")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		// The other upload passed the duplicate check before the first was saved
+		mockStmtRepo.statements = []model.Statement{{ID: first.ID, UserID: "user123"}}
+
+		_, err = svc.ExtractText(context.Background(), "user123", strings.NewReader(content), "")
+		var duplicate *model.DuplicateStatementError
+		if !errors.As(err, &duplicate) || duplicate.StatementID != first.ID {
+			t.Fatalf("expected DuplicateStatementError for %s, got %v", first.ID, err)
+		}
+	})
+
+	t.Run("allows uploading again after transactions failed to save", func(t *testing.T) {
+		mockStmtRepo := &mockStatementRepository{}
+		mockTxnRepo := &mockTransactionRepository{err: errors.New("database error")}
+		svc := NewPDFService(&mockTextExtractor{text: "statement text"}, &mockLLMRepository{}, mockStmtRepo, mockTxnRepo)
+
+		if _, err := svc.ExtractText(context.Background(), "user123", strings.NewReader(content), ""); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if mockStmtRepo.savedStatement != nil {
+			t.Error("statement should not be saved when its transactions are not")
+		}
+	})
+
+	t.Run("processes same file uploaded by another user", func(t *testing.T) {
+		mockExtractor := &mockTextExtractor{text: "statement text"}
+		mockLLM := &mockLLMRepository{}
+		mockStmtRepo := &mockStatementRepository{
+			statements: []model.Statement{
+				{ID: "stmt-1", UserID: "someone-else", FileHash: fileHash([]byte(content))},
+			},
+		}
+		mockTxnRepo := &mockTransactionRepository{}
+
+		svc := NewPDFService(mockExtractor, mockLLM, mockStmtRepo, mockTxnRepo)
+
+		_, err := svc.ExtractText(context.Background(), "user123", strings.NewReader(content), "")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if mockStmtRepo.savedStatement == nil {
+			t.Error("expected statement to be saved")
+		}
+	})
+}
+
+func TestPDFService_ExtractText_RedactsPII(t *testing.T) {
+	mockExtractor := &mockTextExtractor{text: redactionTestText}
+	mockLLM := &mockLLMRepository{
+		statement: model.Statement{
+			CardNumber:   "[CARD_1]",
+			Transactions: []model.Transaction{{CardNumber: "[CARD_1]", Description: "CALL CENTER [PHONE_2]", Amount: 250}},
+		},
+	}
+	mockStmtRepo := &mockStatementRepository{}
+
+	svc := NewPDFService(mockExtractor, mockLLM, mockStmtRepo, &mockTransactionRepository{}).
+		WithRedaction(NewPIIRedactor())
+
+	result, err := svc.ExtractText(context.Background(), "user-1", strings.NewReader("fake pdf content"), "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if strings.Contains(mockLLM.receivedText, "4111 1111 1111 1111") || strings.Contains(mockLLM.receivedText, "JOHN SMITH") {
+		t.Errorf("expected LLM to receive redacted text, got:\n%s", mockLLM.receivedText)
+	}
+	if result.CardNumber != "4111 11XX XXXX 1111" {
+		t.Errorf("expected masked card number, got %s", result.CardNumber)
+	}
+	if result.Transactions[0].Description != "CALL CENTER 02-123-4567" {
+		t.Errorf("expected rehydrated description, got %s", result.Transactions[0].Description)
+	}
+	for _, pii := range []string{"JOHN SMITH", "4111 1111 1111 1111", "SUKHUMVIT", "10110", "1-1017-00123-45-6"} {
+		if strings.Contains(mockStmtRepo.savedStatement.SourceText, pii) {
+			t.Errorf("expected %q not to be stored, got:\n%s", pii, mockStmtRepo.savedStatement.SourceText)
+		}
+	}
+	if !strings.Contains(mockStmtRepo.savedStatement.SourceText, "CARD NO. 4111 11XX XXXX 1111") {
+		t.Errorf("expected masked card number in source text, got:\n%s", mockStmtRepo.savedStatement.SourceText)
+	}
+
+	// Stored text is masked even when redaction for the LLM is off
+	mockStmtRepo = &mockStatementRepository{}
+	svc = NewPDFService(&mockTextExtractor{text: redactionTestText}, &mockLLMRepository{}, mockStmtRepo, &mockTransactionRepository{})
+	if _, err := svc.ExtractText(context.Background(), "user-1", strings.NewReader("fake pdf content"), ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Contains(mockStmtRepo.savedStatement.SourceText, "JOHN SMITH") {
+		t.Errorf("expected names not to be stored, got:\n%s", mockStmtRepo.savedStatement.SourceText)
+	}
+}
+
+func TestPDFService_ExtractText_LayoutParser(t *testing.T) {
+	t.Run("known layout skips the LLM", func(t *testing.T) {
+		mockLLM := &mockLLMRepository{}
+		mockStmtRepo := &mockStatementRepository{}
+		svc := NewPDFService(&mockTextExtractor{text: ktcLayoutText}, mockLLM, mockStmtRepo, &mockTransactionRepository{}).
+			WithLayoutParsers(DefaultLayoutRegistry())
+
+		result, err := svc.ExtractText(context.Background(), "user-1", strings.NewReader("fake pdf content"), "")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if mockLLM.receivedText != "" {
+			t.Error("expected LLM not to be called")
+		}
+		if len(result.Transactions) != 2 || result.ReconciliationStatus != model.ReconciliationMatched {
+			t.Errorf("unexpected result: %+v", result)
+		}
+		if mockStmtRepo.savedStatement.Model != "layout:ktc.v1" {
+			t.Errorf("expected layout parser to be recorded, got %s", mockStmtRepo.savedStatement.Model)
+		}
+	})
+
+	t.Run("unknown layout falls back to the LLM", func(t *testing.T) {
+		mockLLM := &mockLLMRepository{statement: model.Statement{Bank: "OTHER"}}
+		svc := NewPDFService(&mockTextExtractor{text: "OTHER BANK STATEMENT"}, mockLLM, &mockStatementRepository{}, &mockTransactionRepository{}).
+			WithLayoutParsers(DefaultLayoutRegistry())
+
+		result, err := svc.ExtractText(context.Background(), "user-1", strings.NewReader("fake pdf content"), "")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if mockLLM.receivedText != "OTHER BANK STATEMENT" || result.Bank != "OTHER" {
+			t.Error("expected LLM to parse the statement")
+		}
+	})
+}
+
+func TestPDFService_ExtractText_KeepsOwnerOfOverlappingTransactions(t *testing.T) {
+	parsed := []model.Transaction{
+		{TransactionDate: "2024-12-15", Description: "GRAB", Amount: 100.00},
+		{TransactionDate: "2025-01-05", Description: "LAZADA", Amount: 200.00},
+	}
+
+	// The first transaction was saved with the previous month's statement
+	shared := parsed[0]
+	shared.UserID = "user-1"
+	ids := []model.Transaction{shared}
+	assignTransactionIDs(ids)
+	shared.ID = ids[0].ID
+	shared.StatementID = "stmt-december"
+
+	txnRepo := &mockTransactionRepository{transactions: []model.Transaction{shared}}
+	mockLLM := &mockLLMRepository{statement: model.Statement{Transactions: slices.Clone(parsed)}}
+	svc := NewPDFService(&mockTextExtractor{text: "statement text"}, mockLLM, &mockStatementRepository{}, txnRepo)
+
+	statement, err := svc.ExtractText(context.Background(), "user-1", strings.NewReader("pdf"), "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(txnRepo.savedTxns) != 2 {
+		t.Fatalf("expected 2 saved transactions, got %+v", txnRepo.savedTxns)
+	}
+	if txnRepo.savedTxns[0].ID != shared.ID || txnRepo.savedTxns[0].StatementID != "stmt-december" {
+		t.Errorf("expected the shared transaction to stay with its first statement, got %+v", txnRepo.savedTxns[0])
+	}
+	if txnRepo.savedTxns[1].StatementID != statement.ID {
+		t.Errorf("expected the new transaction to belong to %s, got %+v", statement.ID, txnRepo.savedTxns[1])
+	}
+}
+
+func TestPDFService_ExtractText_EnrichesTransactions(t *testing.T) {
+	ctx := context.Background()
+	newService := func(classifier *mockClassifier, txnRepo *mockTransactionRepository) *PDFService {
+		mockLLM := &mockLLMRepository{statement: model.Statement{Transactions: []model.Transaction{
+			{TransactionDate: "2024-12-15", Description: "GRAB", Amount: 100.00},
+			{TransactionDate: "2024-12-16", Description: "LAZADA", Amount: 200.00},
+		}}}
+		rules := &mockCategoryRuleRepository{rules: []model.CategoryRule{{Pattern: "grab", Category: "transport"}}}
+		userRules := &mockRuleRepository{rules: []model.Rule{{
+			ID:        "rule-1",
+			Condition: `merchant == "Grab" and amount >= 100`,
+			Actions:   model.RuleActions{AddTags: []string{"work"}, MarkBusiness: true},
+		}}}
+		return NewPDFService(&mockTextExtractor{text: "statement text"}, mockLLM, &mockStatementRepository{}, txnRepo).
+			WithMerchantNormalizer(DefaultMerchantNormalizer()).
+			WithRules(NewRuleService(userRules, txnRepo)).
+			WithCategorizer(NewCategorizationService(rules, txnRepo, classifier))
+	}
+
+	txnRepo := &mockTransactionRepository{}
+	if _, err := newService(&mockClassifier{category: "shopping"}, txnRepo).ExtractText(ctx, "user-1", strings.NewReader("pdf"), ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if txnRepo.savedTxns[0].Category != "transport" || txnRepo.savedTxns[1].Category != "shopping" {
+		t.Errorf("expected saved transactions to be categorized, got %+v", txnRepo.savedTxns)
+	}
+	if txnRepo.savedTxns[0].Merchant != "Grab" || txnRepo.savedTxns[1].Merchant != "Lazada" {
+		t.Errorf("expected merchants to be normalized, got %+v", txnRepo.savedTxns)
+	}
+	if !txnRepo.savedTxns[0].IsBusiness || !slices.Equal(txnRepo.savedTxns[0].Tags, []string{"work"}) || txnRepo.savedTxns[1].IsBusiness {
+		t.Errorf("expected rules to be applied to normalized merchants, got %+v", txnRepo.savedTxns)
+	}
+
+	// A classifier outage keeps the rule results and does not fail the upload
+	txnRepo = &mockTransactionRepository{}
+	if _, err := newService(&mockClassifier{err: errors.New("LLM down")}, txnRepo).ExtractText(ctx, "user-1", strings.NewReader("pdf"), ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if txnRepo.savedTxns[0].Category != "transport" || txnRepo.savedTxns[1].Category != "" {
+		t.Errorf("expected only the rule category, got %+v", txnRepo.savedTxns)
+	}
 }
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// PDFToTextExtractor extracts text by shelling out to Poppler's pdftotext
+type PDFToTextExtractor struct {
+}
+
+func NewPDFToTextExtractor() *PDFToTextExtractor {
+	return &PDFToTextExtractor{}
+}
+
+func (e *PDFToTextExtractor) Extract(ctx context.Context, pdf []byte, password string) (string, error) {
+	// Create a temporary file to store the PDF
+	tmpFile, err := os.CreateTemp("", "pdf-*.pdf")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	if _, err := tmpFile.Write(pdf); err != nil {
+		return "", err
+	}
+	tmpFile.Close()
+
+	// Build pdftotext command arguments
+	args := []string{"-layout"}
+	if password != "" {
+		args = append(args, "-upw", password)
+	}
+	args = append(args, tmpFile.Name(), "-")
+
+	// Use pdftotext to extract text (supports Thai and other Unicode)
+	stdout, err := runCommand(ctx, "pdftotext", args...)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(stdout), nil
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"math"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+// reconciliationTolerance absorbs rounding differences between the
+// statement total and the sum of the parsed transactions
+const reconciliationTolerance = 0.01
+
+// reconcile checks that the previous balance plus the parsed transactions add
+// up to the statement's total balance, recording the outcome on the statement.
+// A non-zero delta usually means the LLM dropped or misread a line.
+func reconcile(statement *model.Statement) {
+	if statement.TotalPayment == 0 && statement.PreviousBalance == 0 {
+		statement.ReconciliationStatus = model.ReconciliationUnavailable
+		statement.ReconciliationDelta = 0
+		return
+	}
+
+	sum := statement.PreviousBalance
+	for _, t := range statement.Transactions {
+		sum += t.Amount
+	}
+
+	delta := math.Round((statement.TotalPayment-sum)*100) / 100
+	statement.ReconciliationDelta = delta
+	if math.Abs(delta) < reconciliationTolerance {
+		statement.ReconciliationDelta = 0
+		statement.ReconciliationStatus = model.ReconciliationMatched
+		return
+	}
+	statement.ReconciliationStatus = model.ReconciliationMismatched
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+func TestReconcile(t *testing.T) {
+	tests := []struct {
+		name           string
+		statement      model.Statement
+		expectedStatus model.ReconciliationStatus
+		expectedDelta  float64
+	}{
+		{
+			name: "matches when transactions add up",
+			statement: model.Statement{
+				PreviousBalance: 1000.00,
+				TotalPayment:    1250.50,
+				Transactions: []model.Transaction{
+					{Amount: -1000.00},
+					{Amount: 1070.00},
+					{Amount: 180.50},
+				},
+			},
+			expectedStatus: model.ReconciliationMatched,
+			expectedDelta:  0,
+		},
+		{
+			name: "tolerates floating point rounding",
+			statement: model.Statement{
+				TotalPayment: 0.3,
+				Transactions: []model.Transaction{
+					{Amount: 0.1},
+					{Amount: 0.2},
+				},
+			},
+			expectedStatus: model.ReconciliationMatched,
+			expectedDelta:  0,
+		},
+		{
+			name: "flags missing transaction",
+			statement: model.Statement{
+				PreviousBalance: 500.00,
+				TotalPayment:    1750.00,
+				Transactions: []model.Transaction{
+					{Amount: 1000.00},
+				},
+			},
+			expectedStatus: model.ReconciliationMismatched,
+			expectedDelta:  250.00,
+		},
+		{
+			name: "flags extra transaction",
+			statement: model.Statement{
+				TotalPayment: 100.00,
+				Transactions: []model.Transaction{
+					{Amount: 100.00},
+					{Amount: 14.20},
+				},
+			},
+			expectedStatus: model.ReconciliationMismatched,
+			expectedDelta:  -14.20,
+		},
+		{
+			name: "unavailable without statement totals",
+			statement: model.Statement{
+				Transactions: []model.Transaction{
+					{Amount: 100.00},
+				},
+			},
+			expectedStatus: model.ReconciliationUnavailable,
+			expectedDelta:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement := tt.statement
+			reconcile(&statement)
+
+			if statement.ReconciliationStatus != tt.expectedStatus {
+				t.Errorf("expected status %s, got %s", tt.expectedStatus, statement.ReconciliationStatus)
+			}
+			if statement.ReconciliationDelta != tt.expectedDelta {
+				t.Errorf("expected delta %.2f, got %.2f", tt.expectedDelta, statement.ReconciliationDelta)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+// piiKind identifies the type of personal data behind a placeholder
+type piiKind string
+
+const (
+	piiCard       piiKind = "CARD"
+	piiNationalID piiKind = "NATIONAL_ID"
+	piiPhone      piiKind = "PHONE"
+	piiName       piiKind = "NAME"
+	piiAddress    piiKind = "ADDRESS"
+)
+
+var (
+	cardNumberPattern = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	nationalIDPattern = regexp.MustCompile(`\b\d[ -]?\d{4}[ -]?\d{5}[ -]?\d{2}[ -]?\d\b`)
+	phonePattern      = regexp.MustCompile(`(?:\+66[ -]?|\b0)\d{1,2}[ -]?\d{3}[ -]?\d{3,4}\b`)
+	// Names follow a title; words are joined by single spaces because layout
+	// text separates columns with several
+	namePattern = regexp.MustCompile(`\b(?:MR|MRS|MS|MISS|DR|Mr|Mrs|Ms|Miss|Dr)\.? [A-Z][A-Za-z'-]+(?: [A-Z][A-Za-z'-]+){0,2}`)
+	// Thai titles start a token and are followed by a first name and surname,
+	// so merchant words that merely begin with นาย or นาง are kept. Go has no
+	// lookbehind, so the preceding space is matched and the name captured.
+	thaiNamePattern = regexp.MustCompile(`(?:^|\s)((?:นางสาว|นาย|นาง) ?\p{Thai}+ \p{Thai}+)`)
+	addressPattern  = regexp.MustCompile(`(?i)\b(?:SOI|ROAD|RD\.|MOO|STREET|SUB-DISTRICT|DISTRICT|PROVINCE|KHWAENG|KHET|TAMBON|AMPHOE)\b|ซอย|ถนน|แขวง|เขต|ตำบล|อำเภอ|จังหวัด|หมู่`)
+	// A trailing postcode only marks an address line when it continues an
+	// address, so lines such as "CREDIT LIMIT 50000" are kept
+	postcodePattern  = regexp.MustCompile(`\b[1-9]\d{4}\s*$`)
+	amountPattern    = regexp.MustCompile(`\d\.\d{2}\b`)
+	placeholderRegex = regexp.MustCompile(`\[(CARD|NATIONAL_ID|PHONE|NAME|ADDRESS)_\d+\]`)
+)
+
+// PIIRedactor replaces personal data in statement text with placeholders
+// before it is sent to a third-party LLM
+type PIIRedactor struct{}
+
+// NewPIIRedactor creates a new PIIRedactor
+func NewPIIRedactor() *PIIRedactor {
+	return &PIIRedactor{}
+}
+
+// Redaction maps the placeholders of one redacted text back to the
+// original values
+type Redaction struct {
+	originals    map[string]string
+	placeholders map[string]string
+	counts       map[piiKind]int
+}
+
+// Redact masks full card numbers, national IDs, phone numbers, names and
+// address lines. The same value always gets the same placeholder.
+func (r *PIIRedactor) Redact(text string) (string, *Redaction) {
+	redaction := &Redaction{
+		originals:    map[string]string{},
+		placeholders: map[string]string{},
+		counts:       map[piiKind]int{},
+	}
+
+	lines := strings.Split(text, "\n")
+	address := false
+	for i, line := range lines {
+		lines[i], address = redaction.redactLine(line, address)
+	}
+	return strings.Join(lines, "\n"), redaction
+}
+
+// RedactForStorage masks the personal data that is never stored: names,
+// addresses and national IDs stay redacted and card numbers are masked, as
+// Rehydrate leaves them in a parsed statement
+func (r *PIIRedactor) RedactForStorage(text string) string {
+	redacted, redaction := r.Redact(text)
+	return redaction.restore(redacted)
+}
+
+// redactLine masks the personal data in line. afterAddress reports whether
+// the previous line was an address line, and the result whether this one is.
+func (d *Redaction) redactLine(line string, afterAddress bool) (string, bool) {
+	// 13-digit runs are national IDs or card numbers depending on which
+	// checksum they pass, so both checks run before any phone masking
+	line = cardNumberPattern.ReplaceAllStringFunc(line, func(match string) string {
+		digits := digitsOf(match)
+		if len(digits) == 13 && validThaiNationalID(digits) {
+			return d.placeholder(piiNationalID, match)
+		}
+		if validLuhn(digits) {
+			return d.placeholder(piiCard, match)
+		}
+		return match
+	})
+	line = nationalIDPattern.ReplaceAllStringFunc(line, func(match string) string {
+		if !validThaiNationalID(digitsOf(match)) {
+			return match
+		}
+		return d.placeholder(piiNationalID, match)
+	})
+	line = phonePattern.ReplaceAllStringFunc(line, func(match string) string {
+		return d.placeholder(piiPhone, match)
+	})
+	line = namePattern.ReplaceAllStringFunc(line, func(match string) string {
+		return d.placeholder(piiName, match)
+	})
+	line = d.redactThaiNames(line)
+
+	// Address lines are masked whole; transaction lines with amounts are
+	// kept since merchant names often contain street names
+	address := addressPattern.MatchString(line) || (afterAddress && postcodePattern.MatchString(line))
+	if !address || amountPattern.MatchString(line) {
+		return line, false
+	}
+	content := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(content)]
+	return indent + d.placeholder(piiAddress, strings.TrimSpace(content)), true
+}
+
+// redactThaiNames replaces the names captured by thaiNamePattern, keeping the
+// space matched before them
+func (d *Redaction) redactThaiNames(line string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range thaiNamePattern.FindAllStringSubmatchIndex(line, -1) {
+		b.WriteString(line[last:m[2]])
+		b.WriteString(d.placeholder(piiName, line[m[2]:m[3]]))
+		last = m[3]
+	}
+	b.WriteString(line[last:])
+	return b.String()
+}
+
+func (d *Redaction) placeholder(kind piiKind, original string) string {
+	if p, ok := d.placeholders[original]; ok {
+		return p
+	}
+	d.counts[kind]++
+	p := fmt.Sprintf("[%s_%d]", kind, d.counts[kind])
+	d.placeholders[original] = p
+	d.originals[p] = original
+	return p
+}
+
+// Rehydrate restores the placeholders the statement may keep: card numbers
+// are restored masked to their first six and last four digits and phone
+// numbers in descriptions are restored in full. Names, addresses and national
+// IDs are never stored and stay redacted.
+func (d *Redaction) Rehydrate(statement *model.Statement) {
+	statement.CardNumber = d.restore(statement.CardNumber)
+	statement.Bank = d.restore(statement.Bank)
+	for i := range statement.Transactions {
+		t := &statement.Transactions[i]
+		t.CardNumber = d.restore(t.CardNumber)
+		t.Description = d.restore(t.Description)
+	}
+}
+
+func (d *Redaction) restore(s string) string {
+	return placeholderRegex.ReplaceAllStringFunc(s, func(p string) string {
+		original, ok := d.originals[p]
+		if !ok {
+			return p
+		}
+		switch piiKind(placeholderRegex.FindStringSubmatch(p)[1]) {
+		case piiCard:
+			return maskCardNumber(original)
+		case piiPhone:
+			return original
+		default:
+			return p
+		}
+	})
+}
+
+// maskCardNumber keeps the first six and last four digits of a card number,
+// replacing the others with X and preserving separators
+func maskCardNumber(card string) string {
+	total := len(digitsOf(card))
+	var b strings.Builder
+	n := 0
+	for _, r := range card {
+		if r < '0' || r > '9' {
+			b.WriteRune(r)
+			continue
+		}
+		n++
+		if n <= 6 || n > total-4 {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('X')
+		}
+	}
+	return b.String()
+}
+
+func digitsOf(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// validLuhn reports whether digits pass the Luhn checksum used by card numbers
+func validLuhn(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// validThaiNationalID reports whether 13 digits pass the Thai national ID
+// mod-11 checksum
+func validThaiNationalID(digits string) bool {
+	if len(digits) != 13 {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 12; i++ {
+		sum += int(digits[i]-'0') * (13 - i)
+	}
+	return (11-sum%11)%10 == int(digits[12]-'0')
+}
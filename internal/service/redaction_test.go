@@ -0,0 +1,117 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+const redactionTestText = `KTC CREDIT CARD STATEMENT
+MR JOHN SMITH          CARD NO. 4111 1111 1111 1111
+123/45 SOI SUKHUMVIT 31
+KHLONG TOEI NUEA BANGKOK 10110
+TEL 081-234-5678      ID 1-1017-00123-45-6
+17/12  PAYMENT - THANK YOU                31,751.00 CR
+05/01  SHELL RAMA 4 ROAD                   1,070.00
+06/01  CALL CENTER 02-123-4567               250.00
+CARD 4111 1111 1111 1111 TOTAL             1,320.00`
+
+func TestPIIRedactor_Redact(t *testing.T) {
+	redacted, _ := NewPIIRedactor().Redact(redactionTestText)
+
+	for _, pii := range []string{"JOHN SMITH", "4111 1111 1111 1111", "SUKHUMVIT", "10110", "081-234-5678", "1-1017-00123-45-6", "02-123-4567"} {
+		if strings.Contains(redacted, pii) {
+			t.Errorf("expected %q to be redacted, got:\n%s", pii, redacted)
+		}
+	}
+	for _, kept := range []string{"KTC CREDIT CARD STATEMENT", "PAYMENT - THANK YOU", "31,751.00 CR", "SHELL RAMA 4 ROAD", "17/12", "06/01"} {
+		if !strings.Contains(redacted, kept) {
+			t.Errorf("expected %q to be kept, got:\n%s", kept, redacted)
+		}
+	}
+	if strings.Count(redacted, "[CARD_1]") != 2 {
+		t.Errorf("expected the same card number to reuse its placeholder, got:\n%s", redacted)
+	}
+	if !strings.Contains(redacted, "[NATIONAL_ID_1]") {
+		t.Errorf("expected national ID placeholder, got:\n%s", redacted)
+	}
+}
+
+func TestPIIRedactor_IgnoresNumbersFailingChecksums(t *testing.T) {
+	text := "REF 1234 5678 9012 3456  ID 1101700123450"
+	redacted, _ := NewPIIRedactor().Redact(text)
+	if redacted != text {
+		t.Errorf("expected text unchanged, got %q", redacted)
+	}
+}
+
+func TestPIIRedactor_Redact_KeepsLookalikes(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		redacted string
+		kept     string
+	}{
+		{"Thai name with title", "นายสมชาย ใจดี          CARD NO. 4111", "สมชาย", ""},
+		{"Thai name after a column", "TRANSFER นางสาว สุดา รักไทย 500.00", "สุดา", "TRANSFER"},
+		{"merchant starting with a title", "05/01  นายหน้าประกันภัย   1,070.00", "", "นายหน้าประกันภัย"},
+		{"title inside a merchant name", "06/01  ร้านนางฟ้า เบเกอรี่   120.00", "", "ร้านนางฟ้า เบเกอรี่"},
+		{"amount without an address", "CREDIT LIMIT 50000", "", "CREDIT LIMIT 50000"},
+		{"postcode continuing an address", "99 SOI ARI\nPHAYA THAI BANGKOK 10400", "10400", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redacted, _ := NewPIIRedactor().Redact(tt.text)
+			if tt.redacted != "" && strings.Contains(redacted, tt.redacted) {
+				t.Errorf("expected %q to be redacted, got %q", tt.redacted, redacted)
+			}
+			if tt.kept != "" && !strings.Contains(redacted, tt.kept) {
+				t.Errorf("expected %q to be kept, got %q", tt.kept, redacted)
+			}
+		})
+	}
+}
+
+func TestRedaction_Rehydrate(t *testing.T) {
+	_, redaction := NewPIIRedactor().Redact(redactionTestText)
+
+	statement := model.Statement{
+		CardNumber: "[CARD_1]",
+		Transactions: []model.Transaction{
+			{CardNumber: "[CARD_1]", Description: "CALL CENTER [PHONE_2]"},
+			{CardNumber: "[CARD_1]", Description: "TRANSFER [NAME_1] [NATIONAL_ID_1]"},
+			{CardNumber: "[CARD_1]", Description: "UNKNOWN [PHONE_9]"},
+		},
+	}
+	redaction.Rehydrate(&statement)
+
+	if statement.CardNumber != "4111 11XX XXXX 1111" {
+		t.Errorf("expected masked card number, got %s", statement.CardNumber)
+	}
+	if statement.Transactions[0].CardNumber != "4111 11XX XXXX 1111" {
+		t.Errorf("expected masked transaction card number, got %s", statement.Transactions[0].CardNumber)
+	}
+	if statement.Transactions[0].Description != "CALL CENTER 02-123-4567" {
+		t.Errorf("expected phone number restored, got %s", statement.Transactions[0].Description)
+	}
+	if statement.Transactions[1].Description != "TRANSFER [NAME_1] [NATIONAL_ID_1]" {
+		t.Errorf("expected name and national ID to stay redacted, got %s", statement.Transactions[1].Description)
+	}
+	if statement.Transactions[2].Description != "UNKNOWN [PHONE_9]" {
+		t.Errorf("expected unknown placeholder unchanged, got %s", statement.Transactions[2].Description)
+	}
+}
+
+func TestMaskCardNumber(t *testing.T) {
+	tests := map[string]string{
+		"4111111111111111":    "411111XXXXXX1111",
+		"4111-1111-1111-1111": "4111-11XX-XXXX-1111",
+	}
+	for in, want := range tests {
+		if got := maskCardNumber(in); got != want {
+			t.Errorf("maskCardNumber(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -8,10 +8,63 @@ import (
 )
 
 type LLMRepository interface {
-	ParseStatement(statementText string) ([]model.Transaction, error)
+	ParseStatement(ctx context.Context, statementText string) (model.Statement, error)
+}
+
+type TransactionClassifier interface {
+	ClassifyTransactions(ctx context.Context, transactions []model.Transaction, categories []string) ([]string, error)
+}
+
+type StatementRepository interface {
+	Create(ctx context.Context, statement model.Statement) error
+	Save(ctx context.Context, statement model.Statement) error
+	GetStatement(ctx context.Context, statementID string) (model.Statement, error)
+	GetStatements(ctx context.Context, userID string) ([]model.Statement, error)
+	GetStatementByFileHash(ctx context.Context, userID, fileHash string) (model.Statement, error)
+	UpdateReconciliation(ctx context.Context, statementID string, status model.ReconciliationStatus, delta float64) error
+	Delete(ctx context.Context, statementID string) error
 }
 
 type TransactionRepository interface {
 	Save(ctx context.Context, transactions []model.Transaction) error
-	GetTransactions(ctx context.Context, userID string, from, to time.Time) ([]model.Transaction, error)
+	GetTransactions(ctx context.Context, userID string, query model.TransactionQuery) (model.TransactionPage, error)
+	GetTransaction(ctx context.Context, transactionID string) (model.Transaction, error)
+	GetTransactionsByIDs(ctx context.Context, ids []string) ([]model.Transaction, error)
+	GetTransactionsByStatement(ctx context.Context, statementID string) ([]model.Transaction, error)
+	Update(ctx context.Context, transactionID string, update func(*model.Transaction) error) (model.Transaction, error)
+	Delete(ctx context.Context, transactionID string) error
+	DeleteByStatement(ctx context.Context, statementID string) error
+	DeleteByIDs(ctx context.Context, ids []string) error
+}
+
+type JobRepository interface {
+	Save(ctx context.Context, job model.Job) error
+	GetJob(ctx context.Context, jobID string) (model.Job, error)
+	GetUnfinishedJobs(ctx context.Context) ([]model.Job, error)
+	SaveUpload(ctx context.Context, jobID string, upload model.JobUpload) error
+	GetUpload(ctx context.Context, jobID string) (model.JobUpload, error)
+	DeleteUpload(ctx context.Context, jobID string) error
+}
+
+type APIKeyRepository interface {
+	Save(ctx context.Context, key model.APIKey) error
+	GetAPIKey(ctx context.Context, keyID string) (model.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, hash string) (model.APIKey, error)
+	GetAPIKeys(ctx context.Context, userID string) ([]model.APIKey, error)
+	UpdateLastUsedAt(ctx context.Context, keyID string, usedAt time.Time) error
+	Revoke(ctx context.Context, keyID string, revokedAt time.Time) error
+}
+
+type CategoryRuleRepository interface {
+	Save(ctx context.Context, rule model.CategoryRule) error
+	GetCategoryRule(ctx context.Context, ruleID string) (model.CategoryRule, error)
+	GetCategoryRules(ctx context.Context, userID string) ([]model.CategoryRule, error)
+	Delete(ctx context.Context, ruleID string) error
+}
+
+type RuleRepository interface {
+	Save(ctx context.Context, rule model.Rule) error
+	GetRule(ctx context.Context, ruleID string) (model.Rule, error)
+	GetRules(ctx context.Context, userID string) ([]model.Rule, error)
+	Delete(ctx context.Context, ruleID string) error
 }
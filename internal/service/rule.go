@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tsongpon/helios/internal/model"
+)
+
+// RuleService manages the user's transaction rules and applies them
+type RuleService struct {
+	ruleRepository        RuleRepository
+	transactionRepository TransactionRepository
+}
+
+func NewRuleService(ruleRepository RuleRepository, transactionRepository TransactionRepository) *RuleService {
+	return &RuleService{
+		ruleRepository:        ruleRepository,
+		transactionRepository: transactionRepository,
+	}
+}
+
+// CreateRule validates and saves a rule for userID
+func (s *RuleService) CreateRule(ctx context.Context, userID string, rule model.Rule) (model.Rule, error) {
+	rule, err := normalizeRule(rule)
+	if err != nil {
+		return model.Rule{}, err
+	}
+
+	rule.ID = uuid.NewString()
+	rule.UserID = userID
+	rule.CreatedAt = time.Now().UTC()
+	rule.UpdatedAt = rule.CreatedAt
+	if err := s.ruleRepository.Save(ctx, rule); err != nil {
+		return model.Rule{}, fmt.Errorf("failed to save rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+func (s *RuleService) GetRules(ctx context.Context, userID string) ([]model.Rule, error) {
+	return s.ruleRepository.GetRules(ctx, userID)
+}
+
+// GetRule returns the rule with the given ID if it belongs to userID
+func (s *RuleService) GetRule(ctx context.Context, userID, ruleID string) (model.Rule, error) {
+	rule, err := s.ruleRepository.GetRule(ctx, ruleID)
+	if err != nil {
+		return model.Rule{}, err
+	}
+	if rule.UserID != userID {
+		return model.Rule{}, model.ErrNotFound
+	}
+	return rule, nil
+}
+
+// UpdateRule replaces the name, condition and actions of a rule the user
+// owns; it keeps its place in the evaluation order
+func (s *RuleService) UpdateRule(ctx context.Context, userID, ruleID string, rule model.Rule) (model.Rule, error) {
+	existing, err := s.GetRule(ctx, userID, ruleID)
+	if err != nil {
+		return model.Rule{}, err
+	}
+	rule, err = normalizeRule(rule)
+	if err != nil {
+		return model.Rule{}, err
+	}
+
+	rule.ID = existing.ID
+	rule.UserID = existing.UserID
+	rule.CreatedAt = existing.CreatedAt
+	rule.UpdatedAt = time.Now().UTC()
+	if err := s.ruleRepository.Save(ctx, rule); err != nil {
+		return model.Rule{}, fmt.Errorf("failed to save rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// DeleteRule removes a rule the user owns. Changes it already made to
+// transactions are kept.
+func (s *RuleService) DeleteRule(ctx context.Context, userID, ruleID string) error {
+	if _, err := s.GetRule(ctx, userID, ruleID); err != nil {
+		return err
+	}
+	if err := s.ruleRepository.Delete(ctx, ruleID); err != nil {
+		return fmt.Errorf("failed to delete rule: %w", err)
+	}
+	return nil
+}
+
+// ApplyRules runs the user's rules over transactions, oldest rule first.
+// Every matching rule adds its tags and business flag; the first matching
+// rule with a category sets it, unless the user set the category themselves.
+func (s *RuleService) ApplyRules(ctx context.Context, userID string, transactions []model.Transaction) error {
+	rules, err := s.ruleRepository.GetRules(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get rules: %w", err)
+	}
+
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		condition, err := parseRuleCondition(rule.Condition)
+		if err != nil {
+			log.Printf("skipping invalid rule %s: %v", rule.ID, err)
+			continue
+		}
+		compiled = append(compiled, compiledRule{actions: rule.Actions, condition: condition})
+	}
+
+	for i := range transactions {
+		applyRules(compiled, &transactions[i])
+	}
+	return nil
+}
+
+// DryRun returns the user's saved transactions matching query that rule
+// would change, without saving the rule or the transactions
+func (s *RuleService) DryRun(ctx context.Context, userID string, rule model.Rule, query model.TransactionQuery) ([]model.RuleChange, error) {
+	rule, err := normalizeRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	condition, err := parseRuleCondition(rule.Condition)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", model.ErrInvalidRule, err)
+	}
+	compiled := []compiledRule{{actions: rule.Actions, condition: condition}}
+
+	query.Limit = model.MaxTransactionPageSize
+	query.Cursor = ""
+	changes := []model.RuleChange{}
+	for {
+		page, err := s.transactionRepository.GetTransactions(ctx, userID, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transactions: %w", err)
+		}
+
+		for _, before := range page.Transactions {
+			after := before
+			after.Tags = slices.Clone(before.Tags)
+			if applyRules(compiled, &after) {
+				changes = append(changes, model.RuleChange{Before: before, After: after})
+			}
+		}
+
+		if page.NextCursor == "" {
+			return changes, nil
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+type compiledRule struct {
+	actions   model.RuleActions
+	condition ruleCondition
+}
+
+// applyRules applies the actions of every rule whose condition matches the
+// transaction as it was before any rule ran, and reports whether it changed
+func applyRules(rules []compiledRule, t *model.Transaction) bool {
+	original := *t
+	changed := false
+	categorySet := false
+	for _, rule := range rules {
+		if !rule.condition.match(original) {
+			continue
+		}
+
+		a := rule.actions
+		if a.SetCategory != "" && !categorySet && !categorySetByUser(*t) {
+			categorySet = true
+			if t.Category != a.SetCategory {
+				t.Category = a.SetCategory
+				changed = true
+			}
+		}
+		for _, tag := range a.AddTags {
+			if !slices.Contains(t.Tags, tag) {
+				t.Tags = append(t.Tags, tag)
+				changed = true
+			}
+		}
+		if a.MarkBusiness && !t.IsBusiness {
+			t.IsBusiness = true
+			changed = true
+		}
+	}
+	return changed
+}
+
+// normalizeRule trims and lower-cases the rule's fields and validates them
+func normalizeRule(rule model.Rule) (model.Rule, error) {
+	rule.Name = strings.TrimSpace(rule.Name)
+	rule.Condition = strings.TrimSpace(rule.Condition)
+	rule.Actions.SetCategory = strings.ToLower(strings.TrimSpace(rule.Actions.SetCategory))
+
+	var tags []string
+	for _, tag := range rule.Actions.AddTags {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	rule.Actions.AddTags = tags
+
+	if rule.Condition == "" {
+		return model.Rule{}, fmt.Errorf("%w: condition is required", model.ErrInvalidRule)
+	}
+	if _, err := parseRuleCondition(rule.Condition); err != nil {
+		return model.Rule{}, fmt.Errorf("%w: %v", model.ErrInvalidRule, err)
+	}
+	if rule.Actions.IsEmpty() {
+		return model.Rule{}, fmt.Errorf("%w: at least one action is required", model.ErrInvalidRule)
+	}
+	if rule.Actions.SetCategory != "" && !slices.Contains(model.Categories, rule.Actions.SetCategory) {
+		return model.Rule{}, fmt.Errorf("%w: unknown category %q", model.ErrInvalidRule, rule.Actions.SetCategory)
+	}
+	if rule.Name == "" {
+		rule.Name = rule.Condition
+	}
+	return rule, nil
+}
@@ -0,0 +1,407 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+// ruleCondition is a parsed rule condition such as
+//
+//	merchant == "Grab" and amount > 500 and not is_installment
+//
+// Conditions compare transaction fields with literals using ==, !=, <, <=,
+// >, >=, contains and matches (a regular expression), and combine them with
+// and, or, not and parentheses. String comparisons ignore case.
+type ruleCondition interface {
+	match(t model.Transaction) bool
+}
+
+type ruleFieldKind int
+
+const (
+	stringRuleField ruleFieldKind = iota
+	numberRuleField
+	boolRuleField
+	listRuleField
+)
+
+type ruleField struct {
+	kind ruleFieldKind
+	get  func(t model.Transaction) any
+}
+
+// ruleFields are the transaction fields a condition can refer to
+var ruleFields = map[string]ruleField{
+	"description":      {stringRuleField, func(t model.Transaction) any { return t.Description }},
+	"merchant":         {stringRuleField, func(t model.Transaction) any { return t.Merchant }},
+	"category":         {stringRuleField, func(t model.Transaction) any { return t.Category }},
+	"card_number":      {stringRuleField, func(t model.Transaction) any { return t.CardNumber }},
+	"statement_id":     {stringRuleField, func(t model.Transaction) any { return t.StatementID }},
+	"transaction_date": {stringRuleField, func(t model.Transaction) any { return t.TransactionDate }},
+	"posting_date":     {stringRuleField, func(t model.Transaction) any { return t.PostingDate }},
+	"installment_term": {stringRuleField, func(t model.Transaction) any { return t.InstallmentTerm }},
+	"notes":            {stringRuleField, func(t model.Transaction) any { return t.Notes }},
+	"source":           {stringRuleField, func(t model.Transaction) any { return string(t.Source) }},
+	"amount":           {numberRuleField, func(t model.Transaction) any { return t.Amount }},
+	"is_installment":   {boolRuleField, func(t model.Transaction) any { return t.IsInstallment }},
+	"is_business":      {boolRuleField, func(t model.Transaction) any { return t.IsBusiness }},
+	"tags":             {listRuleField, func(t model.Transaction) any { return t.Tags }},
+}
+
+type andCondition struct{ left, right ruleCondition }
+
+func (c andCondition) match(t model.Transaction) bool { return c.left.match(t) && c.right.match(t) }
+
+type orCondition struct{ left, right ruleCondition }
+
+func (c orCondition) match(t model.Transaction) bool { return c.left.match(t) || c.right.match(t) }
+
+type notCondition struct{ inner ruleCondition }
+
+func (c notCondition) match(t model.Transaction) bool { return !c.inner.match(t) }
+
+type stringComparison struct {
+	field ruleField
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+func (c stringComparison) match(t model.Transaction) bool {
+	s := c.field.get(t).(string)
+	switch c.op {
+	case "contains":
+		return strings.Contains(strings.ToLower(s), strings.ToLower(c.value))
+	case "matches":
+		return c.re.MatchString(s)
+	}
+	return compare(strings.Compare(strings.ToLower(s), strings.ToLower(c.value)), c.op)
+}
+
+type numberComparison struct {
+	field ruleField
+	op    string
+	value float64
+}
+
+func (c numberComparison) match(t model.Transaction) bool {
+	n := c.field.get(t).(float64)
+	switch {
+	case n < c.value:
+		return compare(-1, c.op)
+	case n > c.value:
+		return compare(1, c.op)
+	}
+	return compare(0, c.op)
+}
+
+type boolComparison struct {
+	field ruleField
+	value bool
+}
+
+func (c boolComparison) match(t model.Transaction) bool {
+	return c.field.get(t).(bool) == c.value
+}
+
+type listContains struct {
+	field ruleField
+	value string
+}
+
+func (c listContains) match(t model.Transaction) bool {
+	return slices.ContainsFunc(c.field.get(t).([]string), func(item string) bool {
+		return strings.EqualFold(item, c.value)
+	})
+}
+
+// compare reports whether a comparison result satisfies op
+func compare(result int, op string) bool {
+	switch op {
+	case "==":
+		return result == 0
+	case "!=":
+		return result != 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	}
+	return false
+}
+
+type conditionTokenKind int
+
+const (
+	eofToken conditionTokenKind = iota
+	identToken
+	stringToken
+	numberToken
+	operatorToken
+	leftParenToken
+	rightParenToken
+)
+
+type conditionToken struct {
+	kind conditionTokenKind
+	text string
+	pos  int
+}
+
+// describe returns the token as shown in error messages
+func (t conditionToken) describe() string {
+	if t.kind == eofToken {
+		return "end of condition"
+	}
+	return strconv.Quote(t.text)
+}
+
+func tokenizeCondition(src string) ([]conditionToken, error) {
+	var tokens []conditionToken
+	runes := []rune(src)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, conditionToken{leftParenToken, "(", i})
+			i++
+		case r == ')':
+			tokens = append(tokens, conditionToken{rightParenToken, ")", i})
+			i++
+		case r == '"' || r == '\'':
+			var sb strings.Builder
+			start := i
+			for i++; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			tokens = append(tokens, conditionToken{stringToken, sb.String(), start})
+			i++
+		case strings.ContainsRune("=!<>", r):
+			start := i
+			i++
+			if i < len(runes) && runes[i] == '=' {
+				i++
+			}
+			op := string(runes[start:i])
+			if op == "=" || op == "!" {
+				return nil, fmt.Errorf("unknown operator %q at position %d", op, start)
+			}
+			tokens = append(tokens, conditionToken{operatorToken, op, start})
+		case unicode.IsDigit(r) || r == '-' || r == '.':
+			start := i
+			for i++; i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.'); i++ {
+			}
+			tokens = append(tokens, conditionToken{numberToken, string(runes[start:i]), start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i++; i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_'); i++ {
+			}
+			tokens = append(tokens, conditionToken{identToken, strings.ToLower(string(runes[start:i])), start})
+		default:
+			return nil, fmt.Errorf("unexpected %q at position %d", r, i)
+		}
+	}
+	return append(tokens, conditionToken{kind: eofToken, pos: len(runes)}), nil
+}
+
+type conditionParser struct {
+	tokens []conditionToken
+	pos    int
+	depth  int
+}
+
+// Conditions are bounded so a crafted one cannot exhaust the stack of the
+// recursive descent parser or of match
+const (
+	maxRuleConditionLength = 1024
+	maxRuleConditionDepth  = 32
+)
+
+// parseRuleCondition parses a condition, checking that every comparison fits
+// the type of its field
+func parseRuleCondition(src string) (ruleCondition, error) {
+	if len(src) > maxRuleConditionLength {
+		return nil, fmt.Errorf("condition is longer than %d bytes", maxRuleConditionLength)
+	}
+	tokens, err := tokenizeCondition(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &conditionParser{tokens: tokens}
+	condition, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != eofToken {
+		return nil, fmt.Errorf("unexpected %s at position %d", tok.describe(), tok.pos)
+	}
+	return condition, nil
+}
+
+func (p *conditionParser) peek() conditionToken {
+	return p.tokens[p.pos]
+}
+
+func (p *conditionParser) next() conditionToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != eofToken {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *conditionParser) keyword(word string) bool {
+	if tok := p.peek(); tok.kind == identToken && tok.text == word {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *conditionParser) parseOr() (ruleCondition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orCondition{left, right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseAnd() (ruleCondition, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andCondition{left, right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseUnary() (ruleCondition, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxRuleConditionDepth {
+		return nil, fmt.Errorf("condition is nested deeper than %d levels at position %d", maxRuleConditionDepth, p.peek().pos)
+	}
+
+	if p.keyword("not") {
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notCondition{inner}, nil
+	}
+
+	if p.peek().kind == leftParenToken {
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if tok := p.next(); tok.kind != rightParenToken {
+			return nil, fmt.Errorf("expected \")\" at position %d, got %s", tok.pos, tok.describe())
+		}
+		return inner, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *conditionParser) parseComparison() (ruleCondition, error) {
+	tok := p.next()
+	if tok.kind != identToken {
+		return nil, fmt.Errorf("expected a field at position %d, got %s", tok.pos, tok.describe())
+	}
+	field, ok := ruleFields[tok.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown field %q at position %d", tok.text, tok.pos)
+	}
+
+	opToken := p.peek()
+	op := opToken.text
+	switch {
+	case opToken.kind == operatorToken:
+	case opToken.kind == identToken && (op == "contains" || op == "matches"):
+	case field.kind == boolRuleField:
+		// A boolean field on its own, e.g. "is_installment"
+		return boolComparison{field: field, value: true}, nil
+	default:
+		return nil, fmt.Errorf("expected an operator after %q at position %d, got %s", tok.text, opToken.pos, opToken.describe())
+	}
+	p.next()
+
+	value := p.next()
+	invalid := fmt.Errorf("cannot use %q with %s %q at position %d", op, tok.text, value.text, opToken.pos)
+	switch field.kind {
+	case stringRuleField:
+		if value.kind != stringToken {
+			return nil, fmt.Errorf("expected a quoted string at position %d, got %s", value.pos, value.describe())
+		}
+		comparison := stringComparison{field: field, op: op, value: value.text}
+		if op == "matches" {
+			re, err := regexp.Compile("(?i)" + value.text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regular expression at position %d: %v", value.pos, err)
+			}
+			comparison.re = re
+		}
+		return comparison, nil
+
+	case numberRuleField:
+		if op == "contains" || op == "matches" {
+			return nil, invalid
+		}
+		n, err := strconv.ParseFloat(value.text, 64)
+		if value.kind != numberToken || err != nil {
+			return nil, fmt.Errorf("expected a number at position %d, got %s", value.pos, value.describe())
+		}
+		return numberComparison{field: field, op: op, value: n}, nil
+
+	case boolRuleField:
+		if op != "==" && op != "!=" {
+			return nil, invalid
+		}
+		if value.kind != identToken || (value.text != "true" && value.text != "false") {
+			return nil, fmt.Errorf("expected true or false at position %d, got %s", value.pos, value.describe())
+		}
+		return boolComparison{field: field, value: (value.text == "true") == (op == "==")}, nil
+
+	default:
+		if op != "contains" {
+			return nil, invalid
+		}
+		if value.kind != stringToken {
+			return nil, fmt.Errorf("expected a quoted string at position %d, got %s", value.pos, value.describe())
+		}
+		return listContains{field: field, value: value.text}, nil
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+func TestParseRuleCondition(t *testing.T) {
+	grab := model.Transaction{
+		Description:   "GRAB*TAXI BANGKOK",
+		Merchant:      "Grab",
+		Amount:        650,
+		Category:      "transport",
+		IsInstallment: false,
+		Tags:          []string{"Work"},
+	}
+
+	tests := []struct {
+		condition string
+		want      bool
+	}{
+		{`merchant == "grab"`, true},
+		{`merchant != "Grab"`, false},
+		{`description contains "taxi"`, true},
+		{`description matches '^grab\*'`, true},
+		{`amount > 500`, true},
+		{`amount <= 500`, false},
+		{`amount >= -10.5`, true},
+		{`merchant == "Grab" and amount > 500 and not is_installment`, true},
+		{`merchant == "Lazada" or amount > 1000`, false},
+		{`not (merchant == "Lazada" or amount > 1000)`, true},
+		{`merchant == "Lazada" or merchant == "Grab" and amount < 100`, false},
+		{`is_installment`, false},
+		{`is_business == false`, true},
+		{`is_installment != true`, true},
+		{`tags contains "work"`, true},
+		{`category < "travel"`, true},
+		{`MERCHANT == "Grab" AND Amount > 1`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			condition, err := parseRuleCondition(tt.condition)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got := condition.match(grab); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseRuleCondition_Errors(t *testing.T) {
+	tests := []string{
+		``,
+		`merchant`,
+		`merchant == `,
+		`merchant == grab`,
+		`payee == "Grab"`,
+		`amount > "500"`,
+		`amount contains "5"`,
+		`is_installment > true`,
+		`is_installment == yes`,
+		`tags == "work"`,
+		`description matches "(grab"`,
+		`merchant == "Grab`,
+		`merchant = "Grab"`,
+		`(merchant == "Grab"`,
+		`merchant == "Grab")`,
+		`merchant == "Grab" amount > 5`,
+		`merchant == "Grab" and`,
+		`amount > 5 $`,
+	}
+
+	for _, condition := range tests {
+		t.Run(condition, func(t *testing.T) {
+			if _, err := parseRuleCondition(condition); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestParseRuleCondition_Limits(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition string
+		wantErr   bool
+	}{
+		{"nested within the limit", strings.Repeat("(", 30) + `amount > 5` + strings.Repeat(")", 30), false},
+		{"nested parentheses", strings.Repeat("(", 40) + `amount > 5` + strings.Repeat(")", 40), true},
+		{"repeated not", strings.Repeat("not ", 40) + `amount > 5`, true},
+		{"too long", `merchant == "` + strings.Repeat("a", maxRuleConditionLength) + `"`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseRuleCondition(tt.condition)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
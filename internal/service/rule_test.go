@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+type mockRuleRepository struct {
+	rules     []model.Rule
+	saved     model.Rule
+	deletedID string
+}
+
+func (m *mockRuleRepository) Save(ctx context.Context, rule model.Rule) error {
+	m.saved = rule
+	return nil
+}
+
+func (m *mockRuleRepository) GetRule(ctx context.Context, ruleID string) (model.Rule, error) {
+	for _, rule := range m.rules {
+		if rule.ID == ruleID {
+			return rule, nil
+		}
+	}
+	return model.Rule{}, model.ErrNotFound
+}
+
+func (m *mockRuleRepository) GetRules(ctx context.Context, userID string) ([]model.Rule, error) {
+	return m.rules, nil
+}
+
+func (m *mockRuleRepository) Delete(ctx context.Context, ruleID string) error {
+	m.deletedID = ruleID
+	return nil
+}
+
+func TestRuleService_CreateRule(t *testing.T) {
+	tests := []struct {
+		name string
+		rule model.Rule
+		ok   bool
+	}{
+		{"category", model.Rule{Condition: `merchant == "Grab"`, Actions: model.RuleActions{SetCategory: " Transport "}}, true},
+		{"tags and business", model.Rule{Name: "Work trips", Condition: `amount > 500`, Actions: model.RuleActions{AddTags: []string{"Work", "work", " "}, MarkBusiness: true}}, true},
+		{"missing condition", model.Rule{Actions: model.RuleActions{MarkBusiness: true}}, false},
+		{"invalid condition", model.Rule{Condition: `amount > "500"`, Actions: model.RuleActions{MarkBusiness: true}}, false},
+		{"no actions", model.Rule{Condition: `amount > 500`, Actions: model.RuleActions{AddTags: []string{" "}}}, false},
+		{"unknown category", model.Rule{Condition: `amount > 500`, Actions: model.RuleActions{SetCategory: "rides"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRuleRepository{}
+			service := NewRuleService(repo, &mockTransactionRepository{})
+
+			rule, err := service.CreateRule(context.Background(), "user-1", tt.rule)
+			if !tt.ok {
+				if !errors.Is(err, model.ErrInvalidRule) {
+					t.Errorf("expected model.ErrInvalidRule, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if rule.ID == "" || rule.UserID != "user-1" || rule.Name == "" || repo.saved.ID != rule.ID {
+				t.Errorf("unexpected rule: %+v", rule)
+			}
+			if rule.Actions.SetCategory != "" && rule.Actions.SetCategory != "transport" {
+				t.Errorf("expected category to be normalized, got %q", rule.Actions.SetCategory)
+			}
+			if len(rule.Actions.AddTags) > 0 && !slices.Equal(rule.Actions.AddTags, []string{"work"}) {
+				t.Errorf("expected tags to be normalized, got %v", rule.Actions.AddTags)
+			}
+		})
+	}
+}
+
+func TestRuleService_UpdateRule(t *testing.T) {
+	createdAt := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	repo := &mockRuleRepository{rules: []model.Rule{{ID: "rule-1", UserID: "user-1", CreatedAt: createdAt}}}
+	service := NewRuleService(repo, &mockTransactionRepository{})
+	update := model.Rule{Condition: `amount > 500`, Actions: model.RuleActions{MarkBusiness: true}}
+
+	if _, err := service.UpdateRule(context.Background(), "user-2", "rule-1", update); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for another user's rule, got %v", err)
+	}
+
+	rule, err := service.UpdateRule(context.Background(), "user-1", "rule-1", update)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if rule.ID != "rule-1" || !rule.CreatedAt.Equal(createdAt) || !rule.UpdatedAt.After(createdAt) || repo.saved.Condition != "amount > 500" {
+		t.Errorf("unexpected rule: %+v", rule)
+	}
+}
+
+func TestRuleService_DeleteRule(t *testing.T) {
+	repo := &mockRuleRepository{rules: []model.Rule{{ID: "rule-1", UserID: "user-1"}}}
+	service := NewRuleService(repo, &mockTransactionRepository{})
+
+	if err := service.DeleteRule(context.Background(), "user-2", "rule-1"); !errors.Is(err, model.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for another user's rule, got %v", err)
+	}
+	if err := service.DeleteRule(context.Background(), "user-1", "rule-1"); err != nil || repo.deletedID != "rule-1" {
+		t.Errorf("expected rule to be deleted, got %v", err)
+	}
+}
+
+func TestRuleService_ApplyRules(t *testing.T) {
+	repo := &mockRuleRepository{rules: []model.Rule{
+		{ID: "rule-1", Condition: `merchant == "Grab" and amount > 500`, Actions: model.RuleActions{SetCategory: "travel", AddTags: []string{"work"}}},
+		{ID: "rule-2", Condition: `merchant == "Grab"`, Actions: model.RuleActions{SetCategory: "transport", MarkBusiness: true}},
+		{ID: "rule-3", Condition: `amount >`, Actions: model.RuleActions{MarkBusiness: true}},
+		// Conditions see the transaction as parsed, not as changed by earlier rules
+		{ID: "rule-4", Condition: `tags contains "work"`, Actions: model.RuleActions{AddTags: []string{"review"}}},
+	}}
+	service := NewRuleService(repo, &mockTransactionRepository{})
+
+	userCategory := "food"
+	transactions := []model.Transaction{
+		{Merchant: "Grab", Amount: 650},
+		{Merchant: "Grab", Amount: 80, Category: "food", Overrides: model.TransactionPatch{Category: &userCategory}},
+		{Merchant: "Lazada", Amount: 900},
+		{Merchant: "Grab", Amount: 120, Category: "food", Source: model.TransactionSourceManual},
+	}
+	if err := service.ApplyRules(context.Background(), "user-1", transactions); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if got := transactions[0]; got.Category != "travel" || !slices.Equal(got.Tags, []string{"work"}) || !got.IsBusiness {
+		t.Errorf("expected the first matching category, all tags and business flag, got %+v", got)
+	}
+	if got := transactions[1]; got.Category != "food" || !got.IsBusiness {
+		t.Errorf("expected the user's category to be kept, got %+v", got)
+	}
+	if got := transactions[2]; got.Category != "" || got.Tags != nil || got.IsBusiness {
+		t.Errorf("expected no changes, got %+v", got)
+	}
+	if got := transactions[3]; got.Category != "food" || !got.IsBusiness {
+		t.Errorf("expected the category of the manual transaction to be kept, got %+v", got)
+	}
+}
+
+func TestRuleService_DryRun(t *testing.T) {
+	transactionRepo := &mockTransactionRepository{transactions: []model.Transaction{
+		{ID: "t1", Merchant: "Grab", Amount: 650, Tags: []string{"trip"}},
+		{ID: "t2", Merchant: "Grab", Amount: 650, Tags: []string{"trip", "work"}, IsBusiness: true},
+		{ID: "t3", Merchant: "Lazada", Amount: 650},
+	}}
+	service := NewRuleService(&mockRuleRepository{}, transactionRepo)
+	rule := model.Rule{Condition: `merchant == "Grab"`, Actions: model.RuleActions{AddTags: []string{"work"}, MarkBusiness: true}}
+
+	changes, err := service.DryRun(context.Background(), "user-1", rule, model.TransactionQuery{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(changes) != 1 || changes[0].Before.ID != "t1" {
+		t.Fatalf("expected only t1 to change, got %+v", changes)
+	}
+	if !slices.Equal(changes[0].Before.Tags, []string{"trip"}) || !slices.Equal(changes[0].After.Tags, []string{"trip", "work"}) || !changes[0].After.IsBusiness {
+		t.Errorf("unexpected change: %+v", changes[0])
+	}
+	if transactionRepo.savedTxns != nil {
+		t.Errorf("expected nothing to be saved, got %+v", transactionRepo.savedTxns)
+	}
+	if transactionRepo.query.Limit != model.MaxTransactionPageSize {
+		t.Errorf("expected pages of %d, got %d", model.MaxTransactionPageSize, transactionRepo.query.Limit)
+	}
+
+	if _, err := service.DryRun(context.Background(), "user-1", model.Rule{Condition: "amount >"}, model.TransactionQuery{}); !errors.Is(err, model.ErrInvalidRule) {
+		t.Errorf("expected model.ErrInvalidRule, got %v", err)
+	}
+}
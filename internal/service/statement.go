@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+type StatementService struct {
+	statementRepository   StatementRepository
+	transactionRepository TransactionRepository
+}
+
+func NewStatementService(statementRepository StatementRepository, transactionRepository TransactionRepository) *StatementService {
+	return &StatementService{
+		statementRepository:   statementRepository,
+		transactionRepository: transactionRepository,
+	}
+}
+
+func (s *StatementService) GetStatements(ctx context.Context, userID string) ([]model.Statement, error) {
+	return s.statementRepository.GetStatements(ctx, userID)
+}
+
+// GetStatement returns the statement with its transactions if it belongs to userID
+func (s *StatementService) GetStatement(ctx context.Context, userID, statementID string) (model.Statement, error) {
+	statement, err := getOwnedStatement(ctx, s.statementRepository, userID, statementID)
+	if err != nil {
+		return model.Statement{}, err
+	}
+
+	transactions, err := s.transactionRepository.GetTransactionsByStatement(ctx, statementID)
+	if err != nil {
+		return model.Statement{}, err
+	}
+	statement.Transactions = transactions
+
+	return statement, nil
+}
+
+// DeleteStatement removes the statement and every transaction parsed from it
+func (s *StatementService) DeleteStatement(ctx context.Context, userID, statementID string) error {
+	if _, err := getOwnedStatement(ctx, s.statementRepository, userID, statementID); err != nil {
+		return err
+	}
+
+	if err := s.transactionRepository.DeleteByStatement(ctx, statementID); err != nil {
+		return fmt.Errorf("failed to delete transactions: %w", err)
+	}
+	if err := s.statementRepository.Delete(ctx, statementID); err != nil {
+		return fmt.Errorf("failed to delete statement: %w", err)
+	}
+
+	return nil
+}
+
+// getOwnedStatement loads a statement, hiding statements of other users as not found
+func getOwnedStatement(ctx context.Context, statementRepository StatementRepository, userID, statementID string) (model.Statement, error) {
+	statement, err := statementRepository.GetStatement(ctx, statementID)
+	if err != nil {
+		return model.Statement{}, err
+	}
+	if statement.UserID != userID {
+		return model.Statement{}, model.ErrNotFound
+	}
+	return statement, nil
+}
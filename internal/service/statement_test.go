@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tsongpon/helios/internal/model"
+)
+
+func TestStatementService_GetStatements(t *testing.T) {
+	mockStmtRepo := &mockStatementRepository{
+		statements: []model.Statement{
+			{ID: "stmt-1", UserID: "user123"},
+			{ID: "stmt-2", UserID: "someone-else"},
+		},
+	}
+	svc := NewStatementService(mockStmtRepo, &mockTransactionRepository{})
+
+	statements, err := svc.GetStatements(context.Background(), "user123")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(statements) != 1 || statements[0].ID != "stmt-1" {
+		t.Errorf("expected only stmt-1, got %+v", statements)
+	}
+}
+
+func TestStatementService_GetStatement(t *testing.T) {
+	mockStmtRepo := &mockStatementRepository{
+		statements: []model.Statement{{ID: "stmt-1", UserID: "user123"}},
+	}
+	mockTxnRepo := &mockTransactionRepository{
+		transactions: []model.Transaction{
+			{StatementID: "stmt-1", Description: "AMAZON", Amount: 100.50},
+		},
+	}
+	svc := NewStatementService(mockStmtRepo, mockTxnRepo)
+
+	t.Run("returns statement with transactions", func(t *testing.T) {
+		statement, err := svc.GetStatement(context.Background(), "user123", "stmt-1")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(statement.Transactions) != 1 {
+			t.Fatalf("expected 1 transaction, got %d", len(statement.Transactions))
+		}
+	})
+
+	t.Run("hides statement owned by another user", func(t *testing.T) {
+		_, err := svc.GetStatement(context.Background(), "someone-else", "stmt-1")
+		if !errors.Is(err, model.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func TestStatementService_DeleteStatement(t *testing.T) {
+	t.Run("deletes statement and its transactions", func(t *testing.T) {
+		mockStmtRepo := &mockStatementRepository{
+			statements: []model.Statement{{ID: "stmt-1", UserID: "user123"}},
+		}
+		mockTxnRepo := &mockTransactionRepository{}
+		svc := NewStatementService(mockStmtRepo, mockTxnRepo)
+
+		if err := svc.DeleteStatement(context.Background(), "user123", "stmt-1"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if mockTxnRepo.deletedStatementID != "stmt-1" {
+			t.Errorf("expected transactions of stmt-1 to be deleted, got %q", mockTxnRepo.deletedStatementID)
+		}
+		if mockStmtRepo.deletedID != "stmt-1" {
+			t.Errorf("expected stmt-1 to be deleted, got %q", mockStmtRepo.deletedID)
+		}
+	})
+
+	t.Run("does not delete another user's statement", func(t *testing.T) {
+		mockStmtRepo := &mockStatementRepository{
+			statements: []model.Statement{{ID: "stmt-1", UserID: "user123"}},
+		}
+		mockTxnRepo := &mockTransactionRepository{}
+		svc := NewStatementService(mockStmtRepo, mockTxnRepo)
+
+		err := svc.DeleteStatement(context.Background(), "someone-else", "stmt-1")
+		if !errors.Is(err, model.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+		if mockTxnRepo.deletedStatementID != "" || mockStmtRepo.deletedID != "" {
+			t.Error("nothing should be deleted")
+		}
+	})
+}
@@ -2,13 +2,21 @@ package service
 
 import (
 	"context"
-	"time"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
 
+	"github.com/google/uuid"
 	"github.com/tsongpon/helios/internal/model"
 )
 
 type TransactionService struct {
 	transactionRepository TransactionRepository
+	statementRepository   StatementRepository
+	merchants             *MerchantNormalizer
 }
 
 func NewTransactionService(transactionRepository TransactionRepository) *TransactionService {
@@ -17,6 +25,179 @@ func NewTransactionService(transactionRepository TransactionRepository) *Transac
 	}
 }
 
-func (s *TransactionService) GetTransactions(ctx context.Context, userID string, from, to time.Time) ([]model.Transaction, error) {
-	return s.transactionRepository.GetTransactions(ctx, userID, from, to)
+// WithMerchantNormalizer sets the merchant of manual and edited transactions
+// from their descriptions
+func (s *TransactionService) WithMerchantNormalizer(merchants *MerchantNormalizer) *TransactionService {
+	s.merchants = merchants
+	return s
+}
+
+// WithStatements reconciles a statement again when the amount of one of its
+// transactions is edited
+func (s *TransactionService) WithStatements(statementRepository StatementRepository) *TransactionService {
+	s.statementRepository = statementRepository
+	return s
+}
+
+// GetTransactions returns one page of the user's transactions, applying the
+// default page size when the query has no limit
+func (s *TransactionService) GetTransactions(ctx context.Context, userID string, query model.TransactionQuery) (model.TransactionPage, error) {
+	if query.Limit <= 0 {
+		query.Limit = model.DefaultTransactionPageSize
+	}
+	query.Limit = min(query.Limit, model.MaxTransactionPageSize)
+	return s.transactionRepository.GetTransactions(ctx, userID, query)
+}
+
+// CreateTransaction saves a transaction entered by the user, such as a cash
+// payment or an item missing from a statement. A category given with it is
+// recorded as the user's edit, so categorization never replaces it.
+func (s *TransactionService) CreateTransaction(ctx context.Context, userID string, transaction model.Transaction) (model.Transaction, error) {
+	if category := transaction.Category; category != "" {
+		transaction.Category = ""
+		transaction.ApplyOverrides(model.TransactionPatch{Category: &category})
+	}
+	transaction.ID = uuid.NewString()
+	transaction.UserID = userID
+	transaction.StatementID = ""
+	transaction.Source = model.TransactionSourceManual
+	if transaction.PostingDate == "" {
+		transaction.PostingDate = transaction.TransactionDate
+	}
+	if s.merchants != nil {
+		transaction.Merchant = s.merchants.Normalize(transaction.Description)
+	}
+
+	if err := s.transactionRepository.Save(ctx, []model.Transaction{transaction}); err != nil {
+		return model.Transaction{}, fmt.Errorf("failed to save transaction: %w", err)
+	}
+
+	return transaction, nil
+}
+
+// DeleteTransaction removes a manual transaction the user owns
+func (s *TransactionService) DeleteTransaction(ctx context.Context, userID, transactionID string) error {
+	transaction, err := s.transactionRepository.GetTransaction(ctx, transactionID)
+	if err != nil {
+		return err
+	}
+	if transaction.UserID != userID {
+		return model.ErrNotFound
+	}
+	if transaction.Source != model.TransactionSourceManual {
+		return model.ErrNotManualTransaction
+	}
+
+	if err := s.transactionRepository.Delete(ctx, transactionID); err != nil {
+		return fmt.Errorf("failed to delete transaction: %w", err)
+	}
+	return nil
+}
+
+// UpdateTransaction applies the user's edits to a transaction they own. The
+// parsed values are kept in Original and the edits survive reparsing. An
+// amount edit reconciles the transaction's statement again.
+func (s *TransactionService) UpdateTransaction(ctx context.Context, userID, transactionID string, patch model.TransactionPatch) (model.Transaction, error) {
+	var amountChanged bool
+	transaction, err := s.transactionRepository.Update(ctx, transactionID, func(t *model.Transaction) error {
+		if t.UserID != userID {
+			return model.ErrNotFound
+		}
+		amount := t.Amount
+		t.ApplyOverrides(patch)
+		if s.merchants != nil {
+			t.Merchant = s.merchants.Normalize(t.Description)
+		}
+		amountChanged = t.Amount != amount
+		return nil
+	})
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	if amountChanged && transaction.StatementID != "" && s.statementRepository != nil {
+		if err := s.reconcileStatement(ctx, transaction.StatementID); err != nil {
+			log.Printf("failed to reconcile statement %s after editing transaction %s: %v", transaction.StatementID, transaction.ID, err)
+		}
+	}
+
+	return transaction, nil
+}
+
+// reconcileStatement reconciles a statement against its saved transactions
+func (s *TransactionService) reconcileStatement(ctx context.Context, statementID string) error {
+	statement, err := s.statementRepository.GetStatement(ctx, statementID)
+	if err != nil {
+		return err
+	}
+	if statement.Transactions, err = s.transactionRepository.GetTransactionsByStatement(ctx, statementID); err != nil {
+		return err
+	}
+	reconcile(&statement)
+	return s.statementRepository.UpdateReconciliation(ctx, statementID, statement.ReconciliationStatus, statement.ReconciliationDelta)
+}
+
+// preserveOverrides re-applies the user's edits of previously saved
+// transactions to freshly parsed ones with the same ID
+func preserveOverrides(transactions []model.Transaction, previous []model.Transaction) {
+	overrides := make(map[string]model.TransactionPatch)
+	for _, t := range previous {
+		if !t.Overrides.IsEmpty() {
+			overrides[t.ID] = t.Overrides
+		}
+	}
+	for i := range transactions {
+		if patch, ok := overrides[transactions[i].ID]; ok {
+			transactions[i].ApplyOverrides(patch)
+			delete(overrides, transactions[i].ID)
+		}
+	}
+	if len(overrides) > 0 {
+		log.Printf("dropped edits of %d transactions that no longer appear in the statement", len(overrides))
+	}
+}
+
+// keepStatementOwners links transactions that are already saved with an
+// overlapping statement back to it: a transaction belongs to the statement it
+// was first saved with, and is listed, reparsed and deleted with that one
+func keepStatementOwners(transactions []model.Transaction, saved []model.Transaction) {
+	owners := make(map[string]string)
+	for _, t := range saved {
+		if t.StatementID != "" {
+			owners[t.ID] = t.StatementID
+		}
+	}
+	for i := range transactions {
+		if owner, ok := owners[transactions[i].ID]; ok {
+			transactions[i].StatementID = owner
+		}
+	}
+}
+
+// assignTransactionIDs gives every transaction a stable ID derived from its
+// owner, card, dates, description, amount and occurrence index, so identical
+// lines parsed again from the same or an overlapping statement map to the same ID
+func assignTransactionIDs(transactions []model.Transaction) {
+	occurrences := make(map[string]int)
+	for i := range transactions {
+		key := transactionKey(transactions[i])
+		transactions[i].ID = transactionID(key, occurrences[key])
+		occurrences[key]++
+	}
+}
+
+func transactionKey(t model.Transaction) string {
+	return strings.Join([]string{
+		t.UserID,
+		t.CardNumber,
+		t.TransactionDate,
+		t.PostingDate,
+		strings.Join(strings.Fields(t.Description), " "),
+		strconv.FormatFloat(t.Amount, 'f', 2, 64),
+	}, "|")
+}
+
+func transactionID(key string, occurrence int) string {
+	sum := sha256.Sum256([]byte(key + "|" + strconv.Itoa(occurrence)))
+	return hex.EncodeToString(sum[:16])
 }
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"slices"
 	"testing"
 	"time"
 
@@ -10,27 +11,97 @@ import (
 )
 
 type mockTransactionRepository struct {
-	transactions []model.Transaction
-	err          error
-	savedTxns    []model.Transaction
+	transactions       []model.Transaction
+	err                error
+	savedTxns          []model.Transaction
+	deletedStatementID string
+	deletedID          string
+	deletedIDs         []string
+	saveErr            error
+	query              model.TransactionQuery
 }
 
 func (m *mockTransactionRepository) Save(ctx context.Context, transactions []model.Transaction) error {
+	if m.saveErr != nil {
+		return m.saveErr
+	}
 	m.savedTxns = transactions
 	return m.err
 }
 
-func (m *mockTransactionRepository) GetTransactions(ctx context.Context, userID string, from, to time.Time) ([]model.Transaction, error) {
+func (m *mockTransactionRepository) GetTransactions(ctx context.Context, userID string, query model.TransactionQuery) (model.TransactionPage, error) {
+	m.query = query
+	if m.err != nil {
+		return model.TransactionPage{}, m.err
+	}
+	return model.TransactionPage{Transactions: m.transactions}, nil
+}
+
+func (m *mockTransactionRepository) GetTransaction(ctx context.Context, transactionID string) (model.Transaction, error) {
+	for _, t := range m.transactions {
+		if t.ID == transactionID {
+			return t, nil
+		}
+	}
+	return model.Transaction{}, model.ErrNotFound
+}
+
+func (m *mockTransactionRepository) GetTransactionsByIDs(ctx context.Context, ids []string) ([]model.Transaction, error) {
+	var transactions []model.Transaction
+	for _, t := range m.transactions {
+		if slices.Contains(ids, t.ID) {
+			transactions = append(transactions, t)
+		}
+	}
+	return transactions, nil
+}
+
+func (m *mockTransactionRepository) GetTransactionsByStatement(ctx context.Context, statementID string) ([]model.Transaction, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
 	return m.transactions, nil
 }
 
+func (m *mockTransactionRepository) Update(ctx context.Context, transactionID string, update func(*model.Transaction) error) (model.Transaction, error) {
+	for i, t := range m.transactions {
+		if t.ID != transactionID {
+			continue
+		}
+		if err := update(&t); err != nil {
+			return model.Transaction{}, err
+		}
+		if m.err != nil {
+			return model.Transaction{}, m.err
+		}
+		m.transactions[i] = t
+		m.savedTxns = []model.Transaction{t}
+		return t, nil
+	}
+	return model.Transaction{}, model.ErrNotFound
+}
+
+func (m *mockTransactionRepository) Delete(ctx context.Context, transactionID string) error {
+	m.deletedID = transactionID
+	return m.err
+}
+
+func (m *mockTransactionRepository) DeleteByStatement(ctx context.Context, statementID string) error {
+	m.deletedStatementID = statementID
+	return m.err
+}
+
+func (m *mockTransactionRepository) DeleteByIDs(ctx context.Context, ids []string) error {
+	m.deletedIDs = ids
+	return m.err
+}
+
 func TestTransactionService_GetTransactions(t *testing.T) {
 	ctx := context.Background()
-	from := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
-	to := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	query := model.TransactionQuery{
+		From: time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC),
+	}
 
 	t.Run("returns transactions successfully", func(t *testing.T) {
 		expectedTxns := []model.Transaction{
@@ -61,7 +132,8 @@ func TestTransactionService_GetTransactions(t *testing.T) {
 		}
 
 		svc := NewTransactionService(mockRepo)
-		transactions, err := svc.GetTransactions(ctx, "user123", from, to)
+		page, err := svc.GetTransactions(ctx, "user123", query)
+		transactions := page.Transactions
 
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
@@ -87,7 +159,8 @@ func TestTransactionService_GetTransactions(t *testing.T) {
 		}
 
 		svc := NewTransactionService(mockRepo)
-		transactions, err := svc.GetTransactions(ctx, "user123", from, to)
+		page, err := svc.GetTransactions(ctx, "user123", query)
+		transactions := page.Transactions
 
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
@@ -104,7 +177,7 @@ func TestTransactionService_GetTransactions(t *testing.T) {
 		}
 
 		svc := NewTransactionService(mockRepo)
-		_, err := svc.GetTransactions(ctx, "user123", from, to)
+		_, err := svc.GetTransactions(ctx, "user123", query)
 
 		if err == nil {
 			t.Fatal("expected error, got nil")
@@ -114,4 +187,239 @@ func TestTransactionService_GetTransactions(t *testing.T) {
 			t.Errorf("expected error message %q, got %q", "database connection failed", err.Error())
 		}
 	})
+
+	t.Run("applies the default and maximum page size", func(t *testing.T) {
+		mockRepo := &mockTransactionRepository{}
+		svc := NewTransactionService(mockRepo)
+
+		for limit, want := range map[int]int{0: model.DefaultTransactionPageSize, 10: 10, 10000: model.MaxTransactionPageSize} {
+			query.Limit = limit
+			if _, err := svc.GetTransactions(ctx, "user123", query); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if mockRepo.query.Limit != want {
+				t.Errorf("limit %d: expected %d, got %d", limit, want, mockRepo.query.Limit)
+			}
+		}
+	})
+}
+
+func TestAssignTransactionIDs(t *testing.T) {
+	newTransactions := func() []model.Transaction {
+		return []model.Transaction{
+			{UserID: "user123", CardNumber: "1234", TransactionDate: "2024-12-15", PostingDate: "2024-12-16", Description: "GRAB", Amount: 100.00},
+			{UserID: "user123", CardNumber: "1234", TransactionDate: "2024-12-15", PostingDate: "2024-12-16", Description: "GRAB", Amount: 100.00},
+			{UserID: "user123", CardNumber: "1234", TransactionDate: "2024-12-16", PostingDate: "2024-12-16", Description: "LAZADA", Amount: 250.00},
+		}
+	}
+
+	t.Run("assigns stable IDs", func(t *testing.T) {
+		first := newTransactions()
+		second := newTransactions()
+		assignTransactionIDs(first)
+		assignTransactionIDs(second)
+
+		for i := range first {
+			if first[i].ID == "" {
+				t.Fatalf("transaction %d: expected ID to be set", i)
+			}
+			if first[i].ID != second[i].ID {
+				t.Errorf("transaction %d: expected same ID, got %s and %s", i, first[i].ID, second[i].ID)
+			}
+		}
+	})
+
+	t.Run("distinguishes identical transactions by occurrence", func(t *testing.T) {
+		transactions := newTransactions()
+		assignTransactionIDs(transactions)
+
+		if transactions[0].ID == transactions[1].ID {
+			t.Error("expected identical transactions to get different IDs")
+		}
+		if transactions[0].ID == transactions[2].ID {
+			t.Error("expected different transactions to get different IDs")
+		}
+	})
+
+	t.Run("ignores statement and whitespace differences", func(t *testing.T) {
+		a := []model.Transaction{{UserID: "user123", StatementID: "stmt-1", Description: "GRAB  FOOD", Amount: 100}}
+		b := []model.Transaction{{UserID: "user123", StatementID: "stmt-2", Description: "GRAB FOOD", Amount: 100.00}}
+		assignTransactionIDs(a)
+		assignTransactionIDs(b)
+
+		if a[0].ID != b[0].ID {
+			t.Errorf("expected same ID across overlapping statements, got %s and %s", a[0].ID, b[0].ID)
+		}
+	})
+
+	t.Run("scopes IDs to the user", func(t *testing.T) {
+		a := []model.Transaction{{UserID: "user123", Description: "GRAB", Amount: 100}}
+		b := []model.Transaction{{UserID: "someone-else", Description: "GRAB", Amount: 100}}
+		assignTransactionIDs(a)
+		assignTransactionIDs(b)
+
+		if a[0].ID == b[0].ID {
+			t.Error("expected different users to get different IDs")
+		}
+	})
+}
+
+func TestTransactionService_UpdateTransaction(t *testing.T) {
+	ctx := context.Background()
+	amount := 120.0
+	category := "groceries"
+
+	t.Run("applies edits and keeps the parsed values", func(t *testing.T) {
+		mockRepo := &mockTransactionRepository{
+			transactions: []model.Transaction{{ID: "txn-1", UserID: "user123", Description: "TOPS", Amount: 12}},
+		}
+		svc := NewTransactionService(mockRepo)
+
+		updated, err := svc.UpdateTransaction(ctx, "user123", "txn-1", model.TransactionPatch{Amount: &amount, Category: &category})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if updated.Amount != 120 || updated.Category != "groceries" {
+			t.Errorf("expected edits to be applied, got %+v", updated)
+		}
+		if updated.Original.Amount == nil || *updated.Original.Amount != 12 {
+			t.Errorf("expected original amount 12, got %v", updated.Original.Amount)
+		}
+		if len(mockRepo.savedTxns) != 1 || mockRepo.savedTxns[0].Overrides.Amount == nil {
+			t.Errorf("expected the edited transaction to be saved, got %+v", mockRepo.savedTxns)
+		}
+	})
+
+	t.Run("hides transactions owned by another user", func(t *testing.T) {
+		mockRepo := &mockTransactionRepository{
+			transactions: []model.Transaction{{ID: "txn-1", UserID: "user123"}},
+		}
+		svc := NewTransactionService(mockRepo)
+
+		_, err := svc.UpdateTransaction(ctx, "someone-else", "txn-1", model.TransactionPatch{Amount: &amount})
+		if !errors.Is(err, model.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+		if mockRepo.savedTxns != nil {
+			t.Error("expected nothing to be saved")
+		}
+	})
+
+	t.Run("reconciles the statement after an amount edit", func(t *testing.T) {
+		mockRepo := &mockTransactionRepository{transactions: []model.Transaction{
+			{ID: "txn-1", UserID: "user123", StatementID: "stmt-1", Amount: 12},
+			{ID: "txn-2", UserID: "user123", StatementID: "stmt-1", Amount: 30},
+		}}
+		statementRepo := &mockStatementRepository{statements: []model.Statement{
+			{ID: "stmt-1", UserID: "user123", PreviousBalance: 50, TotalPayment: 200, ReconciliationStatus: model.ReconciliationMismatched},
+		}}
+		svc := NewTransactionService(mockRepo).WithStatements(statementRepo)
+
+		if _, err := svc.UpdateTransaction(ctx, "user123", "txn-1", model.TransactionPatch{Amount: &amount}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if statementRepo.reconciliationStatus != model.ReconciliationMatched || statementRepo.reconciliationDelta != 0 {
+			t.Errorf("expected the statement to reconcile, got %s (delta %.2f)", statementRepo.reconciliationStatus, statementRepo.reconciliationDelta)
+		}
+	})
+
+	t.Run("leaves the statement alone when the amount is unchanged", func(t *testing.T) {
+		mockRepo := &mockTransactionRepository{transactions: []model.Transaction{
+			{ID: "txn-1", UserID: "user123", StatementID: "stmt-1", Amount: 12},
+		}}
+		statementRepo := &mockStatementRepository{statements: []model.Statement{{ID: "stmt-1", UserID: "user123"}}}
+		svc := NewTransactionService(mockRepo).WithStatements(statementRepo)
+
+		if _, err := svc.UpdateTransaction(ctx, "user123", "txn-1", model.TransactionPatch{Category: &category}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if statementRepo.reconciliationStatus != "" {
+			t.Errorf("expected no reconciliation, got %s", statementRepo.reconciliationStatus)
+		}
+	})
+}
+
+func TestPreserveOverrides(t *testing.T) {
+	description := "Tops Supermarket"
+	edited := model.Transaction{ID: "txn-1", Description: "TOPS"}
+	edited.ApplyOverrides(model.TransactionPatch{Description: &description})
+
+	parsed := []model.Transaction{
+		{ID: "txn-1", Description: "TOPS SUPERMARKET"},
+		{ID: "txn-2", Description: "GRAB"},
+	}
+	preserveOverrides(parsed, []model.Transaction{edited})
+
+	if parsed[0].Description != "Tops Supermarket" {
+		t.Errorf("expected edited description, got %q", parsed[0].Description)
+	}
+	if parsed[0].Original.Description == nil || *parsed[0].Original.Description != "TOPS SUPERMARKET" {
+		t.Errorf("expected the new parsed description as original, got %v", parsed[0].Original.Description)
+	}
+	if !parsed[1].Overrides.IsEmpty() {
+		t.Errorf("expected unedited transaction to stay unchanged, got %+v", parsed[1])
+	}
+}
+
+func TestTransactionService_CreateTransaction(t *testing.T) {
+	mockRepo := &mockTransactionRepository{}
+	svc := NewTransactionService(mockRepo).WithMerchantNormalizer(DefaultMerchantNormalizer())
+
+	created, err := svc.CreateTransaction(context.Background(), "user123", model.Transaction{
+		TransactionDate: "2024-12-20",
+		Description:     "Street food Bangkok",
+		Amount:          80,
+		StatementID:     "stmt-1",
+		Category:        "food",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if created.ID == "" || created.UserID != "user123" || created.Source != model.TransactionSourceManual {
+		t.Errorf("expected an owned manual transaction, got %+v", created)
+	}
+	if created.Merchant != "STREET FOOD" {
+		t.Errorf("expected merchant STREET FOOD, got %q", created.Merchant)
+	}
+	if created.StatementID != "" || created.PostingDate != "2024-12-20" {
+		t.Errorf("expected no statement and posting date defaulting to the transaction date, got %+v", created)
+	}
+	if created.Category != "food" || created.Overrides.Category == nil {
+		t.Errorf("expected the category to be recorded as the user's edit, got %+v", created)
+	}
+	if len(mockRepo.savedTxns) != 1 || mockRepo.savedTxns[0].ID != created.ID {
+		t.Errorf("expected the transaction to be saved, got %+v", mockRepo.savedTxns)
+	}
+}
+
+func TestTransactionService_DeleteTransaction(t *testing.T) {
+	transactions := []model.Transaction{
+		{ID: "manual", UserID: "user123", Source: model.TransactionSourceManual},
+		{ID: "parsed", UserID: "user123", Source: model.TransactionSourceStatement},
+	}
+	tests := []struct {
+		name   string
+		userID string
+		id     string
+		want   error
+	}{
+		{"deletes manual transactions", "user123", "manual", nil},
+		{"refuses parsed transactions", "user123", "parsed", model.ErrNotManualTransaction},
+		{"hides other users' transactions", "someone-else", "manual", model.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &mockTransactionRepository{transactions: transactions}
+			svc := NewTransactionService(mockRepo)
+
+			err := svc.DeleteTransaction(context.Background(), tt.userID, tt.id)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+			if deleted := mockRepo.deletedID == tt.id; deleted != (tt.want == nil) {
+				t.Errorf("expected deleted=%v, got %q", tt.want == nil, mockRepo.deletedID)
+			}
+		})
+	}
 }